@@ -0,0 +1,29 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package flag
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/marmotedu/component-base/pkg/defaulting"
+)
+
+// FlagAdder is implemented by the options adding their flags to a flag set.
+type FlagAdder interface {
+	AddFlags(fs *pflag.FlagSet)
+}
+
+// AddFlagsWithDefaults sets the defaults of opts, which must be a pointer, from their
+// `default` tags and the defaulting functions of funcs, which may be nil, and then adds
+// their flags to fs. The flags show the defaults in their usage, and override them when
+// they are parsed.
+func AddFlagsWithDefaults(fs *pflag.FlagSet, opts FlagAdder, funcs defaulting.FuncSource) error {
+	if err := defaulting.SetDefaultsWithFuncs(opts, funcs); err != nil {
+		return err
+	}
+	opts.AddFlags(fs)
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package flag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type testServerOptions struct {
+	Mode            string        `json:"mode"    default:"release"`
+	Healthz         *bool         `json:"healthz" default:"true"`
+	ShutdownTimeout time.Duration `json:"timeout" default:"30s"`
+}
+
+func (o *testServerOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Mode, "mode", o.Mode, "Server mode.")
+	fs.BoolVar(o.Healthz, "healthz", *o.Healthz, "Add the /healthz endpoint.")
+	fs.DurationVar(&o.ShutdownTimeout, "shutdown-timeout", o.ShutdownTimeout, "Shutdown timeout.")
+}
+
+func TestAddFlagsWithDefaults(t *testing.T) {
+	opts := &testServerOptions{Mode: "debug"}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddFlagsWithDefaults(fs, opts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the flags show the defaults, and the values already set are kept
+	for name, expected := range map[string]string{"mode": "debug", "healthz": "true", "shutdown-timeout": "30s"} {
+		if value := fs.Lookup(name).DefValue; value != expected {
+			t.Errorf("expected the default of --%s to be %q, got %q", name, expected, value)
+		}
+	}
+
+	// the parsed flags override the defaults, including an explicit false
+	if err := fs.Parse([]string{"--healthz=false", "--shutdown-timeout=1m"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Mode != "debug" || *opts.Healthz || opts.ShutdownTimeout != time.Minute {
+		t.Errorf("unexpected options %+v, healthz %v", opts, *opts.Healthz)
+	}
+}
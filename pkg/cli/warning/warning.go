@@ -0,0 +1,119 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package warning prints the warnings returned by the servers in RFC 7234 Warning headers
+// with the code 299, e.g. the validation warnings.
+package warning
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	utilnet "github.com/marmotedu/component-base/pkg/util/net"
+)
+
+// Handler handles the warnings returned by the servers.
+type Handler interface {
+	// HandleWarningHeader is called with the code, the agent and the text of each warning.
+	HandleWarningHeader(code int, agent string, text string)
+}
+
+// NoWarnings is a Handler which ignores the warnings.
+type NoWarnings struct{}
+
+// HandleWarningHeader implements Handler.
+func (NoWarnings) HandleWarningHeader(code int, agent string, text string) {}
+
+// WriterOptions controls the behavior of the Handler returned by NewWriter.
+type WriterOptions struct {
+	// Deduplicate prints each warning once only.
+	Deduplicate bool
+	// Color prints the warnings in yellow, e.g. when the writer is a terminal.
+	Color bool
+}
+
+type writer struct {
+	lock    sync.Mutex
+	out     io.Writer
+	opts    WriterOptions
+	written map[string]bool
+}
+
+// NewWriter returns a Handler which prints the warnings with the code 299 to out, e.g.
+// "Warning: spec.replicas: deprecated".
+func NewWriter(out io.Writer, opts WriterOptions) Handler {
+	return &writer{
+		out:     out,
+		opts:    opts,
+		written: map[string]bool{},
+	}
+}
+
+const (
+	yellowColor = "\u001b[33;1m"
+	resetColor  = "\u001b[0m"
+)
+
+// HandleWarningHeader implements Handler.
+func (w *writer) HandleWarningHeader(code int, agent string, text string) {
+	if code != utilnet.MiscellaneousPersistentWarning || len(text) == 0 {
+		return
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.opts.Deduplicate {
+		if w.written[text] {
+			return
+		}
+		w.written[text] = true
+	}
+	if w.opts.Color {
+		fmt.Fprintf(w.out, "%sWarning:%s %s\n", yellowColor, resetColor, text)
+	} else {
+		fmt.Fprintf(w.out, "Warning: %s\n", text)
+	}
+}
+
+// HandleResponse passes the warnings of the Warning headers of resp to handler. The headers
+// which cannot be parsed are ignored.
+func HandleResponse(resp *http.Response, handler Handler) {
+	if resp == nil || handler == nil {
+		return
+	}
+
+	warnings, _ := utilnet.ParseWarningHeaders(resp.Header.Values("Warning"))
+	for _, warning := range warnings {
+		handler.HandleWarningHeader(warning.Code, warning.Agent, warning.Text)
+	}
+}
+
+type roundTripper struct {
+	rt      http.RoundTripper
+	handler Handler
+}
+
+// NewRoundTripper returns a http.RoundTripper passing the warnings of the responses to
+// handler. The rt defaults to http.DefaultTransport.
+func NewRoundTripper(rt http.RoundTripper, handler Handler) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return &roundTripper{rt: rt, handler: handler}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.rt.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	HandleResponse(resp, r.handler)
+
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package warning
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Warning", `299 - "spec.replicas: deprecated"`)
+		w.Header().Add("Warning", `299 - "spec.replicas: deprecated", 199 - "ignored"`)
+		w.Header().Add("Warning", `299 - "volumes[0].size: will be limited to 10"`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		opts WriterOptions
+		want string
+	}{
+		{
+			opts: WriterOptions{},
+			want: "Warning: spec.replicas: deprecated\nWarning: spec.replicas: deprecated\n" +
+				"Warning: volumes[0].size: will be limited to 10\n",
+		},
+		{
+			opts: WriterOptions{Deduplicate: true},
+			want: "Warning: spec.replicas: deprecated\nWarning: volumes[0].size: will be limited to 10\n",
+		},
+		{
+			opts: WriterOptions{Deduplicate: true, Color: true},
+			want: "\u001b[33;1mWarning:\u001b[0m spec.replicas: deprecated\n" +
+				"\u001b[33;1mWarning:\u001b[0m volumes[0].size: will be limited to 10\n",
+		},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		client := &http.Client{Transport: NewRoundTripper(nil, NewWriter(&out, test.opts))}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if out.String() != test.want {
+			t.Errorf("%+v: got %q, want %q", test.opts, out.String(), test.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConversionFunc converts the object a into the object b, reusing the given scope for
+// nested conversions. a and b are pointers to the types the function has been registered for.
+type ConversionFunc func(a, b interface{}, scope Scope) error
+
+// Meta is supplied by Scheme, when it calls Convert.
+type Meta struct {
+	// Context is an optional field that callers may use to pass info to conversion functions.
+	Context interface{}
+}
+
+// Scope is passed to conversion funcs to allow them to continue an ongoing conversion.
+type Scope interface {
+	// Convert calls the registered conversion function for the types of src and dest,
+	// or converts them field by field.
+	Convert(src, dest interface{}) error
+
+	// Meta returns any information originally passed to Convert.
+	Meta() *Meta
+}
+
+type typePair struct {
+	source reflect.Type
+	dest   reflect.Type
+}
+
+// Converter knows how to convert one type to another.
+type Converter struct {
+	conversionFuncs map[typePair]ConversionFunc
+	ignoredFields   map[typePair]map[string]struct{}
+}
+
+// NewConverter creates a new Converter object.
+func NewConverter() *Converter {
+	return &Converter{
+		conversionFuncs: map[typePair]ConversionFunc{},
+		ignoredFields:   map[typePair]map[string]struct{}{},
+	}
+}
+
+// RegisterUntypedConversionFunc registers a function that converts between a and b by passing
+// objects of those types to the provided function. a and b must be pointers.
+func (c *Converter) RegisterUntypedConversionFunc(a, b interface{}, fn ConversionFunc) error {
+	typeA := reflect.TypeOf(a)
+	if typeA == nil || typeA.Kind() != reflect.Ptr {
+		return fmt.Errorf("expected pointer arg for 'a', got: %v", typeA)
+	}
+	typeB := reflect.TypeOf(b)
+	if typeB == nil || typeB.Kind() != reflect.Ptr {
+		return fmt.Errorf("expected pointer arg for 'b', got: %v", typeB)
+	}
+
+	c.conversionFuncs[typePair{typeA, typeB}] = fn
+
+	return nil
+}
+
+// RegisterIgnoredFields registers source fields of a which are dropped on purpose when
+// converting to b, so they are not reported as unconverted. a and b must be pointers, and
+// the fields are identified by their Go name.
+func (c *Converter) RegisterIgnoredFields(a, b interface{}, fieldNames ...string) {
+	pair := typePair{reflect.TypeOf(a).Elem(), reflect.TypeOf(b).Elem()}
+	if c.ignoredFields[pair] == nil {
+		c.ignoredFields[pair] = map[string]struct{}{}
+	}
+	for _, name := range fieldNames {
+		c.ignoredFields[pair][name] = struct{}{}
+	}
+}
+
+// HasConversionFunc returns true if a conversion function has been registered from the
+// type of a to the type of b.
+func (c *Converter) HasConversionFunc(a, b interface{}) bool {
+	_, ok := c.conversionFuncs[typePair{reflect.TypeOf(a), reflect.TypeOf(b)}]
+
+	return ok
+}
+
+// Convert translates src to dest, which must both be pointers. A registered conversion
+// function is used when there is one for the types of src and dest. Otherwise, the fields
+// of src are converted to the fields of dest with the same name, recursively, using the
+// registered conversion functions for nested types.
+//
+// When some fields of src have no equivalent in dest, or a type which cannot be converted,
+// the rest of the object is converted and an *UnconvertedFieldsError is returned. Fields
+// without equivalent are not reported when they hold their zero value.
+func (c *Converter) Convert(src, dest interface{}, meta *Meta) error {
+	sv, dv := reflect.ValueOf(src), reflect.ValueOf(dest)
+	if sv.Kind() != reflect.Ptr || sv.IsNil() {
+		return fmt.Errorf("expected non-nil pointer for 'src', got: %v", sv.Type())
+	}
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("expected non-nil pointer for 'dest', got: %v", dv.Type())
+	}
+
+	s := &scope{converter: c, meta: meta}
+	if fn, ok := c.conversionFuncs[typePair{sv.Type(), dv.Type()}]; ok {
+		return fn(src, dest, s)
+	}
+
+	if err := s.convert(sv.Elem(), dv.Elem(), ""); err != nil {
+		return err
+	}
+	if len(s.unconverted) > 0 {
+		return &UnconvertedFieldsError{Source: sv.Type().Elem(), Dest: dv.Type().Elem(), Fields: s.unconverted}
+	}
+
+	return nil
+}
+
+// UnconvertedFieldsError is returned by Convert when some fields of the source object
+// could not be converted.
+type UnconvertedFieldsError struct {
+	Source reflect.Type
+	Dest   reflect.Type
+	// Fields are the paths of the unconverted fields, e.g. "spec.statements[0].effect".
+	Fields []string
+}
+
+func (e *UnconvertedFieldsError) Error() string {
+	return fmt.Sprintf("unable to convert fields %s of %v to %v", strings.Join(e.Fields, ", "), e.Source, e.Dest)
+}
+
+// IsUnconvertedFields returns true if the error is an *UnconvertedFieldsError.
+func IsUnconvertedFields(err error) bool {
+	_, ok := err.(*UnconvertedFieldsError)
+
+	return ok
+}
+
+// scope holds the state of a conversion.
+type scope struct {
+	converter   *Converter
+	meta        *Meta
+	unconverted []string
+}
+
+// Convert implements Scope.
+func (s *scope) Convert(src, dest interface{}) error {
+	return s.converter.Convert(src, dest, s.meta)
+}
+
+// Meta implements Scope.
+func (s *scope) Meta() *Meta {
+	return s.meta
+}
+
+// convert converts sv into dv. path is the path of sv, used to report unconverted fields.
+func (s *scope) convert(sv, dv reflect.Value, path string) error {
+	st, dt := sv.Type(), dv.Type()
+
+	if fn, ok := s.converter.conversionFuncs[typePair{reflect.PtrTo(st), reflect.PtrTo(dt)}]; ok {
+		return s.callFunc(fn, sv, dv, path)
+	}
+
+	if st == dt {
+		dv.Set(sv)
+
+		return nil
+	}
+
+	switch {
+	case st.Kind() == reflect.Struct && dt.Kind() == reflect.Struct:
+		return s.convertStruct(sv, dv, path)
+	case st.Kind() == reflect.Ptr && dt.Kind() == reflect.Ptr:
+		if sv.IsNil() {
+			dv.Set(reflect.Zero(dt))
+
+			return nil
+		}
+		dv.Set(reflect.New(dt.Elem()))
+
+		return s.convert(sv.Elem(), dv.Elem(), path)
+	case st.Kind() == reflect.Slice && dt.Kind() == reflect.Slice:
+		if sv.IsNil() {
+			dv.Set(reflect.Zero(dt))
+
+			return nil
+		}
+		dv.Set(reflect.MakeSlice(dt, sv.Len(), sv.Len()))
+		for i := 0; i < sv.Len(); i++ {
+			if err := s.convert(sv.Index(i), dv.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case st.Kind() == reflect.Map && dt.Kind() == reflect.Map:
+		return s.convertMap(sv, dv, path)
+	case dt.Kind() == reflect.Interface && st.AssignableTo(dt):
+		dv.Set(sv)
+
+		return nil
+	case kindClass(st.Kind()) == kindClass(dt.Kind()) && st.ConvertibleTo(dt):
+		dv.Set(sv.Convert(dt))
+
+		return nil
+	}
+
+	s.unconverted = append(s.unconverted, path)
+
+	return nil
+}
+
+// callFunc calls a registered conversion function with pointers to sv and dv.
+func (s *scope) callFunc(fn ConversionFunc, sv, dv reflect.Value, path string) error {
+	src := sv
+	if !src.CanAddr() {
+		src = reflect.New(sv.Type()).Elem()
+		src.Set(sv)
+	}
+
+	err := fn(src.Addr().Interface(), dv.Addr().Interface(), s)
+	if unconverted, ok := err.(*UnconvertedFieldsError); ok {
+		for _, f := range unconverted.Fields {
+			s.unconverted = append(s.unconverted, joinPath(path, f))
+		}
+
+		return nil
+	}
+
+	return err
+}
+
+func (s *scope) convertStruct(sv, dv reflect.Value, path string) error {
+	st, dt := sv.Type(), dv.Type()
+	ignored := s.converter.ignoredFields[typePair{st, dt}]
+
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		fieldPath := path
+		if name, inline := jsonName(sf); !inline {
+			fieldPath = joinPath(path, name)
+		}
+
+		df, ok := dt.FieldByName(sf.Name)
+		if !ok || df.PkgPath != "" || len(df.Index) != 1 {
+			if _, ok := ignored[sf.Name]; !ok && !sv.Field(i).IsZero() {
+				s.unconverted = append(s.unconverted, fieldPath)
+			}
+
+			continue
+		}
+
+		if err := s.convert(sv.Field(i), dv.Field(df.Index[0]), fieldPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *scope) convertMap(sv, dv reflect.Value, path string) error {
+	st, dt := sv.Type(), dv.Type()
+	if sv.IsNil() {
+		dv.Set(reflect.Zero(dt))
+
+		return nil
+	}
+
+	dv.Set(reflect.MakeMapWithSize(dt, sv.Len()))
+	iter := sv.MapRange()
+	for iter.Next() {
+		key := iter.Key()
+		keyPath := fmt.Sprintf("%s[%v]", path, key.Interface())
+		if st.Key() != dt.Key() {
+			if !st.Key().ConvertibleTo(dt.Key()) {
+				s.unconverted = append(s.unconverted, keyPath)
+
+				continue
+			}
+			key = key.Convert(dt.Key())
+		}
+
+		value := reflect.New(dt.Elem()).Elem()
+		if err := s.convert(iter.Value(), value, keyPath); err != nil {
+			return err
+		}
+		dv.SetMapIndex(key, value)
+	}
+
+	return nil
+}
+
+// kindClass groups the kinds of the values which can be converted to each other, e.g.
+// an int32 to an int64, but not an int to a string.
+func kindClass(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.Complex64, reflect.Complex128:
+		return reflect.Complex128
+	default:
+		return k
+	}
+}
+
+// jsonName returns the JSON name of a struct field, and whether its fields are inlined
+// in the JSON representation of its parent.
+func jsonName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	name := strings.Split(tag, ",")[0]
+	if strings.Contains(tag, ",inline") || (sf.Anonymous && name == "") {
+		return "", true
+	}
+	if name == "" || name == "-" {
+		return sf.Name, false
+	}
+
+	return name, false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	if strings.HasPrefix(name, "[") {
+		return path + name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type statementV1 struct {
+	Effect   string `json:"effect"`
+	Priority int32  `json:"priority"`
+}
+
+type policyV1 struct {
+	Name       string            `json:"name"`
+	Statements []statementV1     `json:"statements"`
+	Labels     map[string]string `json:"labels"`
+	Owner      *string           `json:"owner"`
+	Deprecated string            `json:"deprecated"`
+}
+
+type statementV2 struct {
+	Effect   string `json:"effect"`
+	Priority int64  `json:"priority"`
+}
+
+type policyV2 struct {
+	Name       string            `json:"name"`
+	Statements []statementV2     `json:"statements"`
+	Labels     map[string]string `json:"labels"`
+	Owner      *string           `json:"owner"`
+}
+
+func TestConvertByFieldName(t *testing.T) {
+	owner := "admin"
+	in := &policyV1{
+		Name:       "foo",
+		Statements: []statementV1{{Effect: "allow", Priority: 1}},
+		Labels:     map[string]string{"a": "b"},
+		Owner:      &owner,
+	}
+	out := &policyV2{}
+
+	if err := NewConverter().Convert(in, out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &policyV2{
+		Name:       "foo",
+		Statements: []statementV2{{Effect: "allow", Priority: 1}},
+		Labels:     map[string]string{"a": "b"},
+		Owner:      &owner,
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %#v, got %#v", expected, out)
+	}
+}
+
+func TestConvertReportsUnconvertedFields(t *testing.T) {
+	c := NewConverter()
+	in := &policyV1{Name: "foo", Deprecated: "yes"}
+	out := &policyV2{}
+
+	err := c.Convert(in, out, nil)
+	unconverted, ok := err.(*UnconvertedFieldsError)
+	if !ok || !reflect.DeepEqual(unconverted.Fields, []string{"deprecated"}) {
+		t.Fatalf("expected the deprecated field to be reported, got %v", err)
+	}
+	if out.Name != "foo" {
+		t.Errorf("expected the other fields to be converted, got %#v", out)
+	}
+
+	c.RegisterIgnoredFields(&policyV1{}, &policyV2{}, "Deprecated")
+	if err := c.Convert(in, &policyV2{}, nil); err != nil {
+		t.Errorf("expected ignored fields not to be reported, got %v", err)
+	}
+}
+
+func TestConvertWithConversionFunc(t *testing.T) {
+	c := NewConverter()
+	err := c.RegisterUntypedConversionFunc(&statementV1{}, &statementV2{}, func(a, b interface{}, scope Scope) error {
+		in, out := a.(*statementV1), b.(*statementV2)
+		out.Effect = fmt.Sprintf("%s:%v", in.Effect, scope.Meta().Context)
+		out.Priority = int64(in.Priority) * 10
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.RegisterUntypedConversionFunc(statementV1{}, &statementV2{}, nil); err == nil {
+		t.Errorf("expected an error registering a function for a non pointer type")
+	}
+
+	out := &policyV2{}
+	in := &policyV1{Statements: []statementV1{{Effect: "allow", Priority: 1}}}
+	if err := c.Convert(in, out, &Meta{Context: strconv.Itoa(2)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []statementV2{{Effect: "allow:2", Priority: 10}}; !reflect.DeepEqual(out.Statements, expected) {
+		t.Errorf("expected the conversion function to be used for nested types, got %#v", out.Statements)
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package conversion provides go object versioning: a registry of conversion functions
+// between go types, with a reflection based fallback which converts the fields with
+// matching names and reports the ones which could not be converted.
+package conversion // import "github.com/marmotedu/component-base/pkg/conversion"
@@ -0,0 +1,36 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/log"
+
+	utilnet "github.com/marmotedu/component-base/pkg/util/net"
+)
+
+// AddWarnings adds the warnings to the response as RFC 7234 Warning headers with the code
+// 299, e.g. the warnings of validation.Result. A warning which has already been added to
+// the response is skipped. It must be called before the response is written.
+func AddWarnings(c *gin.Context, warnings ...string) {
+	existing := map[string]bool{}
+	for _, header := range c.Writer.Header().Values("Warning") {
+		existing[header] = true
+	}
+
+	for _, warning := range warnings {
+		header, err := utilnet.NewWarningHeader(utilnet.MiscellaneousPersistentWarning, "-", warning)
+		if err != nil {
+			log.Warnf("skip warning %q: %v", warning, err)
+
+			continue
+		}
+		if existing[header] {
+			continue
+		}
+		existing[header] = true
+		c.Writer.Header().Add("Warning", header)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package defaulting
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// TagName is the name of the struct tag holding the default value of a field.
+const TagName = "default"
+
+// FuncSource provides the defaulting functions registered for types. It is implemented
+// by Registry and by scheme.Scheme.
+type FuncSource interface {
+	// DefaultingFunc returns the function registered for the type, which is a pointer.
+	DefaultingFunc(t reflect.Type) (func(interface{}), bool)
+}
+
+// Registry holds defaulting functions for types which are not registered in a scheme,
+// e.g. configurations.
+type Registry struct {
+	funcs map[reflect.Type]func(interface{})
+}
+
+var _ FuncSource = &Registry{}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{funcs: map[reflect.Type]func(interface{}){}}
+}
+
+// AddTypeDefaultingFunc registers a function that is passed a pointer to an object of
+// the type of srcType, which must be a pointer, and can default fields on the object.
+func (r *Registry) AddTypeDefaultingFunc(srcType interface{}, fn func(interface{})) {
+	r.funcs[reflect.TypeOf(srcType)] = fn
+}
+
+// DefaultingFunc implements FuncSource.
+func (r *Registry) DefaultingFunc(t reflect.Type) (func(interface{}), bool) {
+	fn, ok := r.funcs[t]
+
+	return fn, ok
+}
+
+// SetDefaults sets the zero valued fields of obj, which must be a pointer, to the values of
+// their `default` tags, recursively.
+func SetDefaults(obj interface{}) error {
+	return SetDefaultsWithFuncs(obj, nil)
+}
+
+// SetDefaultsWithFuncs sets the zero valued fields of obj, which must be a pointer, to the
+// values of their `default` tags, recursively. The function registered in funcs for the type
+// of every struct is called once its fields have been defaulted, so it can derive defaults
+// from the other fields.
+func SetDefaultsWithFuncs(obj interface{}, funcs FuncSource) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("expected a non-nil pointer, got %T", obj)
+	}
+
+	return setDefaults(v.Elem(), "", funcs)
+}
+
+func setDefaults(v reflect.Value, path string, funcs FuncSource) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return setDefaults(v.Elem(), path, funcs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := setDefaults(v.Index(i), fmt.Sprintf("%s[%d]", path, i), funcs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values are not addressable, they are defaulted on a copy
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if err := setDefaults(value, fmt.Sprintf("%s[%v]", path, iter.Key()), funcs); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		return setStructDefaults(v, path, funcs)
+	}
+
+	return nil
+}
+
+func setStructDefaults(v reflect.Value, path string, funcs FuncSource) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+		if len(field.PkgPath) > 0 && !field.Anonymous {
+			continue
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = strings.TrimPrefix(path+"."+field.Name, ".")
+		}
+
+		if tag, ok := field.Tag.Lookup(TagName); ok && fieldValue.CanSet() && fieldValue.IsZero() {
+			if err := parseValue(fieldValue, tag); err != nil {
+				return fmt.Errorf("invalid default value %q for field %s: %v", tag, fieldPath, err)
+			}
+		}
+		if err := setDefaults(fieldValue, fieldPath, funcs); err != nil {
+			return err
+		}
+	}
+
+	if funcs != nil && v.CanAddr() {
+		if fn, ok := funcs.DefaultingFunc(reflect.PtrTo(t)); ok {
+			fn(v.Addr().Interface())
+		}
+	}
+
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// parseValue parses the default value of a field, into v. Durations are parsed with
+// time.ParseDuration, the types implementing encoding.TextUnmarshaler, like time.Time,
+// with their UnmarshalText method. Slices are comma separated lists and maps comma
+// separated key=value pairs, unless the value is a JSON array or object.
+func parseValue(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := parseValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		return parseSlice(v, value)
+	case reflect.Map:
+		return parseMap(v, value)
+	case reflect.Struct, reflect.Array, reflect.Interface:
+		return json.Unmarshal([]byte(value), v.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+
+	return nil
+}
+
+func parseSlice(v reflect.Value, value string) error {
+	if strings.HasPrefix(value, "[") {
+		return json.Unmarshal([]byte(value), v.Addr().Interface())
+	}
+
+	items := []string{}
+	if len(value) > 0 {
+		items = strings.Split(value, ",")
+	}
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := parseValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+
+	return nil
+}
+
+func parseMap(v reflect.Value, value string) error {
+	if strings.HasPrefix(value, "{") {
+		return json.Unmarshal([]byte(value), v.Addr().Interface())
+	}
+
+	m := reflect.MakeMap(v.Type())
+	for _, pair := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(pair)) == 0 {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("malformed pair %q, expected key=value", pair)
+		}
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := parseValue(key, strings.TrimSpace(kv[0])); err != nil {
+			return err
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := parseValue(elem, strings.TrimSpace(kv[1])); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, elem)
+	}
+	v.Set(m)
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package defaulting
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+type TLSOptions struct {
+	Port      int    `json:"port"      default:"8443"`
+	CertFile  string `json:"certFile"`
+	CertDir   string `json:"certDir"   default:"/var/run/iam"`
+	KeyLength uint16 `json:"keyLength" default:"0x800"`
+}
+
+type ServerOptions struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Mode            string                `json:"mode"        default:"release"`
+	Healthz         *bool                 `json:"healthz"     default:"true"`
+	Ratio           float64               `json:"ratio"       default:"0.5"`
+	ShutdownTimeout time.Duration         `json:"timeout"     default:"30s"`
+	StartedAt       time.Time             `json:"startedAt"   default:"2020-10-01T08:00:00Z"`
+	Middlewares     []string              `json:"middlewares" default:"recovery, logger"`
+	Ports           []int                 `json:"ports"       default:"[80,443]"`
+	Labels          map[string]string     `json:"labels"      default:"app=iam,tier=api"`
+	Limits          map[string]int        `json:"limits"      default:"{\"cpu\":2}"`
+	Replicas        *int32                `json:"replicas"    default:"3"`
+	TLS             TLSOptions            `json:"tls"`
+	Backends        []TLSOptions          `json:"backends"`
+	Named           map[string]TLSOptions `json:"named"`
+}
+
+func TestSetDefaults(t *testing.T) {
+	opts := &ServerOptions{
+		Mode:     "debug",
+		Labels:   map[string]string{},
+		Backends: []TLSOptions{{Port: 443}, {}},
+		Named:    map[string]TLSOptions{"a": {CertDir: "/tmp"}},
+	}
+	if err := SetDefaults(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	healthz, replicas := true, int32(3)
+	expected := &ServerOptions{
+		Mode:            "debug",
+		Healthz:         &healthz,
+		Ratio:           0.5,
+		ShutdownTimeout: 30 * time.Second,
+		StartedAt:       time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC),
+		Middlewares:     []string{"recovery", "logger"},
+		Ports:           []int{80, 443},
+		Labels:          map[string]string{},
+		Limits:          map[string]int{"cpu": 2},
+		Replicas:        &replicas,
+		TLS:             TLSOptions{Port: 8443, CertDir: "/var/run/iam", KeyLength: 2048},
+		Backends: []TLSOptions{
+			{Port: 443, CertDir: "/var/run/iam", KeyLength: 2048},
+			{Port: 8443, CertDir: "/var/run/iam", KeyLength: 2048},
+		},
+		Named: map[string]TLSOptions{"a": {Port: 8443, CertDir: "/tmp", KeyLength: 2048}},
+	}
+	if !reflect.DeepEqual(opts, expected) {
+		t.Errorf("expected %#v, got %#v", expected, opts)
+	}
+
+	opts = &ServerOptions{}
+	if err := SetDefaults(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(opts.Labels, map[string]string{"app": "iam", "tier": "api"}) {
+		t.Errorf("unexpected labels %v", opts.Labels)
+	}
+}
+
+func TestSetDefaultsErrors(t *testing.T) {
+	testCases := []struct {
+		obj      interface{}
+		expected string
+	}{
+		{
+			obj:      ServerOptions{},
+			expected: "expected a non-nil pointer, got defaulting.ServerOptions",
+		},
+		{
+			obj: &struct {
+				TLS struct {
+					Port int `default:"https"`
+				}
+			}{},
+			expected: `invalid default value "https" for field TLS.Port: strconv.ParseInt: parsing "https": invalid syntax`,
+		},
+		{
+			obj: &struct {
+				Labels map[string]string `default:"app"`
+			}{},
+			expected: `invalid default value "app" for field Labels: malformed pair "app", expected key=value`,
+		},
+		{
+			obj: &struct {
+				Timeout time.Duration `default:"30"`
+			}{},
+			expected: `invalid default value "30" for field Timeout: time: missing unit in duration "30"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		if err := SetDefaults(tc.obj); err == nil || err.Error() != tc.expected {
+			t.Errorf("expected error %q, got %v", tc.expected, err)
+		}
+	}
+}
+
+func TestSetDefaultsWithFuncs(t *testing.T) {
+	r := NewRegistry()
+	r.AddTypeDefaultingFunc(&TLSOptions{}, func(obj interface{}) {
+		if opts := obj.(*TLSOptions); opts.CertFile == "" {
+			opts.CertFile = opts.CertDir + "/server.crt"
+		}
+	})
+
+	opts := &ServerOptions{Backends: []TLSOptions{{CertDir: "/tmp"}}}
+	if err := SetDefaultsWithFuncs(opts, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.TLS.CertFile != "/var/run/iam/server.crt" || opts.Backends[0].CertFile != "/tmp/server.crt" {
+		t.Errorf("expected the functions to run after the tags, got %#v", opts)
+	}
+}
+
+func TestSetDefaultsFlags(t *testing.T) {
+	opts := &ServerOptions{}
+	if err := SetDefaults(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.StringVar(&opts.Mode, "mode", opts.Mode, "Server mode.")
+	fs.DurationVar(&opts.ShutdownTimeout, "shutdown-timeout", opts.ShutdownTimeout, "Shutdown timeout.")
+	if err := fs.Parse([]string{"--mode=debug"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.Mode != "debug" || opts.ShutdownTimeout != 30*time.Second {
+		t.Errorf("expected the flags to override the defaults, got %#v", opts)
+	}
+	if def := fs.Lookup("mode").DefValue; def != "release" {
+		t.Errorf("expected the flag to show the default, got %q", def)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package defaulting sets the default values of go objects, from the `default` tags of
+// their fields and from the defaulting functions registered for their types:
+//
+//	type ServerRunOptions struct {
+//		Mode            string            `json:"mode"        default:"release"`
+//		Healthz         *bool             `json:"healthz"     default:"true"`
+//		ShutdownTimeout time.Duration     `json:"timeout"     default:"30s"`
+//		Middlewares     []string          `json:"middlewares" default:"recovery,logger"`
+//		Labels          map[string]string `json:"labels"      default:"app=iam,tier=api"`
+//	}
+//
+// Only the zero valued fields are defaulted, so a bool field defaulting to true must be a
+// *bool: an explicit false could not be told from an unset field.
+//
+// Defaults are set before validation, on API objects when they are decoded, and on
+// configurations before their flags are added by flag.AddFlagsWithDefaults of
+// pkg/cli/flag, so that the flags show the defaults.
+package defaulting // import "github.com/marmotedu/component-base/pkg/defaulting"
@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/scheme"
+)
+
+// Interface provides the discovery documents of an API server.
+type Interface interface {
+	// ServerGroups returns the supported groups, with information like supported versions
+	// and preferred version.
+	ServerGroups() (*metav1.APIGroupList, error)
+	// ServerResourcesForGroupVersion returns the supported resources for a group and version.
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// Client fetches the discovery documents served by Registry.Install over http.
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+var _ Interface = &Client{}
+
+// NewClient creates a Client for the API server at baseURL, e.g. "https://127.0.0.1:8443".
+// http.DefaultClient is used if client is nil.
+func NewClient(baseURL string, client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// ServerGroups implements Interface.
+func (c *Client) ServerGroups() (*metav1.APIGroupList, error) {
+	list := &metav1.APIGroupList{}
+	if err := c.get(APIPrefix, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ServerResourcesForGroupVersion implements Interface.
+func (c *Client) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	gv, err := scheme.ParseGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metav1.APIResourceList{}
+	if err := c.get(APIPrefix+"/"+gv.Group+"/"+gv.Version, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (c *Client) get(path string, v interface{}) error {
+	resp, err := c.client.Get(c.baseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp core.ErrResponse
+		if err := json.Unmarshal(data, &errResp); err == nil && len(errResp.Message) > 0 {
+			return fmt.Errorf("%s", errResp.Message)
+		}
+
+		return fmt.Errorf("unexpected status code %d getting %s", resp.StatusCode, path)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// ServerGroupsAndResources returns the supported groups and the resources of all their versions.
+func ServerGroupsAndResources(d Interface) ([]metav1.APIGroup, []*metav1.APIResourceList, error) {
+	groups, err := d.ServerGroups()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resources []*metav1.APIResourceList
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			list, err := d.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return nil, nil, err
+			}
+			resources = append(resources, list)
+		}
+	}
+
+	return groups.Groups, resources, nil
+}
@@ -0,0 +1,9 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package discovery generates the discovery documents of the resources served by an
+// API server from the types registered in a scheme, serves them under /apis, and
+// provides the client side helpers to fetch them and to map resource arguments to
+// fully qualified resources and kinds.
+package discovery // import "github.com/marmotedu/component-base/pkg/discovery"
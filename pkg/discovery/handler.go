@@ -0,0 +1,62 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/scheme"
+)
+
+// APIPrefix is the path prefix under which the discovery documents are served.
+const APIPrefix = "/apis"
+
+// Install serves the discovery documents of the registry on the router:
+//
+//	GET /apis                   the APIGroupList
+//	GET /apis/:group            the APIGroup
+//	GET /apis/:group/:version   the APIResourceList
+func (r *Registry) Install(router gin.IRouter) {
+	router.GET(APIPrefix, r.serveGroupList)
+	router.GET(APIPrefix+"/:group", r.serveGroup)
+	router.GET(APIPrefix+"/:group/:version", r.serveResourceList)
+}
+
+func (r *Registry) serveGroupList(c *gin.Context) {
+	c.JSON(http.StatusOK, r.APIGroupList())
+}
+
+func (r *Registry) serveGroup(c *gin.Context) {
+	group, ok := r.APIGroup(c.Param("group"))
+	if !ok {
+		notFound(c)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+func (r *Registry) serveResourceList(c *gin.Context) {
+	list, ok := r.APIResourceList(scheme.GroupVersion{Group: c.Param("group"), Version: c.Param("version")})
+	if !ok {
+		notFound(c)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func notFound(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusNotFound, core.ErrResponse{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("the server could not find the requested resource: %s", c.Request.URL.Path),
+	})
+}
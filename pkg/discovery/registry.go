@@ -0,0 +1,210 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/scheme"
+	"github.com/marmotedu/component-base/pkg/util/sets"
+)
+
+// DefaultVerbs are the verbs of the resources registered without explicit verbs.
+var DefaultVerbs = metav1.Verbs{"create", "delete", "get", "list", "patch", "update", "watch"}
+
+// ResourceOptions describes a resource served for a kind. Empty names are generated
+// from the kind.
+type ResourceOptions struct {
+	// Name is the plural name of the resource, e.g. "policies".
+	Name string
+	// SingularName is the singular name of the resource, e.g. "policy".
+	SingularName string
+	// ShortNames are the short names of the resource, e.g. "pol".
+	ShortNames []string
+	// Verbs are the verbs supported by the resource, DefaultVerbs if empty.
+	Verbs []string
+	// Categories are the categories the resource belongs to, e.g. "all".
+	Categories []string
+}
+
+// Registry holds the resources served for the kinds registered in a scheme, and
+// builds the discovery documents describing them.
+//
+// Like the scheme, a Registry is not expected to change at runtime and is only
+// threadsafe after registration is complete.
+type Registry struct {
+	scheme    *scheme.Scheme
+	resources map[scheme.GroupVersion][]metav1.APIResource
+}
+
+// NewRegistry creates a Registry for the kinds registered in s.
+func NewRegistry(s *scheme.Scheme) *Registry {
+	return &Registry{
+		scheme:    s,
+		resources: map[scheme.GroupVersion][]metav1.APIResource{},
+	}
+}
+
+// AddResource serves a resource for the given kind, which must be registered in the scheme.
+func (r *Registry) AddResource(gvk scheme.GroupVersionKind, opts ResourceOptions) error {
+	if !r.scheme.Recognizes(gvk) {
+		return scheme.NewNotRegisteredErrForKind(gvk)
+	}
+
+	plural, singular := KindToResource(gvk.Kind)
+	if len(opts.Name) > 0 {
+		plural = strings.ToLower(opts.Name)
+	}
+	if len(opts.SingularName) > 0 {
+		singular = strings.ToLower(opts.SingularName)
+	}
+	verbs := DefaultVerbs
+	if len(opts.Verbs) > 0 {
+		verbs = metav1.Verbs(opts.Verbs)
+	}
+
+	gv := gvk.GroupVersion()
+	for _, resource := range r.resources[gv] {
+		if resource.Name == plural {
+			return fmt.Errorf("resource %q is already registered for %q", plural, gv)
+		}
+	}
+	r.resources[gv] = append(r.resources[gv], metav1.APIResource{
+		Name:         plural,
+		SingularName: singular,
+		Kind:         gvk.Kind,
+		Verbs:        append(metav1.Verbs(nil), verbs...),
+		ShortNames:   append([]string(nil), opts.ShortNames...),
+		Categories:   append([]string(nil), opts.Categories...),
+	})
+
+	return nil
+}
+
+// AddGroupVersion serves a resource with the given verbs for every kind registered in
+// the group version, but the list and options kinds.
+func (r *Registry) AddGroupVersion(gv scheme.GroupVersion, verbs ...string) error {
+	for _, gvk := range r.scheme.AllKinds() {
+		if gvk.GroupVersion() != gv || strings.HasSuffix(gvk.Kind, "List") || strings.HasSuffix(gvk.Kind, "Options") {
+			continue
+		}
+		if err := r.AddResource(gvk, ResourceOptions{Verbs: verbs}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// APIGroupList returns the groups which have resources, in the order their versions
+// have been registered in the scheme.
+func (r *Registry) APIGroupList() *metav1.APIGroupList {
+	list := &metav1.APIGroupList{
+		TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+		Groups:   []metav1.APIGroup{},
+	}
+	seen := sets.NewString()
+	for _, gv := range r.groupVersions() {
+		if seen.Has(gv.Group) {
+			continue
+		}
+		seen.Insert(gv.Group)
+		group, _ := r.APIGroup(gv.Group)
+		list.Groups = append(list.Groups, *group)
+	}
+
+	return list
+}
+
+// APIGroup returns the versions of a group which have resources. The preferred version
+// is the first version of the group registered in the scheme.
+func (r *Registry) APIGroup(name string) (*metav1.APIGroup, bool) {
+	group := &metav1.APIGroup{
+		TypeMeta: metav1.TypeMeta{Kind: "APIGroup", APIVersion: "v1"},
+		Name:     name,
+	}
+	for _, gv := range r.groupVersions() {
+		if gv.Group != name {
+			continue
+		}
+		group.Versions = append(group.Versions, metav1.GroupVersionForDiscovery{
+			GroupVersion: gv.String(),
+			Version:      gv.Version,
+		})
+	}
+	if len(group.Versions) == 0 {
+		return nil, false
+	}
+	group.PreferredVersion = group.Versions[0]
+
+	return group, true
+}
+
+// APIResourceList returns the resources served for a group version.
+func (r *Registry) APIResourceList(gv scheme.GroupVersion) (*metav1.APIResourceList, bool) {
+	resources, ok := r.resources[gv]
+	if !ok {
+		return nil, false
+	}
+
+	return &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: gv.String(),
+		APIResources: append([]metav1.APIResource(nil), resources...),
+	}, true
+}
+
+// ServerGroups implements Interface.
+func (r *Registry) ServerGroups() (*metav1.APIGroupList, error) {
+	return r.APIGroupList(), nil
+}
+
+// ServerResourcesForGroupVersion implements Interface.
+func (r *Registry) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	gv, err := scheme.ParseGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := r.APIResourceList(gv)
+	if !ok {
+		return nil, fmt.Errorf("the server could not find the requested resource: %s", groupVersion)
+	}
+
+	return list, nil
+}
+
+// groupVersions returns the group versions which have resources, in the order they
+// have been registered in the scheme.
+func (r *Registry) groupVersions() []scheme.GroupVersion {
+	var gvs []scheme.GroupVersion
+	for _, gv := range r.scheme.PrioritizedVersionsAllGroups() {
+		if _, ok := r.resources[gv]; ok {
+			gvs = append(gvs, gv)
+		}
+	}
+
+	return gvs
+}
+
+// KindToResource returns the plural and the singular resource names of a kind, e.g.
+// "policies" and "policy" for the "Policy" kind.
+func KindToResource(kind string) (plural, singular string) {
+	singular = strings.ToLower(kind)
+	switch {
+	case len(singular) == 0:
+		return "", ""
+	case strings.HasSuffix(singular, "s"), strings.HasSuffix(singular, "x"), strings.HasSuffix(singular, "z"),
+		strings.HasSuffix(singular, "ch"), strings.HasSuffix(singular, "sh"):
+		plural = singular + "es"
+	case strings.HasSuffix(singular, "y") && len(singular) > 1 && !strings.ContainsRune("aeiou", rune(singular[len(singular)-2])):
+		plural = singular[:len(singular)-1] + "ies"
+	default:
+		plural = singular + "s"
+	}
+
+	return plural, singular
+}
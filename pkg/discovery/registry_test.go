@@ -0,0 +1,119 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/scheme"
+)
+
+var (
+	iamV1      = scheme.GroupVersion{Group: "iam.marmotedu.com", Version: "v1"}
+	iamV2      = scheme.GroupVersion{Group: "iam.marmotedu.com", Version: "v2"}
+	settingsV1 = scheme.GroupVersion{Group: "settings.marmotedu.com", Version: "v1"}
+)
+
+type Secret struct {
+	metav1.TypeMeta `json:",inline"`
+}
+
+type SecretList struct {
+	metav1.TypeMeta `json:",inline"`
+}
+
+type Policy struct {
+	metav1.TypeMeta `json:",inline"`
+}
+
+type ListOptions struct {
+	metav1.TypeMeta `json:",inline"`
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	s := scheme.NewScheme()
+	s.AddKnownTypes(iamV1, &Secret{}, &SecretList{}, &Policy{}, &ListOptions{})
+	s.AddKnownTypes(iamV2, &Secret{})
+	s.AddKnownTypes(settingsV1, &Policy{})
+
+	r := NewRegistry(s)
+	if err := r.AddGroupVersion(iamV1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddResource(iamV2.WithKind("Secret"), ResourceOptions{ShortNames: []string{"sec"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddResource(settingsV1.WithKind("Policy"), ResourceOptions{
+		Name:       "settingpolicies",
+		ShortNames: []string{"pol"},
+		Verbs:      []string{"get", "list"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return r
+}
+
+func TestKindToResource(t *testing.T) {
+	testCases := map[string][2]string{
+		"Secret": {"secrets", "secret"},
+		"Policy": {"policies", "policy"},
+		"Key":    {"keys", "key"},
+		"Class":  {"classes", "class"},
+		"Box":    {"boxes", "box"},
+		"Match":  {"matches", "match"},
+		"Mesh":   {"meshes", "mesh"},
+		"":       {"", ""},
+	}
+
+	for kind, expected := range testCases {
+		plural, singular := KindToResource(kind)
+		if plural != expected[0] || singular != expected[1] {
+			t.Errorf("%q: expected %v, got %q and %q", kind, expected, plural, singular)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := newTestRegistry(t)
+
+	if err := r.AddResource(iamV2.WithKind("User"), ResourceOptions{}); !scheme.IsNotRegisteredError(err) {
+		t.Errorf("expected a not registered error, got %v", err)
+	}
+	if err := r.AddResource(iamV2.WithKind("Secret"), ResourceOptions{}); err == nil {
+		t.Errorf("expected an error registering a resource twice")
+	}
+
+	groups := r.APIGroupList()
+	if len(groups.Groups) != 2 || groups.Groups[0].Name != iamV1.Group || groups.Groups[1].Name != settingsV1.Group {
+		t.Fatalf("unexpected groups %#v", groups.Groups)
+	}
+	iam := groups.Groups[0]
+	expectedVersions := []metav1.GroupVersionForDiscovery{
+		{GroupVersion: "iam.marmotedu.com/v1", Version: "v1"},
+		{GroupVersion: "iam.marmotedu.com/v2", Version: "v2"},
+	}
+	if !reflect.DeepEqual(iam.Versions, expectedVersions) || iam.PreferredVersion != expectedVersions[0] {
+		t.Errorf("unexpected versions %#v", iam)
+	}
+
+	list, ok := r.APIResourceList(iamV1)
+	if !ok {
+		t.Fatalf("expected resources for %v", iamV1)
+	}
+	expected := []metav1.APIResource{
+		{Name: "policies", SingularName: "policy", Kind: "Policy", Verbs: DefaultVerbs},
+		{Name: "secrets", SingularName: "secret", Kind: "Secret", Verbs: DefaultVerbs},
+	}
+	if list.GroupVersion != "iam.marmotedu.com/v1" || !reflect.DeepEqual(list.APIResources, expected) {
+		t.Errorf("unexpected resources %#v", list)
+	}
+
+	if _, ok := r.APIResourceList(scheme.GroupVersion{Group: settingsV1.Group, Version: "v2"}); ok {
+		t.Errorf("expected no resources")
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/scheme"
+)
+
+// RESTMapping contains the information needed to deal with objects of a specific
+// resource and kind in a RESTful manner.
+type RESTMapping struct {
+	// Resource is the GroupVersionResource used to access the objects.
+	Resource scheme.GroupVersionResource
+	// GroupVersionKind is the kind of the objects.
+	GroupVersionKind scheme.GroupVersionKind
+	// Verbs are the verbs supported by the resource.
+	Verbs metav1.Verbs
+}
+
+// RESTMapper maps resources to kinds, and kinds to resources. The input resources may be
+// partially specified: the group and the version may be empty, the group may be the first
+// segments of the group name, and the resource may be a plural, singular or short name.
+type RESTMapper interface {
+	// KindFor takes a partial resource and returns the single match. Returns an error if
+	// there are multiple matches.
+	KindFor(resource scheme.GroupVersionResource) (scheme.GroupVersionKind, error)
+
+	// KindsFor takes a partial resource and returns the list of potential kinds in
+	// priority order.
+	KindsFor(resource scheme.GroupVersionResource) ([]scheme.GroupVersionKind, error)
+
+	// ResourceFor takes a partial resource and returns the single match. Returns an error
+	// if there are multiple matches.
+	ResourceFor(input scheme.GroupVersionResource) (scheme.GroupVersionResource, error)
+
+	// ResourcesFor takes a partial resource and returns the list of potential resources
+	// in priority order.
+	ResourcesFor(input scheme.GroupVersionResource) ([]scheme.GroupVersionResource, error)
+
+	// RESTMapping identifies a preferred resource mapping for the provided group kind.
+	// The mapping of the first given version found is returned, the mapping of the
+	// preferred version of the group if no version is given.
+	RESTMapping(gk scheme.GroupKind, versions ...string) (*RESTMapping, error)
+
+	// ResourceSingularizer returns the singular name of a resource.
+	ResourceSingularizer(resource string) (singular string, err error)
+}
+
+// AmbiguousResourceError is returned if the RESTMapper finds multiple matches for a resource.
+type AmbiguousResourceError struct {
+	PartialResource   scheme.GroupVersionResource
+	MatchingResources []scheme.GroupVersionResource
+}
+
+func (e *AmbiguousResourceError) Error() string {
+	return fmt.Sprintf("%v matches multiple resources %v", e.PartialResource, e.MatchingResources)
+}
+
+// NoResourceMatchError is returned if the RESTMapper can't find any match for a resource.
+type NoResourceMatchError struct {
+	PartialResource scheme.GroupVersionResource
+}
+
+func (e *NoResourceMatchError) Error() string {
+	return fmt.Sprintf("no matches for %v", e.PartialResource)
+}
+
+// NoKindMatchError is returned if the RESTMapper can't find any match for a kind.
+type NoKindMatchError struct {
+	GroupKind        scheme.GroupKind
+	SearchedVersions []string
+}
+
+func (e *NoKindMatchError) Error() string {
+	if len(e.SearchedVersions) == 0 {
+		return fmt.Sprintf("no matches for kind %q in group %q", e.GroupKind.Kind, e.GroupKind.Group)
+	}
+
+	return fmt.Sprintf("no matches for kind %q in versions %q", e.GroupKind.Kind, e.SearchedVersions)
+}
+
+// IsNoMatchError returns true if the error indicates that the RESTMapper found no match.
+func IsNoMatchError(err error) bool {
+	switch err.(type) {
+	case *NoResourceMatchError, *NoKindMatchError:
+		return true
+	default:
+		return false
+	}
+}
+
+// resourceMapping is a resource served in a group version.
+type resourceMapping struct {
+	RESTMapping
+	singular   string
+	shortNames []string
+}
+
+type restMapper struct {
+	// mappings are ordered by group, then by version with the preferred version first.
+	mappings []resourceMapping
+}
+
+// NewDiscoveryRESTMapper returns a RESTMapper for the resources served by the API server.
+// The groups and their versions are prioritized in the order they are discovered, the
+// preferred version of a group first.
+func NewDiscoveryRESTMapper(d Interface) (RESTMapper, error) {
+	groups, resources, err := ServerGroupsAndResources(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRESTMapper(groups, resources), nil
+}
+
+// NewRESTMapper returns a RESTMapper for the given discovery documents.
+func NewRESTMapper(groups []metav1.APIGroup, resources []*metav1.APIResourceList) RESTMapper {
+	byGroupVersion := map[string]*metav1.APIResourceList{}
+	for _, list := range resources {
+		byGroupVersion[list.GroupVersion] = list
+	}
+
+	m := &restMapper{}
+	for _, group := range groups {
+		versions := []metav1.GroupVersionForDiscovery{group.PreferredVersion}
+		for _, version := range group.Versions {
+			if version != group.PreferredVersion {
+				versions = append(versions, version)
+			}
+		}
+
+		for _, version := range versions {
+			list, ok := byGroupVersion[version.GroupVersion]
+			if !ok {
+				continue
+			}
+			for _, resource := range list.APIResources {
+				m.add(scheme.GroupVersion{Group: group.Name, Version: version.Version}, resource)
+			}
+		}
+	}
+
+	return m
+}
+
+func (m *restMapper) add(gv scheme.GroupVersion, resource metav1.APIResource) {
+	kindGV := gv
+	if len(resource.Group) > 0 {
+		kindGV.Group = resource.Group
+	}
+	if len(resource.Version) > 0 {
+		kindGV.Version = resource.Version
+	}
+
+	singular := resource.SingularName
+	if len(singular) == 0 {
+		singular = strings.ToLower(resource.Kind)
+	}
+
+	m.mappings = append(m.mappings, resourceMapping{
+		RESTMapping: RESTMapping{
+			Resource:         gv.WithResource(resource.Name),
+			GroupVersionKind: kindGV.WithKind(resource.Kind),
+			Verbs:            resource.Verbs,
+		},
+		singular:   singular,
+		shortNames: resource.ShortNames,
+	})
+}
+
+// KindFor implements RESTMapper.
+func (m *restMapper) KindFor(resource scheme.GroupVersionResource) (scheme.GroupVersionKind, error) {
+	mapping, err := m.mappingFor(resource)
+	if err != nil {
+		return scheme.GroupVersionKind{}, err
+	}
+
+	return mapping.GroupVersionKind, nil
+}
+
+// KindsFor implements RESTMapper.
+func (m *restMapper) KindsFor(resource scheme.GroupVersionResource) ([]scheme.GroupVersionKind, error) {
+	mappings, err := m.mappingsFor(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make([]scheme.GroupVersionKind, 0, len(mappings))
+	for _, mapping := range mappings {
+		kinds = append(kinds, mapping.GroupVersionKind)
+	}
+
+	return kinds, nil
+}
+
+// ResourceFor implements RESTMapper.
+func (m *restMapper) ResourceFor(input scheme.GroupVersionResource) (scheme.GroupVersionResource, error) {
+	mapping, err := m.mappingFor(input)
+	if err != nil {
+		return scheme.GroupVersionResource{}, err
+	}
+
+	return mapping.Resource, nil
+}
+
+// ResourcesFor implements RESTMapper.
+func (m *restMapper) ResourcesFor(input scheme.GroupVersionResource) ([]scheme.GroupVersionResource, error) {
+	mappings, err := m.mappingsFor(input)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]scheme.GroupVersionResource, 0, len(mappings))
+	for _, mapping := range mappings {
+		resources = append(resources, mapping.Resource)
+	}
+
+	return resources, nil
+}
+
+// RESTMapping implements RESTMapper.
+func (m *restMapper) RESTMapping(gk scheme.GroupKind, versions ...string) (*RESTMapping, error) {
+	matches := func(mapping resourceMapping, version string) bool {
+		gvk := mapping.GroupVersionKind
+		if gvk.Group != gk.Group || !strings.EqualFold(gvk.Kind, gk.Kind) {
+			return false
+		}
+
+		return len(version) == 0 || gvk.Version == version
+	}
+
+	if len(versions) == 0 {
+		versions = []string{""}
+	}
+	for _, version := range versions {
+		for _, mapping := range m.mappings {
+			if matches(mapping, version) {
+				restMapping := mapping.RESTMapping
+
+				return &restMapping, nil
+			}
+		}
+	}
+
+	searched := versions
+	if len(searched) == 1 && len(searched[0]) == 0 {
+		searched = nil
+	}
+
+	return nil, &NoKindMatchError{GroupKind: gk, SearchedVersions: searched}
+}
+
+// ResourceSingularizer implements RESTMapper.
+func (m *restMapper) ResourceSingularizer(resource string) (string, error) {
+	resource = strings.ToLower(resource)
+	for _, mapping := range m.mappings {
+		if mapping.Resource.Resource == resource || mapping.singular == resource {
+			return mapping.singular, nil
+		}
+	}
+
+	return resource, &NoResourceMatchError{PartialResource: scheme.GroupVersionResource{Resource: resource}}
+}
+
+// mappingFor returns the single group resource matching the input, in its most
+// preferred version.
+func (m *restMapper) mappingFor(input scheme.GroupVersionResource) (resourceMapping, error) {
+	mappings, err := m.mappingsFor(input)
+	if err != nil {
+		return resourceMapping{}, err
+	}
+
+	for _, mapping := range mappings[1:] {
+		if mapping.Resource.GroupResource() != mappings[0].Resource.GroupResource() {
+			ambiguous := &AmbiguousResourceError{PartialResource: input}
+			for _, mapping := range mappings {
+				ambiguous.MatchingResources = append(ambiguous.MatchingResources, mapping.Resource)
+			}
+
+			return resourceMapping{}, ambiguous
+		}
+	}
+
+	return mappings[0], nil
+}
+
+// mappingsFor returns the mappings matching the input in priority order. Matches on the
+// plural or singular names hide the matches on the short names.
+func (m *restMapper) mappingsFor(input scheme.GroupVersionResource) ([]resourceMapping, error) {
+	resource := strings.ToLower(input.Resource)
+
+	var byName, byShortName []resourceMapping
+	for _, mapping := range m.mappings {
+		gvr := mapping.Resource
+		if !matchGroup(gvr.Group, input.Group) || (len(input.Version) > 0 && gvr.Version != input.Version) {
+			continue
+		}
+
+		switch {
+		case gvr.Resource == resource || mapping.singular == resource:
+			byName = append(byName, mapping)
+		case containsFold(mapping.shortNames, resource):
+			byShortName = append(byShortName, mapping)
+		}
+	}
+
+	if len(byName) > 0 {
+		return byName, nil
+	}
+	if len(byShortName) > 0 {
+		return byShortName, nil
+	}
+
+	return nil, &NoResourceMatchError{PartialResource: input}
+}
+
+// matchGroup returns true if partial is empty, the group itself, or the first segments
+// of the group, e.g. "iam" for "iam.marmotedu.com".
+func matchGroup(group, partial string) bool {
+	return len(partial) == 0 || group == partial || strings.HasPrefix(group, partial+".")
+}
+
+func containsFold(items []string, item string) bool {
+	for _, i := range items {
+		if strings.EqualFold(i, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveResourceArg resolves a "resource", "resource.group" or "resource.version.group"
+// argument, as parsed by scheme.ParseResourceArg, into a fully specified resource and kind.
+// The "resource.version.group" interpretation is preferred when both match.
+func ResolveResourceArg(m RESTMapper, arg string) (scheme.GroupVersionResource, scheme.GroupVersionKind, error) {
+	fullySpecified, gr := scheme.ParseResourceArg(arg)
+	if fullySpecified != nil {
+		if gvk, err := m.KindFor(*fullySpecified); err == nil {
+			gvr, err := m.ResourceFor(*fullySpecified)
+
+			return gvr, gvk, err
+		}
+	}
+
+	gvr, err := m.ResourceFor(gr.WithVersion(""))
+	if err != nil {
+		return scheme.GroupVersionResource{}, scheme.GroupVersionKind{}, err
+	}
+
+	gvk, err := m.KindFor(gvr)
+
+	return gvr, gvk, err
+}
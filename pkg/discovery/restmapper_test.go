@@ -0,0 +1,159 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/marmotedu/component-base/pkg/scheme"
+)
+
+func newTestRESTMapper(t *testing.T) RESTMapper {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	newTestRegistry(t).Install(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	m, err := NewDiscoveryRESTMapper(NewClient(server.URL, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return m
+}
+
+func TestClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	r := newTestRegistry(t)
+	r.Install(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", nil)
+	groups, err := client.ServerGroups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(groups, r.APIGroupList()) {
+		t.Errorf("expected %#v, got %#v", r.APIGroupList(), groups)
+	}
+
+	list, err := client.ServerResourcesForGroupVersion("settings.marmotedu.com/v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, _ := r.APIResourceList(settingsV1); !reflect.DeepEqual(list, expected) {
+		t.Errorf("expected %#v, got %#v", expected, list)
+	}
+
+	if _, err := client.ServerResourcesForGroupVersion("settings.marmotedu.com/v2"); err == nil ||
+		err.Error() != "the server could not find the requested resource: /apis/settings.marmotedu.com/v2" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRESTMapperResourceFor(t *testing.T) {
+	m := newTestRESTMapper(t)
+
+	testCases := []struct {
+		input    scheme.GroupVersionResource
+		expected scheme.GroupVersionResource
+		kind     scheme.GroupVersionKind
+	}{
+		{scheme.GroupVersionResource{Resource: "secrets"}, iamV1.WithResource("secrets"), iamV1.WithKind("Secret")},
+		{scheme.GroupVersionResource{Resource: "Secret"}, iamV1.WithResource("secrets"), iamV1.WithKind("Secret")},
+		{scheme.GroupVersionResource{Resource: "sec"}, iamV2.WithResource("secrets"), iamV2.WithKind("Secret")},
+		{iamV2.WithResource("secret"), iamV2.WithResource("secrets"), iamV2.WithKind("Secret")},
+		{scheme.GroupVersionResource{Group: "iam", Resource: "policy"}, iamV1.WithResource("policies"), iamV1.WithKind("Policy")},
+		{scheme.GroupVersionResource{Resource: "pol"}, settingsV1.WithResource("settingpolicies"), settingsV1.WithKind("Policy")},
+	}
+
+	for _, tc := range testCases {
+		gvr, err := m.ResourceFor(tc.input)
+		if err != nil || gvr != tc.expected {
+			t.Errorf("%v: expected %v, got %v (%v)", tc.input, tc.expected, gvr, err)
+		}
+		gvk, err := m.KindFor(tc.input)
+		if err != nil || gvk != tc.kind {
+			t.Errorf("%v: expected %v, got %v (%v)", tc.input, tc.kind, gvk, err)
+		}
+	}
+
+	if _, err := m.ResourceFor(scheme.GroupVersionResource{Resource: "users"}); !IsNoMatchError(err) {
+		t.Errorf("expected a no match error, got %v", err)
+	}
+	if _, err := m.ResourceFor(scheme.GroupVersionResource{Group: "ia", Resource: "secrets"}); !IsNoMatchError(err) {
+		t.Errorf("expected partial groups to match whole segments, got %v", err)
+	}
+
+	_, err := m.ResourceFor(scheme.GroupVersionResource{Resource: "policy"})
+	ambiguous, ok := err.(*AmbiguousResourceError)
+	expected := []scheme.GroupVersionResource{iamV1.WithResource("policies"), settingsV1.WithResource("settingpolicies")}
+	if !ok || !reflect.DeepEqual(ambiguous.MatchingResources, expected) {
+		t.Errorf("expected an ambiguous resource error, got %v", err)
+	}
+
+	resources, err := m.ResourcesFor(scheme.GroupVersionResource{Resource: "secrets"})
+	expected = []scheme.GroupVersionResource{iamV1.WithResource("secrets"), iamV2.WithResource("secrets")}
+	if err != nil || !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, resources, err)
+	}
+}
+
+func TestRESTMapping(t *testing.T) {
+	m := newTestRESTMapper(t)
+
+	mapping, err := m.RESTMapping(scheme.GroupKind{Group: iamV1.Group, Kind: "Secret"})
+	if err != nil || mapping.Resource != iamV1.WithResource("secrets") || mapping.GroupVersionKind != iamV1.WithKind("Secret") {
+		t.Errorf("unexpected mapping %#v (%v)", mapping, err)
+	}
+
+	mapping, err = m.RESTMapping(scheme.GroupKind{Group: iamV1.Group, Kind: "Secret"}, "v3", "v2")
+	if err != nil || mapping.Resource != iamV2.WithResource("secrets") {
+		t.Errorf("unexpected mapping %#v (%v)", mapping, err)
+	}
+
+	mapping, err = m.RESTMapping(scheme.GroupKind{Group: settingsV1.Group, Kind: "Policy"})
+	if err != nil || !mapping.Verbs.Has("list") || mapping.Verbs.Has("delete") {
+		t.Errorf("unexpected mapping %#v (%v)", mapping, err)
+	}
+
+	if _, err := m.RESTMapping(scheme.GroupKind{Group: iamV1.Group, Kind: "Policy"}, "v2"); !IsNoMatchError(err) {
+		t.Errorf("expected a no match error, got %v", err)
+	}
+
+	if singular, err := m.ResourceSingularizer("settingpolicies"); err != nil || singular != "policy" {
+		t.Errorf("unexpected singular %q (%v)", singular, err)
+	}
+}
+
+func TestResolveResourceArg(t *testing.T) {
+	m := newTestRESTMapper(t)
+
+	testCases := map[string]scheme.GroupVersionResource{
+		"secrets":                       iamV1.WithResource("secrets"),
+		"sec":                           iamV2.WithResource("secrets"),
+		"secrets.iam":                   iamV1.WithResource("secrets"),
+		"secrets.v2.iam.marmotedu.com":  iamV2.WithResource("secrets"),
+		"policy.settings.marmotedu.com": settingsV1.WithResource("settingpolicies"),
+	}
+
+	for arg, expected := range testCases {
+		gvr, gvk, err := ResolveResourceArg(m, arg)
+		if err != nil || gvr != expected || gvk.GroupVersion() != expected.GroupVersion() {
+			t.Errorf("%q: expected %v, got %v %v (%v)", arg, expected, gvr, gvk, err)
+		}
+	}
+
+	if _, _, err := ResolveResourceArg(m, "secrets.v3.iam"); !IsNoMatchError(err) {
+		t.Errorf("expected a no match error, got %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fields
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Adapter builds the Fields of the structs of a kind by reflection, so that they can be
+// matched by field selectors without writing a Set for each of them. The fields are named
+// after their JSON paths, e.g. "metadata.name" or "spec.owner", or after their field tag,
+// e.g. `field:"owner"`. A field tagged `field:"-"` cannot be selected.
+//
+// The fields which can be selected are the booleans, numbers, strings and
+// encoding.TextMarshalers, e.g. time.Time, with their pointers. The fields of a nil pointer
+// are empty. The unexported fields, including the embedded ones, and the fields of the
+// recursive types below their first occurrence are ignored.
+type Adapter struct {
+	kind   string
+	typ    reflect.Type
+	fields map[string][]int
+}
+
+// structField is a field found by walking a struct type.
+type structField struct {
+	index []int
+	typ   reflect.Type
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// NewAdapter returns an Adapter for the structs of a kind, of the type of obj, a struct or
+// a pointer to a struct. If selectable is not empty, only these fields can be selected,
+// otherwise all the supported fields can be. It returns an error if a selectable field
+// does not exist or has an unsupported type.
+func NewAdapter(kind string, obj interface{}, selectable ...string) (*Adapter, error) {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot select the fields of %T for %s: not a struct", obj, kind)
+	}
+
+	all := map[string]structField{}
+	if err := walkStruct(t, "", nil, map[reflect.Type]bool{}, all); err != nil {
+		return nil, fmt.Errorf("cannot select the fields of %v for %s: %v", t, kind, err)
+	}
+
+	fields := map[string][]int{}
+	if len(selectable) == 0 {
+		for name, f := range all {
+			if isSelectableType(f.typ) {
+				fields[name] = f.index
+			}
+		}
+	}
+	for _, name := range selectable {
+		f, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found in %v for %s", name, t, kind)
+		}
+		if !isSelectableType(f.typ) {
+			return nil, fmt.Errorf("field %q of %v for %s has the unsupported type %v", name, t, kind, f.typ)
+		}
+		fields[name] = f.index
+	}
+	return &Adapter{kind: kind, typ: t, fields: fields}, nil
+}
+
+// MustNewAdapter is like NewAdapter but panics on error, e.g. for the adapters declared as
+// package variables.
+func MustNewAdapter(kind string, obj interface{}, selectable ...string) *Adapter {
+	adapter, err := NewAdapter(kind, obj, selectable...)
+	if err != nil {
+		panic(err)
+	}
+	return adapter
+}
+
+// Kind returns the kind of the structs of the adapter.
+func (a *Adapter) Kind() string {
+	return a.kind
+}
+
+// Selectable returns the sorted names of the fields which can be selected.
+func (a *Adapter) Selectable() []string {
+	names := make([]string, 0, len(a.fields))
+	for name := range a.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fields returns the selectable fields of obj, a struct of the type of the adapter or a
+// pointer to it.
+func (a *Adapter) Fields(obj interface{}) (Set, error) {
+	v := reflect.ValueOf(obj)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("cannot select the fields of a nil %T for %s", obj, a.kind)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() != a.typ {
+		return nil, fmt.Errorf("cannot select the fields of %T for %s: expected %v", obj, a.kind, a.typ)
+	}
+
+	set := make(Set, len(a.fields))
+	for name, index := range a.fields {
+		value, err := fieldValue(v, index)
+		if err != nil {
+			return nil, fmt.Errorf("cannot select the field %q of %v for %s: %v", name, a.typ, a.kind, err)
+		}
+		set[name] = value
+	}
+	return set, nil
+}
+
+// ParseSelector parses a field selector like ParseSelector, and returns an error if it
+// selects a field which is not selectable.
+func (a *Adapter) ParseSelector(selector string) (Selector, error) {
+	return ParseAndTransformSelector(selector, func(field, value string) (string, string, error) {
+		if _, ok := a.fields[field]; !ok {
+			return "", "", fmt.Errorf("field label not supported for %s: %q, supported: %s", a.kind, field,
+				strings.Join(a.Selectable(), ", "))
+		}
+		return field, value, nil
+	})
+}
+
+// walkStruct adds the fields of the struct type t, and of the structs it contains, to
+// fields, named after their JSON paths prefixed by prefix. visiting holds the struct types
+// being walked, to stop at the recursive types.
+func walkStruct(t reflect.Type, prefix string, index []int, visiting map[reflect.Type]bool,
+	fields map[string]structField) error {
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag, hasTag := sf.Tag.Lookup("field")
+		if tag == "-" {
+			continue
+		}
+		name, inline := structFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		path := name
+		switch {
+		case inline:
+			path = prefix
+		case len(prefix) > 0:
+			path = prefix + "." + name
+		}
+
+		if !isSelectableType(ft) && ft.Kind() == reflect.Struct {
+			if hasTag {
+				return fmt.Errorf("field tag %q on the struct field %s", tag, sf.Name)
+			}
+			if err := walkStruct(ft, path, fieldIndex, visiting, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		if inline {
+			continue
+		}
+
+		f := structField{index: fieldIndex, typ: ft}
+		if hasTag {
+			if !isSelectableType(ft) {
+				return fmt.Errorf("field tag %q on the field %s of the unsupported type %v", tag, sf.Name, ft)
+			}
+			if _, ok := fields[tag]; ok {
+				return fmt.Errorf("duplicate field %q", tag)
+			}
+			fields[tag] = f
+		}
+		if _, ok := fields[path]; !ok {
+			fields[path] = f
+		}
+	}
+	return nil
+}
+
+// structFieldName returns the JSON name of a struct field, and true for the embedded
+// structs without JSON name, whose fields are inlined.
+func structFieldName(sf reflect.StructField) (string, bool) {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	switch {
+	case len(name) > 0:
+		return name, false
+	case sf.Anonymous:
+		return "", true
+	default:
+		return sf.Name, false
+	}
+}
+
+// isSelectableType returns true for the types whose values can be selected.
+func isSelectableType(t reflect.Type) bool {
+	if isTextMarshaler(t) {
+		return true
+	}
+
+	//nolint: exhaustive
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTextMarshaler returns true if t or its pointer implements encoding.TextMarshaler.
+func isTextMarshaler(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+// fieldValue returns the string value of the field of v at index. It is empty if the field
+// is in a nil struct pointer, or is a nil pointer.
+func fieldValue(v reflect.Value, index []int) (string, error) {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if isTextMarshaler(v.Type()) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	default:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package fields
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestMeta struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	Labels    map[string]string
+}
+
+type testPhase string
+
+type testSecret struct {
+	TestMeta `json:"metadata"`
+	Kind     string `json:"kind"`
+	Spec     struct {
+		Owner    *string   `json:"owner"    field:"owner"`
+		Phase    testPhase `json:"phase"`
+		Replicas int32     `json:"replicas"`
+		Ratio    float64   `json:"ratio"`
+		Enabled  bool      `json:"enabled"`
+		Hidden   string    `json:"hidden"   field:"-"`
+		Ignored  string    `json:"-"`
+		Tags     []string  `json:"tags"`
+	} `json:"spec"`
+	Parent *testSecret `json:"parent,omitempty"`
+	Inline
+	internal string
+}
+
+type Inline struct {
+	Region string `json:"region"`
+}
+
+func TestAdapterFields(t *testing.T) {
+	adapter, err := NewAdapter("Secret", &testSecret{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"kind", "metadata.createdAt", "metadata.name", "owner", "region", "spec.enabled", "spec.owner",
+		"spec.phase", "spec.ratio", "spec.replicas",
+	}
+	if got := adapter.Selectable(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Selectable() = %v, want %v", got, want)
+	}
+
+	owner := "colin"
+	secret := testSecret{TestMeta: TestMeta{Name: "db", CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}}
+	secret.Spec.Owner = &owner
+	secret.Spec.Phase = "Active"
+	secret.Spec.Replicas = 3
+	secret.Spec.Ratio = 0.5
+	secret.Region = "sh"
+
+	set, err := adapter.Fields(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for field, value := range map[string]string{
+		"metadata.name":      "db",
+		"metadata.createdAt": "2020-01-02T03:04:05Z",
+		"owner":              "colin",
+		"spec.owner":         "colin",
+		"spec.phase":         "Active",
+		"spec.replicas":      "3",
+		"spec.ratio":         "0.5",
+		"spec.enabled":       "false",
+		"region":             "sh",
+	} {
+		if !set.Has(field) || set.Get(field) != value {
+			t.Errorf("Fields()[%q] = %q, want %q", field, set.Get(field), value)
+		}
+	}
+
+	if _, err := adapter.Fields(&TestMeta{}); err == nil {
+		t.Errorf("expected an error for another type")
+	}
+	if _, err := adapter.Fields((*testSecret)(nil)); err == nil {
+		t.Errorf("expected an error for a nil pointer")
+	}
+}
+
+func TestAdapterSelector(t *testing.T) {
+	adapter := MustNewAdapter("Secret", testSecret{}, "metadata.name", "owner", "spec.phase")
+	if got, want := adapter.Selectable(), []string{"metadata.name", "owner", "spec.phase"}; !reflect.DeepEqual(got,
+		want) {
+		t.Errorf("Selectable() = %v, want %v", got, want)
+	}
+
+	owner := "colin"
+	secret := &testSecret{TestMeta: TestMeta{Name: "db"}}
+	secret.Spec.Owner = &owner
+	set, err := adapter.Fields(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Set{"metadata.name": "db", "owner": "colin", "spec.phase": ""}); !reflect.DeepEqual(set, want) {
+		t.Errorf("Fields() = %v, want %v", set, want)
+	}
+
+	selector, err := adapter.ParseSelector("metadata.name=db,owner!=lingfei")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !selector.Matches(set) {
+		t.Errorf("%v does not match %v", selector, set)
+	}
+
+	_, err = adapter.ParseSelector("metadata.name=db,spec.replicas=3")
+	if err == nil || !strings.Contains(err.Error(), `field label not supported for Secret: "spec.replicas"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewAdapterErrors(t *testing.T) {
+	type tagged struct {
+		Spec struct {
+			Owner string `json:"owner" field:"owner"`
+		} `json:"spec" field:"spec"`
+	}
+	type duplicate struct {
+		Owner string `json:"owner"`
+		Name  string `json:"name"  field:"owner"`
+	}
+
+	tests := []struct {
+		obj        interface{}
+		selectable []string
+		want       string
+	}{
+		{obj: "secret", want: "not a struct"},
+		{obj: testSecret{}, selectable: []string{"spec.missing"}, want: `field "spec.missing" not found`},
+		{obj: testSecret{}, selectable: []string{"spec.tags"}, want: `has the unsupported type []string`},
+		{obj: testSecret{}, selectable: []string{"spec.hidden"}, want: `field "spec.hidden" not found`},
+		{obj: tagged{}, want: `field tag "spec" on the struct field Spec`},
+		{obj: duplicate{}, want: `duplicate field "owner"`},
+	}
+	for _, test := range tests {
+		_, err := NewAdapter("Secret", test.obj, test.selectable...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("NewAdapter(%T, %v) error = %v, want %q", test.obj, test.selectable, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+type secret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Username    string `json:"username"`
+	SecretID    string `json:"secretID"`
+	SecretKey   string `json:"secretKey"`
+	Expires     int64  `json:"expires"`
+	Description string `json:"description"`
+}
+
+type secretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:",inline"`
+
+	Items []*secret `json:"items"`
+}
+
+func newSecret(i int) *secret {
+	now := time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	return &secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "iam.marmotedu.com/v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			ID:              uint64(i),
+			InstanceID:      fmt.Sprintf("secret-%08d", i),
+			Name:            fmt.Sprintf("secret-%d", i),
+			Extend:          metav1.Extend{"team": "iam", "replicas": float64(3)},
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			ResourceVersion: fmt.Sprint(i),
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:    "iamctl",
+				Operation:  metav1.ManagedFieldsOperationApply,
+				APIVersion: "iam.marmotedu.com/v1",
+				Time:       now,
+				Fields:     []string{"description", "expires"},
+			}},
+		},
+		Username:    "admin",
+		SecretID:    "Z6hKV8GDQQnu0NgVpQYtbuWBPeCGgLmHStMc",
+		SecretKey:   "XJnOqcrAFTV4afzmuZEXsRXj8QB2Aqd1",
+		Expires:     1600000000,
+		Description: "secret <used> by the iam-authz-server & iam-pump",
+	}
+}
+
+func newSecretList(n int) *secretList {
+	list := &secretList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "iam.marmotedu.com/v1", Kind: "SecretList"},
+		ListMeta: metav1.ListMeta{TotalCount: int64(n)},
+	}
+	for i := 0; i < n; i++ {
+		list.Items = append(list.Items, newSecret(i))
+	}
+
+	return list
+}
+
+func newWatchEvent() metav1.WatchEvent {
+	data, _ := json.Marshal(newSecret(1))
+
+	return metav1.WatchEvent{Type: "MODIFIED", Object: data}
+}
+
+var benchmarkCodecs = []string{json.CodecStd, json.CodecJsoniter, json.CodecFast}
+
+// TestBenchmarkCodecs makes sure the codecs compared by the benchmarks produce the same data.
+func TestBenchmarkCodecs(t *testing.T) {
+	for _, v := range []interface{}{newSecretList(3), newWatchEvent(), &metav1.ListOptions{LabelSelector: "app=iam"}} {
+		var expected string
+		for _, name := range benchmarkCodecs {
+			codec, _ := json.Lookup(name)
+			data, err := codec.Marshal(v, json.NewOptions())
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			if len(expected) == 0 {
+				expected = string(data)
+			} else if string(data) != expected {
+				t.Errorf("%s: expected %s, got %s", name, expected, data)
+			}
+		}
+	}
+}
+
+func benchmarkMarshal(b *testing.B, v interface{}, opts ...json.Option) {
+	for _, name := range benchmarkCodecs {
+		codec, _ := json.Lookup(name)
+		options := json.NewOptions(opts...)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.Marshal(v, options); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkUnmarshal(b *testing.B, v interface{}, newObj func() interface{}, opts ...json.Option) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, name := range benchmarkCodecs {
+		codec, _ := json.Lookup(name)
+		options := json.NewOptions(opts...)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := codec.Unmarshal(data, newObj(), options); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalObject(b *testing.B) {
+	benchmarkMarshal(b, newSecret(1))
+}
+
+func BenchmarkMarshalList(b *testing.B) {
+	benchmarkMarshal(b, newSecretList(100))
+}
+
+func BenchmarkMarshalListNoEscapeHTML(b *testing.B) {
+	benchmarkMarshal(b, newSecretList(100), json.EscapeHTML(false))
+}
+
+func BenchmarkMarshalWatchEvent(b *testing.B) {
+	benchmarkMarshal(b, newWatchEvent())
+}
+
+func BenchmarkUnmarshalObject(b *testing.B) {
+	benchmarkUnmarshal(b, newSecret(1), func() interface{} { return &secret{} })
+}
+
+func BenchmarkUnmarshalList(b *testing.B) {
+	benchmarkUnmarshal(b, newSecretList(100), func() interface{} { return &secretList{} })
+}
+
+func BenchmarkUnmarshalListStrict(b *testing.B) {
+	benchmarkUnmarshal(b, newSecretList(100), func() interface{} { return &secretList{} },
+		json.UseNumber(), json.DisallowUnknownFields())
+}
+
+func BenchmarkUnmarshalListOptions(b *testing.B) {
+	limit := int64(10)
+	benchmarkUnmarshal(b, &metav1.ListOptions{LabelSelector: "app=iam", Limit: &limit, Watch: true},
+		func() interface{} { return &metav1.ListOptions{} })
+}
@@ -0,0 +1,225 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"bytes"
+	// The token API is not provided by jsoniter, documents are canonicalized with the
+	// standard library whatever the build tags.
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// MarshalCanonical returns the canonical JSON encoding of v, as defined by the JSON
+// Canonicalization Scheme (RFC 8785), e.g. to hash or sign it. The embedded RawMessage
+// values are canonicalized too.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Canonicalize(data)
+}
+
+// Canonicalize transforms a JSON document into its canonical form, as defined by the JSON
+// Canonicalization Scheme (RFC 8785):
+//
+//   - whitespace is removed,
+//   - object members are sorted by the UTF-16 code units of their names,
+//   - numbers are formatted like ECMAScript does, as IEEE 754 double precision values,
+//   - strings are escaped minimally, i.e. only quotes, backslashes and control characters.
+//
+// Documents with duplicate object member names, or with numbers out of the range of double
+// precision values are rejected. Invalid UTF-8 is replaced by U+FFFD, like encoding/json does.
+func Canonicalize(data []byte) ([]byte, error) {
+	decoder := stdjson.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	out, err := appendCanonical(nil, decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+
+	return out, nil
+}
+
+type canonicalMember struct {
+	name  string
+	value []byte
+}
+
+func appendCanonical(dst []byte, decoder *stdjson.Decoder) ([]byte, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case stdjson.Delim:
+		if t == '[' {
+			return appendCanonicalArray(dst, decoder)
+		}
+
+		return appendCanonicalObject(dst, decoder)
+	case string:
+		return appendCanonicalString(dst, t), nil
+	case stdjson.Number:
+		number, err := formatCanonicalNumber(t)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(dst, number...), nil
+	case bool:
+		return strconv.AppendBool(dst, t), nil
+	default:
+		return append(dst, "null"...), nil
+	}
+}
+
+func appendCanonicalArray(dst []byte, decoder *stdjson.Decoder) ([]byte, error) {
+	dst = append(dst, '[')
+	for i := 0; decoder.More(); i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = appendCanonical(dst, decoder); err != nil {
+			return nil, err
+		}
+	}
+	// the closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return append(dst, ']'), nil
+}
+
+func appendCanonicalObject(dst []byte, decoder *stdjson.Decoder) ([]byte, error) {
+	var members []canonicalMember
+	names := map[string]struct{}{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("duplicate object member name %q", name)
+		}
+		names[name] = struct{}{}
+
+		value, err := appendCanonical(nil, decoder)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, canonicalMember{name: name, value: value})
+	}
+	// the closing brace
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return lessUTF16(members[i].name, members[j].name)
+	})
+
+	dst = append(dst, '{')
+	for i, member := range members {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendCanonicalString(dst, member.name)
+		dst = append(dst, ':')
+		dst = append(dst, member.value...)
+	}
+
+	return append(dst, '}'), nil
+}
+
+// lessUTF16 compares strings by their UTF-16 code units, as required by RFC 8785.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+
+	return len(ua) < len(ub)
+}
+
+// appendCanonicalString appends a string escaped as required by RFC 8785: quotes and
+// backslashes, and control characters, using their short form if they have one.
+func appendCanonicalString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case b == '"' || b == '\\':
+			dst = append(dst, '\\', b)
+		case b == '\b':
+			dst = append(dst, '\\', 'b')
+		case b == '\t':
+			dst = append(dst, '\\', 't')
+		case b == '\n':
+			dst = append(dst, '\\', 'n')
+		case b == '\f':
+			dst = append(dst, '\\', 'f')
+		case b == '\r':
+			dst = append(dst, '\\', 'r')
+		case b < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+		default:
+			dst = append(dst, b)
+		}
+	}
+
+	return append(dst, '"')
+}
+
+// formatCanonicalNumber formats a number like ECMAScript's Number.prototype.toString does
+// for double precision values.
+func formatCanonicalNumber(n stdjson.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %s: %v", n, err)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("invalid number %s", n)
+	}
+	if f == 0 {
+		// negative zero is serialized as 0
+		return "0", nil
+	}
+
+	format := byte('e')
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		format = 'f'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// ECMAScript does not pad the exponent, e.g. 1e-7 instead of 1e-07
+		if n := len(s); s[n-2] == '0' && (s[n-3] == '-' || s[n-3] == '+') {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+
+	return s, nil
+}
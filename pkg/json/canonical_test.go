@@ -0,0 +1,115 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name: "rfc 8785 example",
+			data: `{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+				`"string":"` + "\u20ac" + `$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name: "utf-16 sorting",
+			data: `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh",` +
+				`"1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`,
+			expected: `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","` + "\u00f6" +
+				`":"Latin Small Letter O With Diaeresis","` + "\u20ac" + `":"Euro Sign","` + "\U0001f600" +
+				`":"Emoji: Grinning Face","` + "\ufb33" + `":"Hebrew Letter Dalet With Dagesh"}`,
+		},
+		{
+			name:     "no html escaping",
+			data:     `"<a&b>\u2028\u001f\b\f\t"`,
+			expected: `"<a&b>` + "\u2028" + `\u001f\b\f\t"`,
+		},
+		{
+			name:     "nested",
+			data:     ` [ {"b" : [ ], "a" : { } } , "" ] `,
+			expected: `[{"a":{},"b":[]},""]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := Canonicalize([]byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		} else if string(got) != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	testCases := map[string]string{
+		"0":                       "0",
+		"-0":                      "0",
+		"1":                       "1",
+		"-1.5":                    "-1.5",
+		"1e21":                    "1e+21",
+		"1e20":                    "100000000000000000000",
+		"0.000001":                "0.000001",
+		"1e-7":                    "1e-7",
+		"9007199254740992":        "9007199254740992",
+		"9007199254740993":        "9007199254740992",
+		"295147905179352830000":   "295147905179352830000",
+		"999999999999999700000":   "999999999999999700000",
+		"5e-324":                  "5e-324",
+		"-5e-324":                 "-5e-324",
+		"1.7976931348623157e308":  "1.7976931348623157e+308",
+		"-1.7976931348623157e308": "-1.7976931348623157e+308",
+		"1.2345678901234567e-100": "1.2345678901234567e-100",
+	}
+
+	for number, expected := range testCases {
+		got, err := Canonicalize([]byte(number))
+		if err != nil || string(got) != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", number, expected, got, err)
+		}
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"a":1,"a":2}`,
+		`1e400`,
+		`{"a":1} {}`,
+		`[1,2`,
+		``,
+	} {
+		if _, err := Canonicalize([]byte(data)); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	v := struct {
+		Z      string     `json:"z"`
+		A      RawMessage `json:"a"`
+		Number float64    `json:"number"`
+	}{
+		Z:      "<z>",
+		A:      RawMessage(`{ "y": 1.0, "x": [ 1e3 ] }`),
+		Number: 1e21,
+	}
+
+	got, err := MarshalCanonical(v)
+	if expected := `{"a":{"x":[1000],"y":1},"number":1e+21,"z":"<z>"}`; err != nil || string(got) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, got, err)
+	}
+}
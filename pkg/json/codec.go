@@ -0,0 +1,182 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Options configures a single call to a Codec.
+type Options struct {
+	// UseNumber decodes numbers into interface{} values as Number instead of float64.
+	UseNumber bool
+	// DisallowUnknownFields returns an error when an object has a key which does not match
+	// any exported field of the destination struct.
+	DisallowUnknownFields bool
+	// EscapeHTML escapes <, > and & in strings, so that the JSON can be embedded in HTML.
+	EscapeHTML bool
+	// SortMapKeys writes the keys of maps in sorted order. The encoding/json backend always
+	// sorts map keys.
+	SortMapKeys bool
+}
+
+// Option sets a per call option.
+type Option func(*Options)
+
+// UseNumber decodes numbers into interface{} values as Number instead of float64.
+func UseNumber() Option {
+	return func(o *Options) {
+		o.UseNumber = true
+	}
+}
+
+// DisallowUnknownFields rejects the objects having keys which do not match any field.
+func DisallowUnknownFields() Option {
+	return func(o *Options) {
+		o.DisallowUnknownFields = true
+	}
+}
+
+// EscapeHTML sets whether <, > and & are escaped in strings. They are by default.
+func EscapeHTML(escape bool) Option {
+	return func(o *Options) {
+		o.EscapeHTML = escape
+	}
+}
+
+// SortMapKeys sets whether the keys of maps are sorted. They are by default.
+func SortMapKeys(sort bool) Option {
+	return func(o *Options) {
+		o.SortMapKeys = sort
+	}
+}
+
+// NewOptions returns the options with the defaults of encoding/json, modified by opts.
+func NewOptions(opts ...Option) Options {
+	o := Options{EscapeHTML: true, SortMapKeys: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Codec is a JSON implementation.
+type Codec interface {
+	// Marshal returns the JSON encoding of v.
+	Marshal(v interface{}, opts Options) ([]byte, error)
+	// Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v.
+	Unmarshal(data []byte, v interface{}, opts Options) error
+}
+
+// The names of the builtin codecs.
+const (
+	// CodecStd is the encoding/json codec.
+	CodecStd = "std"
+	// CodecJsoniter is the github.com/json-iterator/go codec.
+	CodecJsoniter = "jsoniter"
+	// CodecFast is the codec which encodes the types implementing AppendMarshaler without
+	// reflection, and falls back to encoding/json for the others.
+	CodecFast = "fast"
+)
+
+var (
+	codecsLock   sync.RWMutex
+	codecs       = map[string]Codec{}
+	defaultCodec Codec
+)
+
+func init() {
+	Register(CodecStd, stdCodec{})
+	Register(CodecJsoniter, jsoniterCodec{})
+	Register(CodecFast, fastCodec{fallback: stdCodec{}})
+
+	defaultCodec = codecs[defaultCodecName]
+}
+
+// Register makes a codec available by the provided name. If Register is called twice with
+// the same name, the codec passed to the later call is used.
+func Register(name string, codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+
+	codecs[name] = codec
+}
+
+// Lookup returns the codec registered with the name.
+func Lookup(name string) (Codec, bool) {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	codec, ok := codecs[name]
+
+	return codec, ok
+}
+
+// Codecs returns the sorted names of the registered codecs.
+func Codecs() []string {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Default returns the default codec, which is "jsoniter" when built with the jsoniter tag,
+// and "std" otherwise, unless it has been changed with SetDefault.
+func Default() Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	return defaultCodec
+}
+
+// SetDefault sets the default codec, used by Marshal and Unmarshal, as well as by
+// MarshalWithOptions and UnmarshalWithOptions. NewEncoder, NewDecoder and MarshalIndent keep
+// using the implementation selected at build time.
+func SetDefault(name string) error {
+	codec, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown json codec %q, expected one of %v", name, Codecs())
+	}
+
+	codecsLock.Lock()
+	defaultCodec = codec
+	codecsLock.Unlock()
+
+	return nil
+}
+
+// defaultOptions are the options of Marshal and Unmarshal, the defaults of encoding/json.
+var defaultOptions = NewOptions()
+
+// Marshal returns the JSON encoding of v with the default codec.
+func Marshal(v interface{}) ([]byte, error) {
+	return Default().Marshal(v, defaultOptions)
+}
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v
+// with the default codec.
+func Unmarshal(data []byte, v interface{}) error {
+	return Default().Unmarshal(data, v, defaultOptions)
+}
+
+// MarshalWithOptions returns the JSON encoding of v with the default codec.
+func MarshalWithOptions(v interface{}, opts ...Option) ([]byte, error) {
+	return Default().Marshal(v, NewOptions(opts...))
+}
+
+// UnmarshalWithOptions parses the JSON-encoded data and stores the result in the value pointed
+// to by v with the default codec.
+func UnmarshalWithOptions(data []byte, v interface{}, opts ...Option) error {
+	return Default().Unmarshal(data, v, NewOptions(opts...))
+}
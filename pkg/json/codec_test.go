@@ -0,0 +1,187 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	stdjson "encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type item struct {
+	Name   string         `json:"name"`
+	Labels map[string]int `json:"labels,omitempty"`
+}
+
+type appendItem struct {
+	Name string
+}
+
+func (i appendItem) AppendJSON(dst []byte, opts Options) ([]byte, error) {
+	dst = append(dst, `{"generated":`...)
+
+	return append(AppendString(dst, i.Name, opts.EscapeHTML), '}'), nil
+}
+
+func TestCodecs(t *testing.T) {
+	if names := Codecs(); !reflect.DeepEqual(names, []string{CodecFast, CodecJsoniter, CodecStd}) {
+		t.Errorf("unexpected codecs %v", names)
+	}
+
+	for _, name := range []string{CodecStd, CodecJsoniter, CodecFast} {
+		codec, _ := Lookup(name)
+
+		data, err := codec.Marshal(&item{Name: "<a&b>", Labels: map[string]int{"z": 1, "a": 2}}, NewOptions())
+		if expected := `{"name":"\u003ca\u0026b\u003e","labels":{"a":2,"z":1}}`; err != nil || string(data) != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", name, expected, data, err)
+		}
+		data, err = codec.Marshal(&item{Name: "<a&b>"}, NewOptions(EscapeHTML(false)))
+		if expected := `{"name":"<a&b>"}`; err != nil || string(data) != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", name, expected, data, err)
+		}
+
+		var v interface{}
+		if err := codec.Unmarshal([]byte(`{"a":1}`), &v, NewOptions(UseNumber())); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		} else if _, ok := v.(map[string]interface{})["a"].(interface{ Int64() (int64, error) }); !ok {
+			t.Errorf("%s: expected a number, got %#v", name, v)
+		}
+
+		data = []byte(`{"name":"foo","nmae":"bar"}`)
+		if err := codec.Unmarshal(data, &item{}, NewOptions()); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if err := codec.Unmarshal(data, &item{}, NewOptions(DisallowUnknownFields())); err == nil ||
+			!strings.Contains(err.Error(), "nmae") {
+			t.Errorf("%s: expected an unknown field error, got %v", name, err)
+		}
+		if err := codec.Unmarshal([]byte(`{} {}`), &item{}, NewOptions(UseNumber())); err == nil {
+			t.Errorf("%s: expected an error for data after the value", name)
+		}
+	}
+}
+
+func TestJsoniterSortMapKeys(t *testing.T) {
+	codec, _ := Lookup(CodecJsoniter)
+
+	m := map[string]int{}
+	for _, k := range strings.Split("abcdefghijklmnop", "") {
+		m[k] = 0
+	}
+	sorted, _ := codec.Marshal(m, NewOptions())
+	for i := 0; i < 10; i++ {
+		data, err := codec.Marshal(m, NewOptions(SortMapKeys(false)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != string(sorted) {
+			return
+		}
+	}
+	t.Errorf("expected the keys not to be sorted, got %s", sorted)
+}
+
+func TestFastCodec(t *testing.T) {
+	codec, _ := Lookup(CodecFast)
+
+	data, err := codec.Marshal(appendItem{Name: "<a>"}, NewOptions())
+	if expected := `{"generated":"\u003ca\u003e"}`; err != nil || string(data) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, data, err)
+	}
+	data, err = codec.Marshal(&appendItem{Name: "<a>"}, NewOptions(EscapeHTML(false)))
+	if expected := `{"generated":"<a>"}`; err != nil || string(data) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, data, err)
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	for _, s := range []string{
+		"", "foo", `quote " and \ backslash`, "new\nline\ttab\r\b\f", "\x00\x01\x1f\x7f",
+		"<script>&</script>", "ünïcödé 世界", "line separator ", "invalid \xff utf-8 \xc3",
+	} {
+		for _, escapeHTML := range []bool{true, false} {
+			data, err := stdCodec{}.Marshal(s, NewOptions(EscapeHTML(escapeHTML)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := AppendString(nil, s, escapeHTML); string(got) != string(data) {
+				t.Errorf("%q (escape html %v): expected %s, got %s", s, escapeHTML, data, got)
+			}
+		}
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	codec := Default()
+	defer func() {
+		codecsLock.Lock()
+		defaultCodec = codec
+		codecsLock.Unlock()
+	}()
+
+	if err := SetDefault("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown codec")
+	}
+
+	Register("test", fastCodec{fallback: stdCodec{}})
+	defer func() {
+		codecsLock.Lock()
+		delete(codecs, "test")
+		codecsLock.Unlock()
+	}()
+	if err := SetDefault("test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, marshal := range []func(interface{}) ([]byte, error){
+		Marshal,
+		func(v interface{}) ([]byte, error) { return MarshalWithOptions(v) },
+	} {
+		if data, err := marshal(appendItem{Name: "a"}); err != nil || string(data) != `{"generated":"a"}` {
+			t.Errorf("expected the default codec to be used, got %s (%v)", data, err)
+		}
+	}
+
+	var v interface{}
+	if err := UnmarshalWithOptions([]byte(`1`), &v, UseNumber()); err != nil || v != stdjson.Number("1") {
+		t.Errorf("unexpected value %#v (%v)", v, err)
+	}
+}
+
+func TestSetDefaultConcurrently(t *testing.T) {
+	codec := Default()
+	defer func() {
+		codecsLock.Lock()
+		defaultCodec = codec
+		codecsLock.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for _, name := range []string{CodecFast, CodecStd} {
+				if err := SetDefault(name); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var v map[string]string
+			data, err := Marshal(map[string]string{"a": "b"})
+			if err == nil {
+				err = Unmarshal(data, &v)
+			}
+			if err != nil || v["a"] != "b" {
+				t.Errorf("unexpected value %v (%v)", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
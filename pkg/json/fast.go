@@ -0,0 +1,93 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"unicode/utf8"
+)
+
+// AppendMarshaler is implemented by the types with generated marshaling code, which append
+// their JSON encoding to a buffer without reflection. Implementations honour the EscapeHTML
+// option, e.g. by writing their strings with AppendString.
+type AppendMarshaler interface {
+	AppendJSON(dst []byte, opts Options) ([]byte, error)
+}
+
+// fastCodec encodes the values implementing AppendMarshaler without reflection, and the
+// other values with the fallback codec.
+type fastCodec struct {
+	fallback Codec
+}
+
+func (c fastCodec) Marshal(v interface{}, opts Options) ([]byte, error) {
+	if m, ok := v.(AppendMarshaler); ok {
+		return m.AppendJSON(make([]byte, 0, 256), opts)
+	}
+
+	return c.fallback.Marshal(v, opts)
+}
+
+func (c fastCodec) Unmarshal(data []byte, v interface{}, opts Options) error {
+	return c.fallback.Unmarshal(data, v, opts)
+}
+
+const hex = "0123456789abcdef"
+
+// AppendString appends the JSON encoding of s to dst, escaped like encoding/json does:
+// invalid UTF-8 is replaced by U+FFFD, and <, > and & are escaped if escapeHTML is true.
+func AppendString(dst []byte, s string, escapeHTML bool) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && (!escapeHTML || (b != '<' && b != '>' && b != '&')) {
+				i++
+
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			// these are valid JSON but break JavaScript evaluated from the JSON
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			i += size
+
+			continue
+		}
+		i += size
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+
+	return append(dst, '"')
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// jsoniterConfigs caches the frozen jsoniter configurations by options, as freezing a
+// configuration discards the encoders and decoders it has built.
+var jsoniterConfigs sync.Map
+
+// jsoniterCodec is the github.com/json-iterator/go codec. With the default options, it is
+// compatible with encoding/json.
+type jsoniterCodec struct{}
+
+func (jsoniterCodec) api(opts Options) jsoniter.API {
+	if api, ok := jsoniterConfigs.Load(opts); ok {
+		return api.(jsoniter.API)
+	}
+
+	api, _ := jsoniterConfigs.LoadOrStore(opts, jsoniter.Config{
+		EscapeHTML:             opts.EscapeHTML,
+		SortMapKeys:            opts.SortMapKeys,
+		UseNumber:              opts.UseNumber,
+		DisallowUnknownFields:  opts.DisallowUnknownFields,
+		ValidateJsonRawMessage: true,
+	}.Froze())
+
+	return api.(jsoniter.API)
+}
+
+func (c jsoniterCodec) Marshal(v interface{}, opts Options) ([]byte, error) {
+	return c.api(opts).Marshal(v)
+}
+
+func (c jsoniterCodec) Unmarshal(data []byte, v interface{}, opts Options) error {
+	return c.api(opts).Unmarshal(data, v)
+}
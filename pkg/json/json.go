@@ -8,14 +8,15 @@ package json
 
 import "encoding/json"
 
+const defaultCodecName = CodecStd
+
 // RawMessage is exported by component-base/pkg/json package.
 type RawMessage = json.RawMessage
 
+// Number is exported by component-base/pkg/json package.
+type Number = json.Number
+
 var (
-	// Marshal is exported by component-base/pkg/json package.
-	Marshal = json.Marshal
-	// Unmarshal is exported by component-base/pkg/json package.
-	Unmarshal = json.Unmarshal
 	// MarshalIndent is exported by component-base/pkg/json package.
 	MarshalIndent = json.MarshalIndent
 	// NewDecoder is exported by component-base/pkg/json package.
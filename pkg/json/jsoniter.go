@@ -6,17 +6,24 @@
 
 package json
 
-import jsoniter "github.com/json-iterator/go"
+import (
+	stdjson "encoding/json"
 
-// RawMessage is exported by component-base/pkg/json package.
-type RawMessage = jsoniter.RawMessage
+	jsoniter "github.com/json-iterator/go"
+)
+
+const defaultCodecName = CodecJsoniter
+
+// RawMessage is exported by component-base/pkg/json package. It is the encoding/json type,
+// which is supported by jsoniter, so that the types using it can be encoded by every codec.
+type RawMessage = stdjson.RawMessage
+
+// Number is exported by component-base/pkg/json package. It is the encoding/json type,
+// which jsoniter produces when decoding numbers with UseNumber, like every codec.
+type Number = stdjson.Number
 
 var (
 	json = jsoniter.ConfigCompatibleWithStandardLibrary
-	// Marshal is exported by component-base/pkg/json package.
-	Marshal = json.Marshal
-	// Unmarshal is exported by component-base/pkg/json package.
-	Unmarshal = json.Unmarshal
 	// MarshalIndent is exported by component-base/pkgn/json package.
 	MarshalIndent = json.MarshalIndent
 	// NewDecoder is exported by component-base/pkg/json package.
@@ -0,0 +1,55 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+)
+
+// stdCodec is the encoding/json codec. It always sorts map keys.
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}, opts Options) ([]byte, error) {
+	if opts.EscapeHTML {
+		return stdjson.Marshal(v)
+	}
+
+	var buf bytes.Buffer
+	encoder := stdjson.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	// unlike Marshal, Encode terminates the value with a newline
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+func (stdCodec) Unmarshal(data []byte, v interface{}, opts Options) error {
+	if !opts.UseNumber && !opts.DisallowUnknownFields {
+		return stdjson.Unmarshal(data, v)
+	}
+
+	decoder := stdjson.NewDecoder(bytes.NewReader(data))
+	if opts.UseNumber {
+		decoder.UseNumber()
+	}
+	if opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	// unlike Unmarshal, Decode stops at the end of the first value
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// DigestAlgorithm is the prefix of the digests returned by ContentDigest.
+const DigestAlgorithm = "sha256"
+
+// ServerPopulatedFields are the fields of ObjectMeta which are populated by the system,
+// and are excluded from the content digests.
+var ServerPopulatedFields = []string{"id", "instanceID", "createdAt", "updatedAt", "resourceVersion", "managedFields"}
+
+// ContentDigest returns a digest of the content of an API object, in the form
+// "sha256:<hex>". The digest is computed on the canonical JSON encoding (RFC 8785) of the
+// object, without the server populated fields of its metadata, so that it is stable across
+// the stores, the updates which do not change the object, and the JSON backends.
+func ContentDigest(obj interface{}) (string, error) {
+	data, err := CanonicalContent(obj)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+
+	return DigestAlgorithm + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// CanonicalContent returns the canonical JSON encoding (RFC 8785) of an API object, without
+// the server populated fields of its metadata.
+func CanonicalContent(obj interface{}) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var content map[string]interface{}
+	if err := decoder.Decode(&content); err != nil {
+		return nil, fmt.Errorf("content digests are only computed for objects: %v", err)
+	}
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, field := range ServerPopulatedFields {
+			delete(metadata, field)
+		}
+	}
+
+	return json.MarshalCanonical(content)
+}
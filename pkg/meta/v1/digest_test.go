@@ -0,0 +1,63 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type policy struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+
+	Username string            `json:"username"`
+	Policy   map[string]string `json:"policy"`
+}
+
+func TestContentDigest(t *testing.T) {
+	obj := &policy{
+		TypeMeta:   TypeMeta{APIVersion: "iam.marmotedu.com/v1", Kind: "Policy"},
+		ObjectMeta: ObjectMeta{Name: "foo", Extend: Extend{"b": 1, "a": "x"}},
+		Username:   "admin",
+		Policy:     map[string]string{"effect": "allow"},
+	}
+	digest, err := ContentDigest(obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") || len(digest) != len("sha256:")+64 {
+		t.Errorf("unexpected digest %q", digest)
+	}
+
+	content, err := CanonicalContent(obj)
+	expected := `{"apiVersion":"iam.marmotedu.com/v1","kind":"Policy","metadata":{"extend":{"a":"x","b":1},"name":"foo"},` +
+		`"policy":{"effect":"allow"},"username":"admin"}`
+	if err != nil || string(content) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, content, err)
+	}
+
+	stored := *obj
+	stored.ID = 10
+	stored.InstanceID = "policy-xxxx"
+	stored.CreatedAt = time.Now()
+	stored.UpdatedAt = time.Now()
+	stored.ResourceVersion = "12"
+	stored.ManagedFields = []ManagedFieldsEntry{{Manager: "iamctl", Operation: ManagedFieldsOperationApply}}
+	if got, _ := ContentDigest(&stored); got != digest {
+		t.Errorf("expected the server populated fields to be ignored, got %q instead of %q", got, digest)
+	}
+
+	changed := *obj
+	changed.Username = "colin"
+	if got, _ := ContentDigest(&changed); got == digest {
+		t.Errorf("expected the digest to change with the content")
+	}
+
+	if _, err := ContentDigest([]string{"a"}); err == nil {
+		t.Errorf("expected an error for a non object value")
+	}
+}
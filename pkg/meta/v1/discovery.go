@@ -0,0 +1,104 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "fmt"
+
+// APIGroupList is a list of APIGroup, to allow clients to discover the API at /apis.
+type APIGroupList struct {
+	TypeMeta `json:",inline"`
+
+	// Groups is a list of APIGroup.
+	Groups []APIGroup `json:"groups"`
+}
+
+// APIGroup contains the name, the supported versions, and the preferred version
+// of a group.
+type APIGroup struct {
+	TypeMeta `json:",inline"`
+
+	// Name is the name of the group.
+	Name string `json:"name"`
+
+	// Versions are the versions supported in this group.
+	Versions []GroupVersionForDiscovery `json:"versions"`
+
+	// PreferredVersion is the version preferred by the API server, which
+	// probably is the storage version.
+	PreferredVersion GroupVersionForDiscovery `json:"preferredVersion,omitempty"`
+}
+
+// GroupVersionForDiscovery contains the "group/version" and "version" string of a version.
+// It is made a struct to keep extensibility.
+type GroupVersionForDiscovery struct {
+	// GroupVersion specifies the API group and version in the form "group/version".
+	GroupVersion string `json:"groupVersion"`
+
+	// Version specifies the version in the form of "version". This is to save
+	// the clients the trouble of splitting the GroupVersion.
+	Version string `json:"version"`
+}
+
+// APIResourceList is a list of APIResource, it is used to expose the name of the
+// resources supported in a specific group and version.
+type APIResourceList struct {
+	TypeMeta `json:",inline"`
+
+	// GroupVersion is the group and version this APIResourceList is for.
+	GroupVersion string `json:"groupVersion"`
+
+	// APIResources contains the name of the resources.
+	APIResources []APIResource `json:"resources"`
+}
+
+// APIResource specifies the name of a resource, its kind and the verbs it supports.
+type APIResource struct {
+	// Name is the plural name of the resource.
+	Name string `json:"name"`
+
+	// SingularName is the singular name of the resource. This allows clients to handle
+	// plural and singular opaquely. The singularName is more correct for reporting status
+	// on a single item and both singular and plural are allowed from the CLI interface.
+	SingularName string `json:"singularName"`
+
+	// Group is the preferred group of the resource. Empty implies the group of the
+	// containing resource list.
+	Group string `json:"group,omitempty"`
+
+	// Version is the preferred version of the resource. Empty implies the version of the
+	// containing resource list.
+	Version string `json:"version,omitempty"`
+
+	// Kind is the kind for the resource (e.g. 'Foo' is the kind for a resource 'foo')
+	Kind string `json:"kind"`
+
+	// Verbs is a list of supported verbs (this includes get, list, watch, create,
+	// update, patch, delete and deletecollection)
+	Verbs Verbs `json:"verbs"`
+
+	// ShortNames is a list of suggested short names of the resource.
+	ShortNames []string `json:"shortNames,omitempty"`
+
+	// Categories is a list of the grouped resources this resource belongs to (e.g. 'all')
+	Categories []string `json:"categories,omitempty"`
+}
+
+// Verbs is the list of the verbs supported by a resource.
+type Verbs []string
+
+// Has returns true if the verb is in the list.
+func (vs Verbs) Has(verb string) bool {
+	for _, v := range vs {
+		if v == verb {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (vs Verbs) String() string {
+	return fmt.Sprintf("%v", []string(vs))
+}
@@ -75,11 +75,17 @@ func (obj *ObjectMeta) GetObjectMeta() Object { return obj }
 
 var _ Object = &ObjectMeta{}
 
-func (meta *ObjectMeta) GetID() uint64                    { return meta.ID }
-func (meta *ObjectMeta) SetID(id uint64)                  { meta.ID = id }
-func (meta *ObjectMeta) GetName() string                  { return meta.Name }
-func (meta *ObjectMeta) SetName(name string)              { meta.Name = name }
-func (meta *ObjectMeta) GetCreatedAt() time.Time          { return meta.CreatedAt }
-func (meta *ObjectMeta) SetCreatedAt(createdAt time.Time) { meta.CreatedAt = createdAt }
-func (meta *ObjectMeta) GetUpdatedAt() time.Time          { return meta.UpdatedAt }
-func (meta *ObjectMeta) SetUpdatedAt(updatedAt time.Time) { meta.UpdatedAt = updatedAt }
+func (meta *ObjectMeta) GetID() uint64                          { return meta.ID }
+func (meta *ObjectMeta) SetID(id uint64)                        { meta.ID = id }
+func (meta *ObjectMeta) GetName() string                        { return meta.Name }
+func (meta *ObjectMeta) SetName(name string)                    { meta.Name = name }
+func (meta *ObjectMeta) GetCreatedAt() time.Time                { return meta.CreatedAt }
+func (meta *ObjectMeta) SetCreatedAt(createdAt time.Time)       { meta.CreatedAt = createdAt }
+func (meta *ObjectMeta) GetUpdatedAt() time.Time                { return meta.UpdatedAt }
+func (meta *ObjectMeta) SetUpdatedAt(updatedAt time.Time)       { meta.UpdatedAt = updatedAt }
+func (meta *ObjectMeta) GetResourceVersion() string             { return meta.ResourceVersion }
+func (meta *ObjectMeta) SetResourceVersion(version string)      { meta.ResourceVersion = version }
+func (meta *ObjectMeta) GetManagedFields() []ManagedFieldsEntry { return meta.ManagedFields }
+func (meta *ObjectMeta) SetManagedFields(managedFields []ManagedFieldsEntry) {
+	meta.ManagedFields = managedFields
+}
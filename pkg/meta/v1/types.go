@@ -11,6 +11,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// DryRunAll is the only valid value of the DryRun field of the create, update
+// and patch options. All dry run stages will be processed.
+const DryRunAll = "All"
+
 // Extend defines a new type used to store extended fields.
 type Extend map[string]interface{}
 
@@ -112,6 +116,24 @@ type ObjectMeta struct {
 	// Null for lists.
 	UpdatedAt time.Time `json:"updatedAt,omitempty" gorm:"column:updatedAt"`
 
+	// ResourceVersion is an opaque value that represents the internal version of this object
+	// that can be used by clients to determine when objects have changed and to resume a watch.
+	// It will not be stored in db.
+	//
+	// Populated by the system.
+	// Read-only.
+	ResourceVersion string `json:"resourceVersion,omitempty" gorm:"-"`
+
+	// ManagedFields maps workflow-id and version to the set of fields
+	// that are managed by that workflow. This is mostly for internal
+	// housekeeping, and users typically shouldn't need to set or
+	// understand this field. A workflow can be the user's name, a
+	// controller's name, or the name of a specific apply path like
+	// "ci-cd". The set of fields is always in the version that the
+	// workflow used when modifying the object.
+	// It will not be stored in db.
+	ManagedFields []ManagedFieldsEntry `json:"managedFields,omitempty" gorm:"-"`
+
 	// DeletedAt is RFC 3339 date and time at which this resource will be deleted. This
 	// field is set by the server when a graceful deletion is requested by the user, and is not
 	// directly settable by a client.
@@ -144,6 +166,38 @@ func (obj *ObjectMeta) AfterFind(tx *gorm.DB) error {
 	return nil
 }
 
+// ManagedFieldsOperationType is the type of operation which lead to a ManagedFieldsEntry being created.
+type ManagedFieldsOperationType string
+
+const (
+	// ManagedFieldsOperationApply is the operation of an Apply request.
+	ManagedFieldsOperationApply ManagedFieldsOperationType = "Apply"
+	// ManagedFieldsOperationUpdate is the operation of any other modification.
+	ManagedFieldsOperationUpdate ManagedFieldsOperationType = "Update"
+)
+
+// ManagedFieldsEntry is a workflow-id, a set of field paths and the group version of the
+// resource that the field set applies to.
+type ManagedFieldsEntry struct {
+	// Manager is an identifier of the workflow managing these fields.
+	Manager string `json:"manager,omitempty"`
+
+	// Operation is the type of operation which lead to this ManagedFieldsEntry being created.
+	// The only valid values for this field are 'Apply' and 'Update'.
+	Operation ManagedFieldsOperationType `json:"operation,omitempty"`
+
+	// APIVersion defines the version of this resource that this field set
+	// applies to.
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Time is the timestamp of when the managed fields were last changed.
+	Time time.Time `json:"time,omitempty"`
+
+	// Fields contains the paths of the fields owned by the manager, e.g. "spec.statements[read].effect".
+	// List items merged by key are identified by the value of their merge key.
+	Fields []string `json:"fields,omitempty"`
+}
+
 // ListOptions is the query options to a standard REST list call.
 type ListOptions struct {
 	TypeMeta `json:",inline"`
@@ -162,6 +216,18 @@ type ListOptions struct {
 
 	// Limit specify the number of records to be retrieved.
 	Limit *int64 `json:"limit,omitempty" form:"limit"`
+
+	// Watch for changes to the described resources and return them as a stream of
+	// add, update, and remove notifications.
+	Watch bool `json:"watch,omitempty" form:"watch"`
+
+	// AllowWatchBookmarks requests watch events with type "BOOKMARK".
+	// Servers that do not implement bookmarks may ignore this flag.
+	AllowWatchBookmarks bool `json:"allowWatchBookmarks,omitempty" form:"allowWatchBookmarks"`
+
+	// ResourceVersion is used to resume a watch from the given version. Only events
+	// that happened after this version are sent. An empty value starts from the current state.
+	ResourceVersion string `json:"resourceVersion,omitempty" form:"resourceVersion"`
 }
 
 // ExportOptions is the query options to the standard REST get call.
@@ -201,6 +267,12 @@ type CreateOptions struct {
 	// - All: all dry run stages will be processed
 	// +optional
 	DryRun []string `json:"dryRun,omitempty"`
+
+	// FieldManager is a name associated with the actor or entity
+	// that is making these changes. The value must be less than or
+	// 128 characters long, and only contain printable characters.
+	// +optional
+	FieldManager string `json:"fieldManager,omitempty"`
 }
 
 // PatchOptions may be provided when patching an API object.
@@ -221,6 +293,13 @@ type PatchOptions struct {
 	// flag must be unset for non-apply patch requests.
 	// +optional
 	Force bool `json:"force,omitempty"`
+
+	// FieldManager is a name associated with the actor or entity
+	// that is making these changes. The value must be less than or
+	// 128 characters long, and only contain printable characters.
+	// It is required for apply requests.
+	// +optional
+	FieldManager string `json:"fieldManager,omitempty"`
 }
 
 // UpdateOptions may be provided when updating an API object.
@@ -235,6 +314,12 @@ type UpdateOptions struct {
 	// - All: all dry run stages will be processed
 	// +optional
 	DryRun []string `json:"dryRun,omitempty"`
+
+	// FieldManager is a name associated with the actor or entity
+	// that is making these changes. The value must be less than or
+	// 128 characters long, and only contain printable characters.
+	// +optional
+	FieldManager string `json:"fieldManager,omitempty"`
 }
 
 // AuthorizeOptions may be provided when authorize an API object.
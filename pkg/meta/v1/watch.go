@@ -0,0 +1,39 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "github.com/marmotedu/component-base/pkg/json"
+
+// WatchEvent represents a single event to a watched resource.
+// It is the serialized form of a watch.Event sent over the wire.
+type WatchEvent struct {
+	// Type is one of ADDED, MODIFIED, DELETED, BOOKMARK or ERROR.
+	Type string `json:"type"`
+
+	// Object is:
+	//  * If Type is ADDED or MODIFIED: the new state of the object.
+	//  * If Type is DELETED: the state of the object immediately before deletion.
+	//  * If Type is BOOKMARK: an object of the watched kind with only ResourceVersion set.
+	//  * If Type is ERROR: an object with a "message" field describing the failure.
+	Object json.RawMessage `json:"object"`
+}
+
+var _ json.AppendMarshaler = WatchEvent{}
+
+// AppendJSON implements json.AppendMarshaler, so that the events of watches, which are
+// encoded one by one, are encoded without reflection by the fast json codec. The object
+// is written as is.
+func (e WatchEvent) AppendJSON(dst []byte, opts json.Options) ([]byte, error) {
+	dst = append(dst, `{"type":`...)
+	dst = json.AppendString(dst, e.Type, opts.EscapeHTML)
+	dst = append(dst, `,"object":`...)
+	if len(e.Object) == 0 {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, e.Object...)
+	}
+
+	return append(dst, '}'), nil
+}
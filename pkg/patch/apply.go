@@ -0,0 +1,257 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// ApplyPatchType is the patch type of server-side apply requests. The patch is a partial
+// object holding the fields the field manager wants to own, in JSON.
+const ApplyPatchType PatchType = "application/apply-patch+json"
+
+// ManagedFieldsAccessor is implemented by objects which record their field managers,
+// such as the objects embedding metav1.ObjectMeta.
+type ManagedFieldsAccessor interface {
+	GetManagedFields() []metav1.ManagedFieldsEntry
+	SetManagedFields(managedFields []metav1.ManagedFieldsEntry)
+}
+
+// ServerSideApply merges the applied configuration into obj, which must be a pointer to
+// a struct implementing ManagedFieldsAccessor, and returns the result as a new value of
+// the same type.
+//
+// The fields set in the applied configuration become owned by opts.FieldManager. Applying
+// a field owned by another manager with a different value is a conflict, reported as an
+// Invalid error naming the owner, unless opts.Force is set, in which case the field is
+// taken over. Fields applied by the manager before and left out of the applied configuration
+// are removed from the object, unless they are also owned by another manager.
+//
+// obj is updated with the result unless the options request a dry run.
+func ServerSideApply(obj interface{}, applied []byte, opts metav1.PatchOptions) (interface{}, field.ErrorList) {
+	if errs := ValidatePatchOptions(opts, ApplyPatchType); len(errs) > 0 {
+		return nil, errs
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), obj, "must be a non-nil pointer")}
+	}
+	accessor, ok := obj.(ManagedFieldsAccessor)
+	if !ok {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), obj, "must have managed fields")}
+	}
+
+	original, errs := decodeObject(obj)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	config, err := decode(applied)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath("patch"), string(applied), err.Error())}
+	}
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, field.ErrorList{field.Invalid(field.NewPath("patch"), string(applied), "must be an object")}
+	}
+	if metadata, ok := configMap["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+
+	t := reflect.TypeOf(obj)
+	appliedValues := fieldValues(deepCopy(configMap), t)
+	currentValues := fieldValues(deepCopy(original), t)
+	appliedFields := NewFieldSet()
+	for _, fv := range appliedValues {
+		appliedFields.Insert(fv.path)
+	}
+
+	managed := accessor.GetManagedFields()
+	entries := make([]metav1.ManagedFieldsEntry, 0, len(managed)+1)
+	var previous FieldSet
+	others := NewFieldSet()
+	allErrs := field.ErrorList{}
+	for _, entry := range managed {
+		owned := NewFieldSetFromList(entry.Fields)
+		if entry.Manager == opts.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			previous = owned
+
+			continue
+		}
+
+		for _, p := range owned.Intersection(appliedFields).List() {
+			current, exists := currentValues[p]
+			if !exists || equal(current.value, appliedValues[p].value) {
+				continue
+			}
+			if !opts.Force {
+				allErrs = append(allErrs, field.Invalid(appliedValues[p].path, appliedValues[p].value,
+					fmt.Sprintf("conflict with %q", entry.Manager)))
+
+				continue
+			}
+			owned.set.Delete(p)
+		}
+		entry.Fields = owned.List()
+		entries = append(entries, entry)
+		others = others.Union(owned)
+	}
+	if len(allErrs) > 0 {
+		return nil, allErrs
+	}
+
+	merged, errs := StrategicMergePatchValue(original, configMap, obj)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if previous.set != nil {
+		merged = removeFields(merged, t, previous.Difference(appliedFields).Difference(others))
+		if merged == nil {
+			merged = map[string]interface{}{}
+		}
+	}
+
+	entries = append(entries, metav1.ManagedFieldsEntry{
+		Manager:    opts.FieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: apiVersionOf(merged),
+		Time:       time.Now(),
+		Fields:     appliedFields.List(),
+	})
+
+	result, errs := encodeObject(merged, v.Elem().Type())
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	result.(ManagedFieldsAccessor).SetManagedFields(pruneManagedFields(entries))
+
+	if !IsDryRun(opts.DryRun) {
+		v.Elem().Set(reflect.ValueOf(result).Elem())
+	}
+
+	return result, nil
+}
+
+// UpdateManagedFields records the fields changed from oldObj to newObj as owned by the
+// manager with an Update operation, and removes them from the other managers. Both objects
+// must be pointers to structs of the same type implementing ManagedFieldsAccessor; the
+// managed fields of newObj are set from the ones of oldObj.
+func UpdateManagedFields(oldObj, newObj interface{}, manager string) field.ErrorList {
+	oldAccessor, ok := oldObj.(ManagedFieldsAccessor)
+	if !ok {
+		return field.ErrorList{field.Invalid(field.NewPath(""), oldObj, "must have managed fields")}
+	}
+	newAccessor, ok := newObj.(ManagedFieldsAccessor)
+	if !ok {
+		return field.ErrorList{field.Invalid(field.NewPath(""), newObj, "must have managed fields")}
+	}
+
+	oldValue, errs := decodeObject(oldObj)
+	if len(errs) > 0 {
+		return errs
+	}
+	newValue, errs := decodeObject(newObj)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	t := reflect.TypeOf(newObj)
+	oldValues := fieldValues(oldValue, t)
+	newValues := fieldValues(newValue, t)
+	current := NewFieldSet()
+	changed := NewFieldSet()
+	for p, fv := range newValues {
+		current.Insert(fv.path)
+		if old, exists := oldValues[p]; !exists || !equal(old.value, fv.value) {
+			changed.Insert(fv.path)
+		}
+	}
+
+	managed := oldAccessor.GetManagedFields()
+	entries := make([]metav1.ManagedFieldsEntry, 0, len(managed)+1)
+	owned := NewFieldSet()
+	var last metav1.ManagedFieldsEntry
+	for _, entry := range managed {
+		fields := NewFieldSetFromList(entry.Fields).Intersection(current)
+		if entry.Manager == manager && entry.Operation == metav1.ManagedFieldsOperationUpdate {
+			owned = fields
+			last = entry
+
+			continue
+		}
+		entry.Fields = fields.Difference(changed).List()
+		entries = append(entries, entry)
+	}
+
+	if changed.Len() > 0 {
+		last = metav1.ManagedFieldsEntry{
+			Manager:    manager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: apiVersionOf(newValue),
+			Time:       time.Now(),
+		}
+	}
+	last.Fields = owned.Union(changed).List()
+	entries = append(entries, last)
+	newAccessor.SetManagedFields(pruneManagedFields(entries))
+
+	return nil
+}
+
+// pruneManagedFields removes the entries which do not own any field.
+func pruneManagedFields(entries []metav1.ManagedFieldsEntry) []metav1.ManagedFieldsEntry {
+	pruned := make([]metav1.ManagedFieldsEntry, 0, len(entries))
+	for _, entry := range entries {
+		if len(entry.Fields) > 0 {
+			pruned = append(pruned, entry)
+		}
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	return pruned
+}
+
+func apiVersionOf(obj interface{}) string {
+	m, _ := obj.(map[string]interface{})
+	apiVersion, _ := m["apiVersion"].(string)
+
+	return apiVersion
+}
+
+// decodeObject returns the JSON representation of obj as a decoded JSON value.
+func decodeObject(obj interface{}) (interface{}, field.ErrorList) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath(""), err)}
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath(""), err)}
+	}
+
+	return v, nil
+}
+
+// encodeObject converts a decoded JSON value to a new pointer to a value of type t.
+func encodeObject(v interface{}, t reflect.Type) (interface{}, field.ErrorList) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath(""), err)}
+	}
+	result := reflect.New(t)
+	if err := json.Unmarshal(data, result.Interface()); err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), string(data), err.Error())}
+	}
+
+	return result.Interface(), nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+func managedFieldsOf(obj *policy) map[string][]string {
+	fields := map[string][]string{}
+	for _, entry := range obj.ManagedFields {
+		fields[entry.Manager+"/"+string(entry.Operation)] = entry.Fields
+	}
+
+	return fields
+}
+
+func TestFieldsOf(t *testing.T) {
+	obj, err := decode([]byte(`{"metadata":{"name":"foo","managedFields":[{"manager":"a"}]},` +
+		`"spec":{"statements":[{"name":"read","resources":["x","y"]}],"subjects":["a","b"]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"metadata.name",
+		"spec.statements[read].name",
+		"spec.statements[read].resources[x]",
+		"spec.statements[read].resources[y]",
+		"spec.subjects",
+	}
+	if got := FieldsOf(obj, &policy{}).List(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected fields %v, got %v", expected, got)
+	}
+}
+
+func TestServerSideApply(t *testing.T) {
+	obj := &policy{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+
+	applied := []byte(`{"metadata":{"name":"foo"},"spec":{"statements":[{"name":"read","effect":"allow"}]}}`)
+	if _, errs := ServerSideApply(obj, applied, metav1.PatchOptions{FieldManager: "alice"}); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	expected := map[string][]string{
+		"alice/Apply": {"metadata.name", "spec.statements[read].effect", "spec.statements[read].name"},
+	}
+	if got := managedFieldsOf(obj); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected managed fields %v, got %v", expected, got)
+	}
+
+	// bob shares the statement name with alice and adds a statement of its own.
+	applied = []byte(`{"spec":{"statements":[{"name":"read"},{"name":"write","effect":"deny"}]}}`)
+	if _, errs := ServerSideApply(obj, applied, metav1.PatchOptions{FieldManager: "bob"}); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if statements := obj.Spec.Statements; len(statements) != 2 || statements[0].Effect != "allow" {
+		t.Errorf("expected the statements to be merged, got %v", statements)
+	}
+
+	// changing a field owned by alice is a conflict.
+	applied = []byte(`{"spec":{"statements":[{"name":"read","effect":"deny"}]}}`)
+	_, errs := ServerSideApply(obj, applied, metav1.PatchOptions{FieldManager: "bob"})
+	if len(errs) != 1 || errs[0].Type != field.ErrorTypeInvalid || errs[0].Field != "spec.statements[read].effect" ||
+		errs[0].Detail != `conflict with "alice"` {
+		t.Fatalf("expected a conflict with alice, got %v", errs)
+	}
+
+	// unless the change is forced; bob's write statement is dropped from the applied configuration.
+	if _, errs := ServerSideApply(obj, applied, metav1.PatchOptions{FieldManager: "bob", Force: true}); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if statements := obj.Spec.Statements; len(statements) != 1 || statements[0].Effect != "deny" {
+		t.Errorf("expected the forced statement to be applied and the other one removed, got %v", statements)
+	}
+	expected = map[string][]string{
+		"alice/Apply": {"metadata.name", "spec.statements[read].name"},
+		"bob/Apply":   {"spec.statements[read].effect", "spec.statements[read].name"},
+	}
+	if got := managedFieldsOf(obj); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected managed fields %v, got %v", expected, got)
+	}
+
+	// fields no longer applied by bob stay in place while alice owns them.
+	applied = []byte(`{"spec":{"subjects":["carol"]}}`)
+	if _, errs := ServerSideApply(obj, applied, metav1.PatchOptions{FieldManager: "bob"}); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if statements := obj.Spec.Statements; len(statements) != 1 || statements[0].Name != "read" || statements[0].Effect != "" {
+		t.Errorf("expected only the fields owned by alice to be left, got %v", statements)
+	}
+}
+
+func TestServerSideApplyOptions(t *testing.T) {
+	obj := &policy{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	applied := []byte(`{"spec":{"subjects":["a"]}}`)
+
+	_, errs := ApplyToObject(obj, applied, ApplyPatchType, metav1.PatchOptions{Force: true})
+	if len(errs) != 1 || errs[0].Type != field.ErrorTypeRequired || errs[0].Field != "fieldManager" {
+		t.Errorf("expected the field manager to be required, got %v", errs)
+	}
+
+	result, errs := ApplyToObject(obj, applied, ApplyPatchType, metav1.PatchOptions{
+		FieldManager: "alice",
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if subjects := result.(*policy).Spec.Subjects; !reflect.DeepEqual(subjects, []string{"a"}) {
+		t.Errorf("expected the configuration to be applied, got %v", subjects)
+	}
+	if obj.Spec.Subjects != nil || obj.ManagedFields != nil {
+		t.Errorf("expected dry run to leave the object untouched, got %v", obj)
+	}
+}
+
+func TestUpdateManagedFields(t *testing.T) {
+	obj := &policy{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	applied := []byte(`{"spec":{"statements":[{"name":"read","effect":"allow"}],"subjects":["a"]}}`)
+	if _, errs := ServerSideApply(obj, applied, metav1.PatchOptions{FieldManager: "alice"}); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	patch := []byte(`{"spec":{"statements":[{"name":"read","effect":"deny"}]}}`)
+	opts := metav1.PatchOptions{FieldManager: "editor"}
+	if _, errs := ApplyToObject(obj, patch, StrategicMergePatchType, opts); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expected := map[string][]string{
+		"alice/Apply":   {"spec.statements[read].name", "spec.subjects"},
+		"editor/Update": {"spec.statements[read].effect"},
+	}
+	if got := managedFieldsOf(obj); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected managed fields %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package patch implements RFC 6902 JSON Patch, RFC 7386 JSON Merge Patch and a
+// strategic merge patch which merges lists by the keys declared on struct tags.
+//
+// Patches operate on JSON bytes or on decoded JSON values (map[string]interface{},
+// []interface{} and scalars), which is also what jsonutil.Json.Interface() returns.
+// Failures are reported as a field.ErrorList whose paths point into the patched document.
+package patch // import "github.com/marmotedu/component-base/pkg/patch"
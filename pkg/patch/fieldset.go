@@ -0,0 +1,198 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/marmotedu/component-base/pkg/util/sets"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// FieldSet is a set of field paths, identified by their string representation.
+//
+// Items of the lists merged by key are identified by the value of their merge key,
+// e.g. "spec.statements[read].effect", and values of the primitive lists merged as
+// a set by the value itself, e.g. "spec.resources[users]".
+type FieldSet struct {
+	set sets.String
+}
+
+// NewFieldSet creates a FieldSet from the given paths.
+func NewFieldSet(paths ...*field.Path) FieldSet {
+	s := FieldSet{set: sets.NewString()}
+	for _, p := range paths {
+		s.Insert(p)
+	}
+
+	return s
+}
+
+// NewFieldSetFromList creates a FieldSet from the string representation of the paths,
+// as returned by List.
+func NewFieldSetFromList(paths []string) FieldSet {
+	return FieldSet{set: sets.NewString(paths...)}
+}
+
+// Insert adds the path to the set.
+func (s FieldSet) Insert(p *field.Path) {
+	s.set.Insert(p.String())
+}
+
+// Has returns true if the path is contained in the set.
+func (s FieldSet) Has(p *field.Path) bool {
+	return s.set.Has(p.String())
+}
+
+// Len returns the size of the set.
+func (s FieldSet) Len() int {
+	return s.set.Len()
+}
+
+// List returns the sorted string representation of the paths in the set.
+func (s FieldSet) List() []string {
+	return s.set.List()
+}
+
+// Union returns a new set which includes the paths in either s or other.
+func (s FieldSet) Union(other FieldSet) FieldSet {
+	return FieldSet{set: s.set.Union(other.set)}
+}
+
+// Intersection returns a new set which includes the paths in both s and other.
+func (s FieldSet) Intersection(other FieldSet) FieldSet {
+	return FieldSet{set: s.set.Intersection(other.set)}
+}
+
+// Difference returns a new set of the paths in s which are not in other.
+func (s FieldSet) Difference(other FieldSet) FieldSet {
+	return FieldSet{set: s.set.Difference(other.set)}
+}
+
+// FieldsOf returns the set of the leaf fields of a decoded JSON object. dataStruct is a value
+// of the Go type the object decodes into, and is used to identify the lists merged by key.
+func FieldsOf(obj interface{}, dataStruct interface{}) FieldSet {
+	s := NewFieldSet()
+	visitFields(obj, typeOf(dataStruct), nil, func(p *field.Path, _ interface{}) bool {
+		s.Insert(p)
+
+		return true
+	})
+
+	return s
+}
+
+// fieldValue is the value of a leaf field and its path.
+type fieldValue struct {
+	path  *field.Path
+	value interface{}
+}
+
+// fieldValues returns the values of the leaf fields of a decoded JSON object, keyed by path.
+func fieldValues(obj interface{}, t reflect.Type) map[string]fieldValue {
+	values := map[string]fieldValue{}
+	visitFields(obj, t, nil, func(p *field.Path, v interface{}) bool {
+		values[p.String()] = fieldValue{path: p, value: v}
+
+		return true
+	})
+
+	return values
+}
+
+// removeFields removes the fields contained in s from a decoded JSON object. Objects and
+// list items left empty by the removal are removed as well.
+func removeFields(obj interface{}, t reflect.Type, s FieldSet) interface{} {
+	return visitFields(obj, t, nil, func(p *field.Path, _ interface{}) bool {
+		return !s.Has(p)
+	})
+}
+
+func typeOf(v interface{}) reflect.Type {
+	if v == nil {
+		return nil
+	}
+
+	return reflect.TypeOf(v)
+}
+
+// visitFieldFunc is called for every leaf field; the field is removed when it returns false.
+type visitFieldFunc func(p *field.Path, v interface{}) (keep bool)
+
+// visitFields calls fn with every leaf field of v, and returns v without the fields
+// for which fn returned false. Scalars, empty objects and lists which are not merged
+// are leaf fields.
+func visitFields(v interface{}, t reflect.Type, fldPath *field.Path, fn visitFieldFunc) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		if fldPath == nil || fn(fldPath, v) {
+			return v
+		}
+
+		return nil
+	}
+
+	for _, k := range sortedKeys(m) {
+		meta := lookupPatchMeta(t, k)
+		childPath := fldPath.Child(k)
+		if childPath.String() == "metadata.managedFields" {
+			continue
+		}
+
+		var child interface{}
+		list, isList := m[k].([]interface{})
+		if isList && hasStrategy(meta.strategy, mergeDirective) && len(list) > 0 {
+			child = visitList(list, meta, childPath, fn)
+		} else {
+			child = visitFields(m[k], meta.t, childPath, fn)
+		}
+		if child == nil && m[k] != nil {
+			delete(m, k)
+
+			continue
+		}
+		m[k] = child
+	}
+	if len(m) == 0 {
+		return nil
+	}
+
+	return m
+}
+
+func visitList(list []interface{}, meta patchMeta, fldPath *field.Path, fn visitFieldFunc) interface{} {
+	var elemType reflect.Type
+	if t := indirect(meta.t); t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elemType = t.Elem()
+	}
+
+	kept := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if meta.mergeKey == "" {
+			if fn(fldPath.Key(fmt.Sprint(item)), item) {
+				kept = append(kept, item)
+			}
+
+			continue
+		}
+
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			kept = append(kept, item)
+
+			continue
+		}
+		itemPath := fldPath.Key(fmt.Sprint(m[meta.mergeKey]))
+		if result := visitFields(m, elemType, itemPath, fn); result != nil {
+			kept = append(kept, result)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return kept
+}
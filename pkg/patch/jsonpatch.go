@@ -0,0 +1,351 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// The RFC 6902 operations.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is an RFC 6902 JSON Patch document.
+type JSONPatch []Operation
+
+// DecodeJSONPatch decodes an RFC 6902 JSON Patch document.
+func DecodeJSONPatch(data []byte) (JSONPatch, error) {
+	var p JSONPatch
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Apply applies the patch to the JSON document and returns the patched document.
+// The patch is atomic: if any operation fails, none of them is applied.
+func (p JSONPatch) Apply(doc []byte) ([]byte, field.ErrorList) {
+	v, err := decode(doc)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), string(doc), err.Error())}
+	}
+
+	patched, errs := p.ApplyValue(v)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	data, err := json.Marshal(patched)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath(""), err)}
+	}
+
+	return data, nil
+}
+
+// ApplyValue applies the patch to a decoded JSON value and returns the patched value.
+// doc is not modified.
+func (p JSONPatch) ApplyValue(doc interface{}) (interface{}, field.ErrorList) {
+	doc = deepCopy(doc)
+	for i, op := range p {
+		var err *field.Error
+		doc, err = op.apply(doc)
+		if err != nil {
+			detail := fmt.Sprintf("operation %d (%s %s)", i, op.Op, op.Path)
+			if err.Detail != "" {
+				detail += ": " + err.Detail
+			}
+			err.Detail = detail
+
+			return nil, field.ErrorList{err}
+		}
+	}
+
+	return doc, nil
+}
+
+func (op Operation) value() (interface{}, *field.Error) {
+	if len(op.Value) == 0 {
+		return nil, field.Required(field.NewPath("value"), fmt.Sprintf("%q operation requires a value", op.Op))
+	}
+	v, err := decode(op.Value)
+	if err != nil {
+		return nil, field.Invalid(field.NewPath("value"), string(op.Value), err.Error())
+	}
+
+	return v, nil
+}
+
+func (op Operation) apply(doc interface{}) (interface{}, *field.Error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, field.Invalid(field.NewPath("path"), op.Path, err.Error())
+	}
+
+	switch op.Op {
+	case OpAdd:
+		value, ferr := op.value()
+		if ferr != nil {
+			return nil, ferr
+		}
+
+		return add(doc, path, value)
+	case OpRemove:
+		doc, _, ferr := remove(doc, path)
+
+		return doc, ferr
+	case OpReplace:
+		value, ferr := op.value()
+		if ferr != nil {
+			return nil, ferr
+		}
+
+		return replace(doc, path, value)
+	case OpMove, OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, field.Invalid(field.NewPath("from"), op.From, err.Error())
+		}
+		if op.Op == OpMove {
+			if op.From == op.Path {
+				// moving a value to its own location is a no-op, once the location exists
+				if _, ferr := get(doc, from); ferr != nil {
+					return nil, ferr
+				}
+
+				return doc, nil
+			}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, field.Invalid(field.NewPath("from"), op.From, "may not be a proper prefix of path")
+			}
+			doc, value, ferr := remove(doc, from)
+			if ferr != nil {
+				return nil, ferr
+			}
+
+			return add(doc, path, value)
+		}
+		value, ferr := get(doc, from)
+		if ferr != nil {
+			return nil, ferr
+		}
+
+		return add(doc, path, deepCopy(value))
+	case OpTest:
+		expected, ferr := op.value()
+		if ferr != nil {
+			return nil, ferr
+		}
+		actual, ferr := get(doc, path)
+		if ferr != nil {
+			return nil, ferr
+		}
+		if !equal(actual, expected) {
+			return nil, field.Invalid(pathOf(doc, path), actual, fmt.Sprintf("test failed, expected %s", op.Value))
+		}
+
+		return doc, nil
+	default:
+		return nil, field.NotSupported(
+			field.NewPath("op"),
+			op.Op,
+			[]string{OpAdd, OpRemove, OpReplace, OpMove, OpCopy, OpTest},
+		)
+	}
+}
+
+// containerFunc operates on the container (object or array) holding the
+// element referenced by key, and returns the updated container.
+type containerFunc func(container interface{}, key string, fldPath *field.Path) (interface{}, *field.Error)
+
+// walk calls fn with the container which holds the last token of path, and returns
+// the updated document.
+func walk(doc interface{}, path []string, fldPath *field.Path, fn containerFunc) (interface{}, *field.Error) {
+	if len(path) == 1 {
+		return fn(doc, path[0], fldPath)
+	}
+
+	switch t := doc.(type) {
+	case map[string]interface{}:
+		child, ok := t[path[0]]
+		if !ok {
+			return nil, field.NotFound(fldPath.Child(path[0]), path[0])
+		}
+		updated, err := walk(child, path[1:], fldPath.Child(path[0]), fn)
+		if err != nil {
+			return nil, err
+		}
+		t[path[0]] = updated
+
+		return t, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(t), false, fldPath)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := walk(t[i], path[1:], fldPath.Index(i), fn)
+		if err != nil {
+			return nil, err
+		}
+		t[i] = updated
+
+		return t, nil
+	default:
+		return nil, field.Invalid(fldPath, doc, "cannot traverse a scalar value")
+	}
+}
+
+// pathOf converts the tokens of a JSON pointer into a field path, looking at the
+// document to tell array indices from object keys.
+func pathOf(doc interface{}, path []string) *field.Path {
+	var fldPath *field.Path
+	for _, token := range path {
+		switch t := doc.(type) {
+		case []interface{}:
+			i, err := arrayIndex(token, len(t), false, fldPath)
+			if err != nil {
+				return fldPath.Key(token)
+			}
+			fldPath, doc = fldPath.Index(i), t[i]
+		case map[string]interface{}:
+			fldPath, doc = fldPath.Child(token), t[token]
+		default:
+			return fldPath.Child(token)
+		}
+	}
+
+	return fldPath
+}
+
+func get(doc interface{}, path []string) (interface{}, *field.Error) {
+	var fldPath *field.Path
+	for _, token := range path {
+		switch t := doc.(type) {
+		case map[string]interface{}:
+			child, ok := t[token]
+			if !ok {
+				return nil, field.NotFound(fldPath.Child(token), token)
+			}
+			fldPath, doc = fldPath.Child(token), child
+		case []interface{}:
+			i, err := arrayIndex(token, len(t), false, fldPath)
+			if err != nil {
+				return nil, err
+			}
+			fldPath, doc = fldPath.Index(i), t[i]
+		default:
+			return nil, field.Invalid(fldPath, doc, "cannot traverse a scalar value")
+		}
+	}
+
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, *field.Error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return walk(doc, path, nil, func(container interface{}, key string, fldPath *field.Path) (interface{}, *field.Error) {
+		switch t := container.(type) {
+		case map[string]interface{}:
+			t[key] = value
+
+			return t, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(t), true, fldPath)
+			if err != nil {
+				return nil, err
+			}
+			t = append(t, nil)
+			copy(t[i+1:], t[i:])
+			t[i] = value
+
+			return t, nil
+		default:
+			return nil, field.Invalid(fldPath, container, "cannot add a member to a scalar value")
+		}
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, interface{}, *field.Error) {
+	if len(path) == 0 {
+		return nil, nil, field.Forbidden(field.NewPath(""), "the root of the document cannot be removed")
+	}
+
+	var removed interface{}
+	doc, err := walk(doc, path, nil, func(container interface{}, key string, fldPath *field.Path) (interface{}, *field.Error) {
+		switch t := container.(type) {
+		case map[string]interface{}:
+			v, ok := t[key]
+			if !ok {
+				return nil, field.NotFound(fldPath.Child(key), key)
+			}
+			removed = v
+			delete(t, key)
+
+			return t, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(t), false, fldPath)
+			if err != nil {
+				return nil, err
+			}
+			removed = t[i]
+
+			return append(t[:i], t[i+1:]...), nil
+		default:
+			return nil, field.Invalid(fldPath, container, "cannot remove a member of a scalar value")
+		}
+	})
+
+	return doc, removed, err
+}
+
+func replace(doc interface{}, path []string, value interface{}) (interface{}, *field.Error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return walk(doc, path, nil, func(container interface{}, key string, fldPath *field.Path) (interface{}, *field.Error) {
+		switch t := container.(type) {
+		case map[string]interface{}:
+			if _, ok := t[key]; !ok {
+				return nil, field.NotFound(fldPath.Child(key), key)
+			}
+			t[key] = value
+
+			return t, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(t), false, fldPath)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = value
+
+			return t, nil
+		default:
+			return nil, field.Invalid(fldPath, container, "cannot replace a member of a scalar value")
+		}
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+func assertJSONEqual(t *testing.T, expected string, actual []byte) {
+	t.Helper()
+	e, err := decode([]byte(expected))
+	if err != nil {
+		t.Fatalf("invalid expected document %s: %v", expected, err)
+	}
+	a, err := decode(actual)
+	if err != nil {
+		t.Fatalf("invalid actual document %s: %v", actual, err)
+	}
+	if !equal(e, a) {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "add object member",
+			doc:      `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux"}]`,
+			expected: `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:     "add array element",
+			doc:      `{"foo":["bar","baz"]}`,
+			patch:    `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			expected: `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:     "append array element",
+			doc:      `{"foo":["bar"]}`,
+			patch:    `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			expected: `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:     "remove object member",
+			doc:      `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"remove","path":"/baz"}]`,
+			expected: `{"foo":"bar"}`,
+		},
+		{
+			name:     "remove array element",
+			doc:      `{"foo":["bar","qux","baz"]}`,
+			patch:    `[{"op":"remove","path":"/foo/1"}]`,
+			expected: `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:     "replace value",
+			doc:      `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			expected: `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:     "move value",
+			doc:      `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:    `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:     "move array element",
+			doc:      `{"foo":["all","grass","cows","eat"]}`,
+			patch:    `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			expected: `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:     "move value to itself",
+			doc:      `{"foo":{"bar":1}}`,
+			patch:    `[{"op":"move","from":"/foo/bar","path":"/foo/bar"}]`,
+			expected: `{"foo":{"bar":1}}`,
+		},
+		{
+			name:     "copy value",
+			doc:      `{"foo":{"bar":1}}`,
+			patch:    `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`,
+			expected: `{"foo":{"bar":1},"baz":{"bar":2}}`,
+		},
+		{
+			name:     "test succeeds",
+			doc:      `{"baz":"qux","foo":["a",2,"c"],"n":1}`,
+			patch:    `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2},{"op":"test","path":"/n","value":1.0}]`,
+			expected: `{"baz":"qux","foo":["a",2,"c"],"n":1}`,
+		},
+		{
+			name:     "escaped pointer",
+			doc:      `{"a/b":{"m~n":1}}`,
+			patch:    `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`,
+			expected: `{"a/b":{"m~n":2}}`,
+		},
+		{
+			name:     "replace root",
+			doc:      `{"foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"","value":{"baz":"qux"}}]`,
+			expected: `{"baz":"qux"}`,
+		},
+		{
+			name:     "keep big numbers",
+			doc:      `{"id":18446744073709551615}`,
+			patch:    `[{"op":"add","path":"/name","value":"foo"}]`,
+			expected: `{"id":18446744073709551615,"name":"foo"}`,
+		},
+		{
+			name:     "test big integers",
+			doc:      `{"id":9007199254740993,"n":2}`,
+			patch:    `[{"op":"test","path":"/id","value":9007199254740993},{"op":"test","path":"/n","value":2e0}]`,
+			expected: `{"id":9007199254740993,"n":2}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := DecodeJSONPatch([]byte(tc.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			patched, errs := p.Apply([]byte(tc.doc))
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			assertJSONEqual(t, tc.expected, patched)
+		})
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		doc       string
+		patch     string
+		errorType field.ErrorType
+		field     string
+	}{
+		{
+			name:      "test fails",
+			doc:       `{"spec":{"replicas":[1,2]}}`,
+			patch:     `[{"op":"test","path":"/spec/replicas/1","value":3}]`,
+			errorType: field.ErrorTypeInvalid,
+			field:     "spec.replicas[1]",
+		},
+		{
+			name:      "test fails on big integers",
+			doc:       `{"id":9007199254740993}`,
+			patch:     `[{"op":"test","path":"/id","value":9007199254740992}]`,
+			errorType: field.ErrorTypeInvalid,
+			field:     "id",
+		},
+		{
+			name:      "remove missing member",
+			doc:       `{"spec":{}}`,
+			patch:     `[{"op":"remove","path":"/spec/name"}]`,
+			errorType: field.ErrorTypeNotFound,
+			field:     "spec.name",
+		},
+		{
+			name:      "index out of bounds",
+			doc:       `{"foo":["bar"]}`,
+			patch:     `[{"op":"add","path":"/foo/2","value":"baz"}]`,
+			errorType: field.ErrorTypeInvalid,
+			field:     "foo[2]",
+		},
+		{
+			name:      "unknown operation",
+			doc:       `{}`,
+			patch:     `[{"op":"merge","path":"/foo"}]`,
+			errorType: field.ErrorTypeNotSupported,
+			field:     "op",
+		},
+		{
+			name:      "missing value",
+			doc:       `{}`,
+			patch:     `[{"op":"add","path":"/foo"}]`,
+			errorType: field.ErrorTypeRequired,
+			field:     "value",
+		},
+		{
+			name:      "move missing member to itself",
+			doc:       `{"foo":{}}`,
+			patch:     `[{"op":"move","from":"/foo/bar","path":"/foo/bar"}]`,
+			errorType: field.ErrorTypeNotFound,
+			field:     "foo.bar",
+		},
+		{
+			name:      "move into own child",
+			doc:       `{"foo":{"bar":{}}}`,
+			patch:     `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			errorType: field.ErrorTypeInvalid,
+			field:     "from",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := DecodeJSONPatch([]byte(tc.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, errs := p.Apply([]byte(tc.doc))
+			if len(errs) != 1 {
+				t.Fatalf("expected one error, got %v", errs)
+			}
+			if errs[0].Type != tc.errorType || errs[0].Field != tc.field {
+				t.Errorf("expected %s error on %q, got %v", tc.errorType, tc.field, errs[0])
+			}
+		})
+	}
+}
+
+func TestJSONPatchIsAtomic(t *testing.T) {
+	doc := map[string]interface{}{"foo": "bar"}
+	p := JSONPatch{
+		{Op: OpAdd, Path: "/baz", Value: []byte(`"qux"`)},
+		{Op: OpTest, Path: "/foo", Value: []byte(`"other"`)},
+	}
+	if _, errs := p.ApplyValue(doc); len(errs) != 1 {
+		t.Fatalf("expected the test operation to fail, got %v", errs)
+	}
+	if _, ok := doc["baz"]; ok {
+		t.Errorf("expected the original document to be left untouched, got %v", doc)
+	}
+}
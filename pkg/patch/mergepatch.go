@@ -0,0 +1,120 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to the original JSON document.
+func MergePatch(original, patch []byte) ([]byte, field.ErrorList) {
+	return applyBytes(original, patch, func(o, p interface{}) (interface{}, field.ErrorList) {
+		return MergePatchValue(o, p), nil
+	})
+}
+
+// MergePatchValue applies an RFC 7386 JSON Merge Patch to a decoded JSON value and returns
+// the patched value. original is not modified.
+func MergePatchValue(original, patch interface{}) interface{} {
+	return mergeValue(deepCopy(original), patch)
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepCopy(patch)
+	}
+
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		targetMap = map[string]interface{}{}
+	}
+	for k, v := range patchMap {
+		if v == nil {
+			delete(targetMap, k)
+
+			continue
+		}
+		targetMap[k] = mergeValue(targetMap[k], v)
+	}
+
+	return targetMap
+}
+
+// CreateMergePatch returns the RFC 7386 JSON Merge Patch which turns the original
+// JSON document into the modified one.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	o, err := decode(original)
+	if err != nil {
+		return nil, err
+	}
+	m, err := decode(modified)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(diffValue(o, m))
+}
+
+func diffValue(original, modified interface{}) interface{} {
+	originalMap, ok := original.(map[string]interface{})
+	if !ok {
+		return modified
+	}
+	modifiedMap, ok := modified.(map[string]interface{})
+	if !ok {
+		return modified
+	}
+
+	patch := map[string]interface{}{}
+	for k, o := range originalMap {
+		m, ok := modifiedMap[k]
+		switch {
+		case !ok:
+			patch[k] = nil
+		case !equal(o, m):
+			if _, isMap := m.(map[string]interface{}); isMap {
+				patch[k] = diffValue(o, m)
+			} else {
+				patch[k] = m
+			}
+		}
+	}
+	for k, m := range modifiedMap {
+		if _, ok := originalMap[k]; !ok {
+			patch[k] = m
+		}
+	}
+
+	return patch
+}
+
+// applyBytes decodes original and patch, applies fn and encodes the result.
+func applyBytes(
+	original, patch []byte,
+	fn func(original, patch interface{}) (interface{}, field.ErrorList),
+) ([]byte, field.ErrorList) {
+	o, err := decode(original)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), string(original), err.Error())}
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath("patch"), string(patch), err.Error())}
+	}
+
+	patched, errs := fn(o, p)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	data, err := json.Marshal(patched)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath(""), err)}
+	}
+
+	return data, nil
+}
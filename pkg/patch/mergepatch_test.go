@@ -0,0 +1,67 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"testing"
+)
+
+// Test cases from RFC 7386 Appendix A.
+func TestMergePatch(t *testing.T) {
+	testCases := []struct {
+		original string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tc := range testCases {
+		patched, errs := MergePatch([]byte(tc.original), []byte(tc.patch))
+		if len(errs) > 0 {
+			t.Errorf("%s + %s: unexpected errors: %v", tc.original, tc.patch, errs)
+
+			continue
+		}
+		assertJSONEqual(t, tc.expected, patched)
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	testCases := []struct {
+		original string
+		modified string
+	}{
+		{`{"a":"b","c":{"d":"e","f":"g"}}`, `{"a":"z","c":{"d":"e"}}`},
+		{`{"a":[1,2]}`, `{"a":[2]}`},
+		{`{"a":"b"}`, `{"b":{"c":1}}`},
+	}
+
+	for _, tc := range testCases {
+		patch, err := CreateMergePatch([]byte(tc.original), []byte(tc.modified))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		patched, errs := MergePatch([]byte(tc.original), patch)
+		if len(errs) > 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		assertJSONEqual(t, tc.modified, patched)
+	}
+}
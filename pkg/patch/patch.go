@@ -0,0 +1,141 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"reflect"
+	"unicode"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+const maxFieldManagerLength = 128
+
+// Apply applies a patch of the given type to the original JSON document and returns
+// the patched document. dataStruct is only used by strategic merge patches, to look
+// up the list merge keys, and may be nil for other patch types.
+func Apply(original, patch []byte, pt PatchType, dataStruct interface{}) ([]byte, field.ErrorList) {
+	switch pt {
+	case JSONPatchType:
+		p, err := DecodeJSONPatch(patch)
+		if err != nil {
+			return nil, field.ErrorList{field.Invalid(field.NewPath("patch"), string(patch), err.Error())}
+		}
+
+		return p.Apply(original)
+	case MergePatchType:
+		return MergePatch(original, patch)
+	case StrategicMergePatchType:
+		return StrategicMergePatch(original, patch, dataStruct)
+	default:
+		return nil, field.ErrorList{field.NotSupported(field.NewPath("patchType"), pt, supportedPatchTypes())}
+	}
+}
+
+func supportedPatchTypes() []string {
+	return []string{string(JSONPatchType), string(MergePatchType), string(StrategicMergePatchType)}
+}
+
+// ApplyToObject applies a patch of the given type to obj, which must be a pointer to
+// a struct, and returns the patched object as a new value of the same type.
+//
+// Apply patches are handled by ServerSideApply. For the other patch types, the fields
+// changed by the patch are recorded as owned by opts.FieldManager when it is set.
+//
+// obj is updated with the patched object unless the options request a dry run.
+// Fields which are not serialized to JSON are reset in the patched object.
+func ApplyToObject(obj interface{}, patch []byte, pt PatchType, opts metav1.PatchOptions) (interface{}, field.ErrorList) {
+	if pt == ApplyPatchType {
+		return ServerSideApply(obj, patch, opts)
+	}
+
+	if errs := ValidatePatchOptions(opts, pt); len(errs) > 0 {
+		return nil, errs
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), obj, "must be a non-nil pointer")}
+	}
+
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath(""), err)}
+	}
+
+	patched, errs := Apply(original, patch, pt, obj)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	result := reflect.New(v.Elem().Type())
+	if err := json.Unmarshal(patched, result.Interface()); err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), string(patched), err.Error())}
+	}
+
+	if opts.FieldManager != "" {
+		if errs := UpdateManagedFields(obj, result.Interface(), opts.FieldManager); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+
+	if !IsDryRun(opts.DryRun) {
+		v.Elem().Set(result.Elem())
+	}
+
+	return result.Interface(), nil
+}
+
+// IsDryRun returns true if the DryRun field of an options struct requests a dry run.
+func IsDryRun(dryRun []string) bool {
+	return len(dryRun) > 0
+}
+
+// ValidatePatchOptions validates the options of a patch of the given type.
+func ValidatePatchOptions(opts metav1.PatchOptions, pt PatchType) field.ErrorList {
+	allErrs := ValidateDryRun(field.NewPath("dryRun"), opts.DryRun)
+	allErrs = append(allErrs, ValidateFieldManager(field.NewPath("fieldManager"), opts.FieldManager)...)
+	if pt == ApplyPatchType {
+		if opts.FieldManager == "" {
+			allErrs = append(allErrs, field.Required(field.NewPath("fieldManager"), "is required for apply patch"))
+		}
+	} else if opts.Force {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("force"), "may not be specified for non-apply patch"))
+	}
+
+	return allErrs
+}
+
+// ValidateFieldManager validates that the fieldManager field is at most 128 characters long
+// and only contains printable characters.
+func ValidateFieldManager(fldPath *field.Path, fieldManager string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(fieldManager) > maxFieldManagerLength {
+		allErrs = append(allErrs, field.TooLong(fldPath, fieldManager, maxFieldManagerLength))
+	}
+	for _, r := range fieldManager {
+		if !unicode.IsPrint(r) {
+			allErrs = append(allErrs, field.Invalid(fldPath, fieldManager, "must only contain printable characters"))
+
+			break
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateDryRun validates that the dryRun field only contains supported values.
+func ValidateDryRun(fldPath *field.Path, dryRun []string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, v := range dryRun {
+		if v != metav1.DryRunAll {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), v, []string{metav1.DryRunAll}))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,395 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// StrategicMergePatch applies a strategic merge patch to the original JSON document.
+//
+// It behaves like a JSON Merge Patch, except for lists of the struct fields tagged
+// with `patchStrategy:"merge"`: lists of objects are merged item by item, matching
+// the items on the field named by the `patchMergeKey` tag, and lists of primitives
+// are merged as a set. dataStruct is a value of the Go type the document decodes into,
+// and is used to look up these tags.
+//
+// The patch may contain the following directives:
+//   - "$patch": "replace" in an object replaces the object instead of merging it.
+//   - "$patch": "delete" in an object or a list item deletes it.
+//   - "$deleteFromPrimitiveList/<field>": [...] removes values from a merged primitive list.
+func StrategicMergePatch(original, patch []byte, dataStruct interface{}) ([]byte, field.ErrorList) {
+	return applyBytes(original, patch, func(o, p interface{}) (interface{}, field.ErrorList) {
+		return StrategicMergePatchValue(o, p, dataStruct)
+	})
+}
+
+// StrategicMergePatchValue applies a strategic merge patch to a decoded JSON value and
+// returns the patched value. original is not modified.
+func StrategicMergePatchValue(original, patch, dataStruct interface{}) (interface{}, field.ErrorList) {
+	var t reflect.Type
+	if dataStruct != nil {
+		t = reflect.TypeOf(dataStruct)
+	}
+
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return nil, field.ErrorList{field.Invalid(field.NewPath(""), patch, "a strategic merge patch must be an object")}
+	}
+	originalMap, _ := original.(map[string]interface{})
+
+	merged, errs := mergeMap(deepCopy(originalMap).(map[string]interface{}), patchMap, t, nil)
+	if merged == nil && len(errs) == 0 {
+		// the whole object has been deleted
+		return map[string]interface{}{}, nil
+	}
+
+	return merged, errs
+}
+
+// patchMeta describes how a field of the document is merged.
+type patchMeta struct {
+	t        reflect.Type
+	strategy string
+	mergeKey string
+}
+
+// lookupPatchMeta returns the patch meta of the member key of an object of type t.
+func lookupPatchMeta(t reflect.Type, key string) patchMeta {
+	t = indirect(t)
+	if t == nil {
+		return patchMeta{}
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return patchMeta{t: t.Elem()}
+	case reflect.Struct:
+		if sf, ok := lookupJSONField(t, key); ok {
+			return patchMeta{
+				t:        sf.Type,
+				strategy: sf.Tag.Get("patchStrategy"),
+				mergeKey: sf.Tag.Get("patchMergeKey"),
+			}
+		}
+	}
+
+	return patchMeta{}
+}
+
+// lookupJSONField finds the struct field serialized under the given JSON name,
+// descending into embedded and inlined structs.
+func lookupJSONField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		jsonName := strings.Split(tag, ",")[0]
+		if jsonName == "" && (sf.Anonymous || strings.Contains(tag, ",inline")) {
+			if ft := indirect(sf.Type); ft.Kind() == reflect.Struct {
+				if found, ok := lookupJSONField(ft, name); ok {
+					return found, true
+				}
+
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = sf.Name
+		}
+		if jsonName == name {
+			return sf, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func hasStrategy(strategy, s string) bool {
+	for _, part := range strings.Split(strategy, ",") {
+		if part == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeMap merges patch into original, which is modified. A nil map is returned when
+// the patch deletes the object.
+func mergeMap(
+	original, patch map[string]interface{},
+	t reflect.Type,
+	fldPath *field.Path,
+) (map[string]interface{}, field.ErrorList) {
+	if directive, ok := patch[directiveMarker]; ok {
+		switch directive {
+		case replaceDirective:
+			// the directives nested in the replacement are applied to nothing
+			replaced := make(map[string]interface{}, len(patch))
+			for k, v := range patch {
+				if k != directiveMarker {
+					replaced[k] = v
+				}
+			}
+
+			return mergeMap(nil, replaced, t, fldPath)
+		case deleteDirective:
+			return nil, nil
+		case mergeDirective:
+		default:
+			return original, field.ErrorList{field.NotSupported(
+				fldPath.Child(directiveMarker),
+				directive,
+				[]string{replaceDirective, deleteDirective, mergeDirective},
+			)}
+		}
+	}
+	if original == nil {
+		original = map[string]interface{}{}
+	}
+
+	var allErrs field.ErrorList
+	// values are deleted from primitive lists before these lists are merged
+	keys := sortedKeys(patch)
+	for _, k := range keys {
+		pv := patch[k]
+		if !strings.HasPrefix(k, deleteFromPrimitiveListDirectivePrefix) {
+			continue
+		}
+		listKey := strings.TrimPrefix(k, deleteFromPrimitiveListDirectivePrefix)
+		remaining, err := deleteFromPrimitiveList(original[listKey], pv, fldPath.Child(k))
+		if err != nil {
+			allErrs = append(allErrs, err)
+
+			continue
+		}
+		// the directive does not create the list when there is none
+		if _, ok := original[listKey].([]interface{}); ok {
+			original[listKey] = remaining
+		}
+	}
+
+	for _, k := range keys {
+		pv := patch[k]
+		if k == directiveMarker || strings.HasPrefix(k, deleteFromPrimitiveListDirectivePrefix) {
+			continue
+		}
+		if strings.HasPrefix(k, "$") {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child(k), k, []string{
+				directiveMarker,
+				deleteFromPrimitiveListDirectivePrefix + "<field>",
+			}))
+
+			continue
+		}
+
+		if pv == nil {
+			delete(original, k)
+
+			continue
+		}
+
+		meta := lookupPatchMeta(t, k)
+		switch pt := pv.(type) {
+		case map[string]interface{}:
+			om, _ := original[k].(map[string]interface{})
+			merged, errs := mergeMap(om, pt, meta.t, fldPath.Child(k))
+			allErrs = append(allErrs, errs...)
+			if merged == nil {
+				delete(original, k)
+			} else {
+				original[k] = merged
+			}
+		case []interface{}:
+			ol, isList := original[k].([]interface{})
+			if ov, ok := original[k]; !ok || ov == nil {
+				// a missing list is merged as an empty list, so that the delete directives drop
+				// their items
+				ol, isList = []interface{}{}, true
+			}
+			if !isList || !hasStrategy(meta.strategy, mergeDirective) {
+				original[k] = stripDirectives(deepCopy(pt))
+
+				continue
+			}
+			merged, errs := mergeList(ol, pt, meta, fldPath.Child(k))
+			allErrs = append(allErrs, errs...)
+			original[k] = merged
+		default:
+			original[k] = pv
+		}
+	}
+
+	return original, allErrs
+}
+
+// mergeList merges the patch list into the original list, according to the patch meta.
+func mergeList(original, patch []interface{}, meta patchMeta, fldPath *field.Path) ([]interface{}, field.ErrorList) {
+	var elemType reflect.Type
+	if t := indirect(meta.t); t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elemType = t.Elem()
+	}
+
+	if meta.mergeKey == "" {
+		// a list of primitives is merged as a set, keeping the original order
+		merged := original
+		for _, pv := range patch {
+			if indexOf(merged, pv) < 0 {
+				merged = append(merged, pv)
+			}
+		}
+
+		return merged, nil
+	}
+
+	var allErrs field.ErrorList
+	merged := original
+	seen := make([]interface{}, 0, len(patch))
+	for i, pv := range patch {
+		itemPath := fldPath.Index(i)
+		item, ok := pv.(map[string]interface{})
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(itemPath, pv, "must be an object to be merged by key "+meta.mergeKey))
+
+			continue
+		}
+		key, ok := item[meta.mergeKey]
+		if !ok {
+			allErrs = append(allErrs, field.Required(itemPath.Child(meta.mergeKey), "merge key of the list item"))
+
+			continue
+		}
+		if indexOf(seen, key) >= 0 {
+			allErrs = append(allErrs, field.Duplicate(itemPath.Child(meta.mergeKey), key))
+
+			continue
+		}
+		seen = append(seen, key)
+
+		pos := -1
+		for j, ov := range merged {
+			if om, ok := ov.(map[string]interface{}); ok && equal(om[meta.mergeKey], key) {
+				pos = j
+
+				break
+			}
+		}
+
+		switch item[directiveMarker] {
+		case deleteDirective:
+			if pos >= 0 {
+				merged = append(merged[:pos], merged[pos+1:]...)
+			}
+
+			continue
+		case replaceDirective:
+			replaced, errs := mergeMap(nil, item, elemType, itemPath)
+			allErrs = append(allErrs, errs...)
+			if pos >= 0 {
+				merged[pos] = replaced
+			} else {
+				merged = append(merged, replaced)
+			}
+
+			continue
+		}
+
+		if pos < 0 {
+			// the directives of a new item are applied to an empty item
+			added, errs := mergeMap(nil, item, elemType, itemPath)
+			allErrs = append(allErrs, errs...)
+			if added != nil {
+				merged = append(merged, added)
+			}
+
+			continue
+		}
+		om, _ := merged[pos].(map[string]interface{})
+		result, errs := mergeMap(om, item, elemType, itemPath)
+		allErrs = append(allErrs, errs...)
+		merged[pos] = result
+	}
+
+	return merged, allErrs
+}
+
+func deleteFromPrimitiveList(original, values interface{}, fldPath *field.Path) (interface{}, *field.Error) {
+	toDelete, ok := values.([]interface{})
+	if !ok {
+		return original, field.Invalid(fldPath, values, "must be a list of the values to delete")
+	}
+	list, ok := original.([]interface{})
+	if !ok {
+		return original, nil
+	}
+
+	remaining := make([]interface{}, 0, len(list))
+	for _, v := range list {
+		if indexOf(toDelete, v) < 0 {
+			remaining = append(remaining, v)
+		}
+	}
+
+	return remaining, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func indexOf(list []interface{}, v interface{}) int {
+	for i, e := range list {
+		if equal(e, v) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripDirectives removes the patch directives from a value which is added as is.
+func stripDirectives(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			if strings.HasPrefix(k, "$") {
+				delete(t, k)
+
+				continue
+			}
+			t[k] = stripDirectives(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = stripDirectives(e)
+		}
+	}
+
+	return v
+}
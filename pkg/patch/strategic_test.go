@@ -0,0 +1,171 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+type statement struct {
+	Name      string                            `json:"name"`
+	Effect    string                            `json:"effect,omitempty"`
+	Resources []string                          `json:"resources,omitempty" patchStrategy:"merge"`
+	Condition map[string]map[string]interface{} `json:"condition,omitempty"`
+}
+
+type policySpec struct {
+	Statements []statement `json:"statements,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	Subjects   []string    `json:"subjects,omitempty"`
+}
+
+type policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec policySpec `json:"spec"`
+}
+
+func TestStrategicMergePatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		original string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "merge list items by key",
+			original: `{"spec":{"statements":[{"name":"a","effect":"allow"},{"name":"b","effect":"deny"}]}}`,
+			patch:    `{"spec":{"statements":[{"name":"b","effect":"allow"},{"name":"c","effect":"deny"}]}}`,
+			expected: `{"spec":{"statements":[{"name":"a","effect":"allow"},{"name":"b","effect":"allow"},{"name":"c","effect":"deny"}]}}`,
+		},
+		{
+			name:     "delete list item",
+			original: `{"spec":{"statements":[{"name":"a"},{"name":"b"}]}}`,
+			patch:    `{"spec":{"statements":[{"name":"a","$patch":"delete"}]}}`,
+			expected: `{"spec":{"statements":[{"name":"b"}]}}`,
+		},
+		{
+			name:     "replace list item",
+			original: `{"spec":{"statements":[{"name":"a","effect":"allow","resources":["x"]}]}}`,
+			patch:    `{"spec":{"statements":[{"name":"a","effect":"deny","$patch":"replace"}]}}`,
+			expected: `{"spec":{"statements":[{"name":"a","effect":"deny"}]}}`,
+		},
+		{
+			name:     "merge primitive list as a set",
+			original: `{"spec":{"statements":[{"name":"a","resources":["x","y"]}]}}`,
+			patch:    `{"spec":{"statements":[{"name":"a","resources":["y","z"],"$deleteFromPrimitiveList/resources":["x"]}]}}`,
+			expected: `{"spec":{"statements":[{"name":"a","resources":["y","z"]}]}}`,
+		},
+		{
+			name:     "delete list item from a missing list",
+			original: `{"spec":{}}`,
+			patch:    `{"spec":{"statements":[{"name":"a","$patch":"delete"},{"name":"b","effect":"allow"}]}}`,
+			expected: `{"spec":{"statements":[{"name":"b","effect":"allow"}]}}`,
+		},
+		{
+			name:     "merge list items into a missing list",
+			original: `{}`,
+			patch:    `{"spec":{"statements":[{"name":"a","resources":["x"],"$deleteFromPrimitiveList/resources":["y"]}]}}`,
+			expected: `{"spec":{"statements":[{"name":"a","resources":["x"]}]}}`,
+		},
+		{
+			name:     "delete from a missing primitive list",
+			original: `{}`,
+			patch:    `{"$deleteFromPrimitiveList/finalizers":["a"]}`,
+			expected: `{}`,
+		},
+		{
+			name:     "apply the nested directives of a new list item",
+			original: `{"spec":{"statements":[{"name":"a"}]}}`,
+			patch: `{"spec":{"statements":[{"name":"b","condition":{"ip":{"$patch":"delete"},` +
+				`"user":{"$patch":"replace","name":"colin","group":null}}}]}}`,
+			expected: `{"spec":{"statements":[{"name":"a"},{"name":"b","condition":{"user":{"name":"colin"}}}]}}`,
+		},
+		{
+			name:     "replace lists without a merge strategy",
+			original: `{"spec":{"subjects":["a","b"]}}`,
+			patch:    `{"spec":{"subjects":["c"]}}`,
+			expected: `{"spec":{"subjects":["c"]}}`,
+		},
+		{
+			name:     "replace object",
+			original: `{"metadata":{"name":"foo","extend":{"a":"b"}},"spec":{"subjects":["a"]}}`,
+			patch:    `{"spec":{"$patch":"replace","statements":[{"name":"a"}]}}`,
+			expected: `{"metadata":{"name":"foo","extend":{"a":"b"}},"spec":{"statements":[{"name":"a"}]}}`,
+		},
+		{
+			name:     "delete member",
+			original: `{"metadata":{"name":"foo","extend":{"a":"b"}}}`,
+			patch:    `{"metadata":{"extend":null}}`,
+			expected: `{"metadata":{"name":"foo"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			patched, errs := StrategicMergePatch([]byte(tc.original), []byte(tc.patch), &policy{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			assertJSONEqual(t, tc.expected, patched)
+		})
+	}
+}
+
+func TestStrategicMergePatchErrors(t *testing.T) {
+	original := `{"spec":{"statements":[{"name":"a"}]}}`
+	patch := `{"spec":{"statements":[{"effect":"deny"},{"name":"b"},{"name":"b"},` +
+		`{"name":"c","condition":{"ip":{"$patch":"remove"}}}],"$retainKeys":["statements"]}}`
+
+	_, errs := StrategicMergePatch([]byte(original), []byte(patch), &policy{})
+	var got []string
+	for _, err := range errs {
+		got = append(got, string(err.Type)+" "+err.Field)
+	}
+	expected := []string{
+		string(field.ErrorTypeNotSupported) + " spec.$retainKeys",
+		string(field.ErrorTypeRequired) + " spec.statements[0].name",
+		string(field.ErrorTypeDuplicate) + " spec.statements[2].name",
+		string(field.ErrorTypeNotSupported) + " spec.statements[3].condition.ip.$patch",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected errors %v, got %v", expected, got)
+	}
+}
+
+func TestApplyToObject(t *testing.T) {
+	obj := &policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+		Spec:       policySpec{Statements: []statement{{Name: "a", Effect: "allow"}}},
+	}
+	patch := []byte(`{"spec":{"statements":[{"name":"b","effect":"deny"}]}}`)
+
+	result, errs := ApplyToObject(obj, patch, StrategicMergePatchType, metav1.PatchOptions{DryRun: []string{"All"}})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if statements := result.(*policy).Spec.Statements; len(statements) != 2 {
+		t.Errorf("expected the patched object to have 2 statements, got %v", statements)
+	}
+	if len(obj.Spec.Statements) != 1 {
+		t.Errorf("expected dry run to leave the object untouched, got %v", obj.Spec.Statements)
+	}
+
+	if _, errs := ApplyToObject(obj, patch, MergePatchType, metav1.PatchOptions{}); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if statements := obj.Spec.Statements; len(statements) != 1 || statements[0].Name != "b" {
+		t.Errorf("expected a merge patch to replace the list, got %v", statements)
+	}
+
+	_, errs = ApplyToObject(obj, patch, MergePatchType, metav1.PatchOptions{DryRun: []string{"Some"}, Force: true})
+	if len(errs) != 2 || errs[0].Field != "dryRun[0]" || errs[1].Field != "force" {
+		t.Errorf("expected invalid options to be rejected, got %v", errs)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+// PatchType identifies the format of a patch. Its values are the media types used
+// as Content-Type of PATCH requests.
+type PatchType string
+
+// These are the supported patch types.
+const (
+	JSONPatchType           PatchType = "application/json-patch+json"
+	MergePatchType          PatchType = "application/merge-patch+json"
+	StrategicMergePatchType PatchType = "application/strategic-merge-patch+json"
+)
+
+// directiveMarker is the key of the map used to hold strategic merge patch directives.
+const directiveMarker = "$patch"
+
+// The strategic merge patch directives.
+const (
+	replaceDirective = "replace"
+	deleteDirective  = "delete"
+	mergeDirective   = "merge"
+)
+
+// deleteFromPrimitiveListDirectivePrefix prefixes the key holding the values to remove
+// from a primitive list merged by a strategic merge patch.
+const deleteFromPrimitiveListDirectivePrefix = "$deleteFromPrimitiveList/"
@@ -0,0 +1,189 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package patch
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// decode decodes a JSON document into a generic value, keeping numbers as json.Number
+// so that they survive a round trip without losing precision.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the top-level JSON value")
+	}
+
+	return v, nil
+}
+
+// deepCopy copies a decoded JSON value.
+func deepCopy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			out[k] = deepCopy(e)
+		}
+
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, e := range t {
+			out[i] = deepCopy(e)
+		}
+
+		return out
+	default:
+		return v
+	}
+}
+
+// equal reports whether two decoded JSON values are equal. Numbers are compared by
+// value, so that 1, 1.0 and 1e0 are considered equal, see equalNumbers.
+func equal(a, b interface{}) bool {
+	switch ta := a.(type) {
+	case map[string]interface{}:
+		tb, ok := b.(map[string]interface{})
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for k, va := range ta {
+			vb, ok := tb[k]
+			if !ok || !equal(va, vb) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		tb, ok := b.([]interface{})
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for i := range ta {
+			if !equal(ta[i], tb[i]) {
+				return false
+			}
+		}
+
+		return true
+	case json.Number, float64, int, int64:
+		return equalNumbers(a, b)
+	default:
+		return a == b
+	}
+}
+
+// equalNumbers compares two numbers. Integers are compared exactly, so that large values
+// which only differ beyond the precision of a float64 are not equal; the other numbers
+// are compared as float64.
+func equalNumbers(a, b interface{}) bool {
+	ia, okA := toInt(a)
+	ib, okB := toInt(b)
+	if okA && okB {
+		return ia.Cmp(ib) == 0
+	}
+
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+
+	return okA && okB && fa == fb
+}
+
+// toInt returns the value of an integer number. Numbers with a fraction or an exponent,
+// e.g. 1.0 or 1e0, are not integers.
+func toInt(v interface{}) (*big.Int, bool) {
+	switch t := v.(type) {
+	case json.Number:
+		return new(big.Int).SetString(string(t), 10)
+	case float64:
+		if math.IsInf(t, 0) || math.IsNaN(t) || t != math.Trunc(t) {
+			return nil, false
+		}
+		i, _ := big.NewFloat(t).Int(nil)
+
+		return i, true
+	case int:
+		return big.NewInt(int64(t)), true
+	case int64:
+		return big.NewInt(t), true
+	default:
+		return nil, false
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case json.Number:
+		f, err := t.Float64()
+
+		return f, err == nil
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	default:
+		return 0, false
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON pointer must be empty or start with a \"/\"")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token. When appendable is true, "-" and
+// len(array) are accepted and refer to the position after the last element.
+func arrayIndex(token string, length int, appendable bool, fldPath *field.Path) (int, *field.Error) {
+	if token == "-" && appendable {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, field.Invalid(fldPath.Key(token), token, "must be a valid array index")
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, field.Invalid(fldPath.Key(token), token, "must be a valid array index")
+	}
+
+	upper := length - 1
+	if appendable {
+		upper = length
+	}
+	if i > upper {
+		return 0, field.Invalid(fldPath.Index(i), i, fmt.Sprintf("index out of bounds, the array has %d elements", length))
+	}
+
+	return i, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	cborHandle    = newCBORHandle()
+	msgpackHandle = newMsgpackHandle()
+)
+
+func newCBORHandle() *codec.CborHandle {
+	h := &codec.CborHandle{}
+	h.Canonical = true
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+
+	return h
+}
+
+func newMsgpackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.Canonical = true
+	h.WriteExt = true
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+
+	return h
+}
+
+// codecSerializer encodes objects with an ugorji codec. Objects are converted to and from
+// JSON, so the json struct tags and the JSON marshalers of the objects apply.
+type codecSerializer struct {
+	handle codec.Handle
+}
+
+// NewCBORSerializer returns a Serializer encoding objects in CBOR (RFC 7049).
+func NewCBORSerializer() Serializer {
+	return codecSerializer{handle: cborHandle}
+}
+
+// NewMsgpackSerializer returns a Serializer encoding objects in MessagePack.
+func NewMsgpackSerializer() Serializer {
+	return codecSerializer{handle: msgpackHandle}
+}
+
+func (s codecSerializer) Encode(v interface{}) ([]byte, error) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if err := codec.NewEncoderBytes(&data, s.handle).Encode(generic); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s codecSerializer) Decode(data []byte, v interface{}) error {
+	var generic interface{}
+	if err := codec.NewDecoderBytes(data, s.handle).Decode(&generic); err != nil {
+		return err
+	}
+
+	return fromGeneric(generic, v)
+}
+
+type protobufSerializer struct{}
+
+// NewProtobufSerializer returns a Serializer encoding objects in protobuf. Objects must
+// implement proto.Message.
+func NewProtobufSerializer() Serializer {
+	return protobufSerializer{}
+}
+
+func (protobufSerializer) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("object %T does not implement the protobuf marshalling interface", v)
+	}
+
+	return proto.Marshal(m)
+}
+
+func (protobufSerializer) Decode(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("object %T does not implement the protobuf marshalling interface", v)
+	}
+
+	return proto.Unmarshal(data, m)
+}
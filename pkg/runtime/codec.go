@@ -0,0 +1,320 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/marmotedu/component-base/pkg/conversion"
+	"github.com/marmotedu/component-base/pkg/defaulting"
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/scheme"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// Codec is a JSON Encoder and Decoder which uses a Scheme to set the type information
+// of the objects it encodes, and to pick the Go type of the objects it decodes.
+type Codec struct {
+	scheme   *scheme.Scheme
+	versions scheme.GroupVersions
+	strict   bool
+}
+
+var (
+	_ Encoder = &Codec{}
+	_ Decoder = &Codec{}
+)
+
+// NewCodec creates a Codec using the given scheme. Objects registered with several kinds
+// are encoded with the kind of the first matching version; when no version is given, with
+// the kind they have been registered with first.
+//
+// Objects of other versions are converted to the first given version of their group when
+// they are encoded, and when they are decoded by DecodeObject.
+func NewCodec(s *scheme.Scheme, versions ...scheme.GroupVersion) *Codec {
+	return &Codec{scheme: s, versions: versions}
+}
+
+// NewStrictCodec creates a Codec like NewCodec, which returns a *StrictDecodingError when
+// the decoded data has unknown, duplicate or case-mismatched fields. The object is decoded
+// anyway.
+func NewStrictCodec(s *scheme.Scheme, versions ...scheme.GroupVersion) *Codec {
+	return &Codec{scheme: s, versions: versions, strict: true}
+}
+
+// Encode implements Encoder. The apiVersion and kind of registered objects are set from
+// the scheme; other values are encoded as is.
+func (c *Codec) Encode(v interface{}) ([]byte, error) {
+	obj, ok := v.(scheme.Object)
+	if !ok {
+		return json.Marshal(v)
+	}
+
+	gvk, err := c.encodeKind(obj)
+	if err != nil {
+		if scheme.IsNotRegisteredError(err) {
+			return json.Marshal(v)
+		}
+
+		return nil, err
+	}
+	if len(c.versions) > 0 && !c.isTargetVersion(gvk) {
+		converted, err := c.convertToTarget(obj, gvk)
+		if err != nil {
+			return nil, err
+		}
+		if converted != nil {
+			return json.Marshal(converted)
+		}
+	}
+
+	kind := obj.GetObjectKind()
+	old := kind.GroupVersionKind()
+	kind.SetGroupVersionKind(gvk)
+	defer kind.SetGroupVersionKind(old)
+
+	return json.Marshal(obj)
+}
+
+func (c *Codec) encodeKind(obj scheme.Object) (scheme.GroupVersionKind, error) {
+	gvks, unversioned, err := c.scheme.ObjectKinds(obj)
+	if err != nil {
+		return scheme.GroupVersionKind{}, err
+	}
+	if unversioned || len(c.versions) == 0 {
+		return gvks[0], nil
+	}
+	if gvk, ok := c.versions.KindForGroupVersionKinds(gvks); ok {
+		return gvk, nil
+	}
+
+	return gvks[0], nil
+}
+
+// Decode implements Decoder. When v is a registered object, the apiVersion and kind of
+// the data must be one of the kinds v is registered with, and are set on v when missing.
+// The default tags of v and the defaulting functions of the scheme are then applied to v.
+// Data of another version of the kind is converted to v, and the fields which cannot be
+// converted are reported by a *conversion.UnconvertedFieldsError.
+func (c *Codec) Decode(data []byte, v interface{}) error {
+	obj, ok := v.(scheme.Object)
+	if !ok {
+		return c.decodeValue(data, v)
+	}
+
+	gvks, _, err := c.scheme.ObjectKinds(obj)
+	if err != nil {
+		if scheme.IsNotRegisteredError(err) {
+			return c.decodeValue(data, v)
+		}
+
+		return err
+	}
+
+	actual, err := peekKind(data)
+	if err != nil {
+		return err
+	}
+	if len(actual.Kind) > 0 && !containsKind(gvks, actual) {
+		return c.decodeAndConvert(data, actual, obj, gvks)
+	}
+
+	warnings, err := c.unmarshal(data, obj)
+	if err != nil {
+		return err
+	}
+	if len(actual.Kind) == 0 {
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	if err := defaulting.SetDefaultsWithFuncs(obj, c.scheme); err != nil {
+		return err
+	}
+
+	return strictError(warnings)
+}
+
+// DecodeObject decodes data into a new object of the Go type registered for its apiVersion
+// and kind, applies the default tags and the defaulting functions of the scheme, and returns the object with its
+// kind. In strict mode, the object and its kind are returned along a *StrictDecodingError.
+// When some fields cannot be converted to the version of the codec, the converted object
+// and its kind are returned along a *conversion.UnconvertedFieldsError.
+func (c *Codec) DecodeObject(data []byte) (scheme.Object, *scheme.GroupVersionKind, error) {
+	gvk, err := peekKind(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(gvk.Kind) == 0 {
+		return nil, nil, scheme.NewMissingKindErr(string(data))
+	}
+
+	obj, err := c.scheme.New(gvk)
+	if err != nil {
+		if len(gvk.Version) == 0 {
+			return nil, nil, scheme.NewMissingVersionErr(string(data))
+		}
+
+		return nil, nil, err
+	}
+	warnings, err := c.unmarshal(data, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := defaulting.SetDefaultsWithFuncs(obj, c.scheme); err != nil {
+		return nil, nil, err
+	}
+
+	if len(c.versions) == 0 || c.isTargetVersion(gvk) {
+		return obj, &gvk, strictError(warnings)
+	}
+	converted, err := c.convertToTarget(obj, gvk)
+	if err != nil && (converted == nil || !conversion.IsUnconvertedFields(err)) {
+		return obj, &gvk, err
+	}
+	if converted == nil {
+		return obj, &gvk, strictError(warnings)
+	}
+	target := converted.GetObjectKind().GroupVersionKind()
+	if err != nil {
+		return converted, &target, err
+	}
+
+	return converted, &target, strictError(warnings)
+}
+
+// decodeAndConvert decodes data into a new object of the Go type registered for actual,
+// and converts it to obj, which is registered with the kinds gvks.
+func (c *Codec) decodeAndConvert(data []byte, actual scheme.GroupVersionKind, obj scheme.Object,
+	gvks []scheme.GroupVersionKind) error {
+	in, err := c.scheme.New(actual)
+	if err != nil || actual.GroupKind() != gvks[0].GroupKind() {
+		return fmt.Errorf("unable to decode %s into %T", actual, obj)
+	}
+	warnings, err := c.unmarshal(data, in)
+	if err != nil {
+		return err
+	}
+	if err := defaulting.SetDefaultsWithFuncs(in, c.scheme); err != nil {
+		return err
+	}
+
+	err = c.scheme.Convert(in, obj, nil)
+	if err != nil && !conversion.IsUnconvertedFields(err) {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	if err != nil {
+		return err
+	}
+
+	return strictError(warnings)
+}
+
+// unmarshal decodes data into v. In strict mode, the unknown, duplicate and case-mismatched
+// fields are returned, to be reported once the object has been fully decoded.
+func (c *Codec) unmarshal(data []byte, v interface{}) (field.ErrorList, error) {
+	if !c.strict {
+		return nil, json.Unmarshal(data, v)
+	}
+
+	return DecodeWithWarnings(data, v)
+}
+
+func (c *Codec) decodeValue(data []byte, v interface{}) error {
+	warnings, err := c.unmarshal(data, v)
+	if err != nil {
+		return err
+	}
+
+	return strictError(warnings)
+}
+
+func strictError(warnings field.ErrorList) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	return &StrictDecodingError{Errors: warnings}
+}
+
+// isTargetVersion returns true if gvk is in one of the versions of the codec, or if the
+// codec has no version for its group.
+func (c *Codec) isTargetVersion(gvk scheme.GroupVersionKind) bool {
+	_, ok := c.targetVersion(gvk)
+
+	return !ok
+}
+
+// targetVersion returns the version gvk should be converted to, and false if it is
+// already in one of the versions of the codec or there is none for its group.
+func (c *Codec) targetVersion(gvk scheme.GroupVersionKind) (scheme.GroupVersion, bool) {
+	for _, gv := range c.versions {
+		if gv == gvk.GroupVersion() {
+			return scheme.GroupVersion{}, false
+		}
+	}
+	for _, gv := range c.versions {
+		if gv.Group == gvk.Group {
+			return gv, true
+		}
+	}
+
+	return scheme.GroupVersion{}, false
+}
+
+// convertToTarget converts obj of kind gvk to the target version of the codec. It returns
+// nil if the kind does not exist in the target version.
+func (c *Codec) convertToTarget(obj scheme.Object, gvk scheme.GroupVersionKind) (scheme.Object, error) {
+	target, ok := c.targetVersion(gvk)
+	if !ok || !c.scheme.Recognizes(target.WithKind(gvk.Kind)) {
+		return nil, nil
+	}
+
+	return c.scheme.ConvertToVersion(obj, target)
+}
+
+// peekKind returns the apiVersion and kind of serialized data.
+func peekKind(data []byte) (scheme.GroupVersionKind, error) {
+	var typeMeta struct {
+		APIVersion string `json:"apiVersion,omitempty"`
+		Kind       string `json:"kind,omitempty"`
+	}
+	if err := json.Unmarshal(data, &typeMeta); err != nil {
+		return scheme.GroupVersionKind{}, fmt.Errorf("couldn't get version/kind; json parse error: %w", err)
+	}
+
+	return scheme.FromAPIVersionAndKind(typeMeta.APIVersion, typeMeta.Kind), nil
+}
+
+// containsKind returns true if gvk is one of gvks. A gvk without version only has to
+// match the kind.
+func containsKind(gvks []scheme.GroupVersionKind, gvk scheme.GroupVersionKind) bool {
+	for _, k := range gvks {
+		if k == gvk || (len(gvk.Version) == 0 && k.Kind == gvk.Kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type codecClientNegotiator struct {
+	codec *Codec
+}
+
+var _ ClientNegotiator = &codecClientNegotiator{}
+
+func (n *codecClientNegotiator) Encoder() (Encoder, error) {
+	return n.codec, nil
+}
+
+func (n *codecClientNegotiator) Decoder() (Decoder, error) {
+	return n.codec, nil
+}
+
+// NewVersionedClientNegotiator returns a ClientNegotiator whose serializer sets the type
+// information of the objects from the scheme, encoding them in the given version.
+func NewVersionedClientNegotiator(s *scheme.Scheme, version scheme.GroupVersion) ClientNegotiator {
+	return &codecClientNegotiator{codec: NewCodec(s, version)}
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/conversion"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/scheme"
+)
+
+var (
+	testGroupVersion   = scheme.GroupVersion{Group: "iam.marmotedu.com", Version: "v1"}
+	testGroupVersionV2 = scheme.GroupVersion{Group: "iam.marmotedu.com", Version: "v2"}
+)
+
+type Secret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Expires int64 `json:"expires"`
+}
+
+type Policy struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Name string `json:"name"`
+}
+
+func newTestScheme() *scheme.Scheme {
+	s := scheme.NewScheme()
+	s.AddKnownTypes(testGroupVersion, &Secret{}, &Policy{})
+	s.AddTypeDefaultingFunc(&Secret{}, func(obj interface{}) {
+		if secret := obj.(*Secret); secret.Expires == 0 {
+			secret.Expires = 3600
+		}
+	})
+
+	return s
+}
+
+func TestCodecEncode(t *testing.T) {
+	codec := NewCodec(newTestScheme())
+
+	secret := &Secret{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Expires: 60}
+	data, err := codec.Encode(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj, gvk, err := codec.DecodeObject(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *gvk != testGroupVersion.WithKind("Secret") {
+		t.Errorf("expected the kind to be set on encode, got %v in %s", gvk, data)
+	}
+	if decoded, ok := obj.(*Secret); !ok || decoded.Name != "foo" || decoded.Expires != 60 {
+		t.Errorf("unexpected decoded object %#v", obj)
+	}
+	if secret.Kind != "" {
+		t.Errorf("expected the encoded object to be left untouched, got %v", secret.TypeMeta)
+	}
+
+	if data, err := codec.Encode(map[string]string{"a": "b"}); err != nil || string(data) != `{"a":"b"}` {
+		t.Errorf("expected values which are not objects to be encoded as is, got %s, %v", data, err)
+	}
+}
+
+func TestCodecEncodeVersion(t *testing.T) {
+	s := newTestScheme()
+	s.AddKnownTypeWithName(testGroupVersionV2.WithKind("AccessKey"), &Secret{})
+
+	encoder, _ := NewVersionedClientNegotiator(s, testGroupVersionV2).Encoder()
+	data, err := encoder.Encode(&Secret{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk, _ := peekKind(data); gvk != testGroupVersionV2.WithKind("AccessKey") {
+		t.Errorf("expected the object to be encoded in the requested version, got %s", data)
+	}
+}
+
+func TestCodecDecode(t *testing.T) {
+	codec := NewCodec(newTestScheme())
+
+	secret := &Secret{}
+	if err := codec.Decode([]byte(`{"metadata":{"name":"foo"}}`), secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.Kind != "Secret" || secret.APIVersion != testGroupVersion.String() || secret.Expires != 3600 {
+		t.Errorf("expected the kind and defaults to be set, got %#v", secret)
+	}
+
+	if err := codec.Decode([]byte(`{"apiVersion":"iam.marmotedu.com/v1","kind":"Policy"}`), &Secret{}); err == nil {
+		t.Errorf("expected an error decoding a policy into a secret")
+	}
+
+	if _, _, err := codec.DecodeObject([]byte(`{"apiVersion":"iam.marmotedu.com/v1"}`)); !scheme.IsMissingKind(err) {
+		t.Errorf("expected a missing kind error, got %v", err)
+	}
+	if _, _, err := codec.DecodeObject([]byte(`{"kind":"Secret"}`)); !scheme.IsMissingVersion(err) {
+		t.Errorf("expected a missing version error, got %v", err)
+	}
+	if _, _, err := codec.DecodeObject([]byte(`{"apiVersion":"v9","kind":"Secret"}`)); !scheme.IsNotRegisteredError(err) {
+		t.Errorf("expected a not registered error, got %v", err)
+	}
+}
+
+type SecretV2 struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	ExpiresSeconds int64  `json:"expiresSeconds"`
+	Description    string `json:"description"`
+}
+
+func newVersionedTestScheme(t *testing.T) *scheme.Scheme {
+	s := newTestScheme()
+	s.AddKnownTypeWithName(testGroupVersionV2.WithKind("Secret"), &SecretV2{})
+	err := s.AddConversionFunc(&Secret{}, &SecretV2{}, func(a, b interface{}, scope conversion.Scope) error {
+		in, out := a.(*Secret), b.(*SecretV2)
+		out.ObjectMeta = in.ObjectMeta
+		out.ExpiresSeconds = in.Expires
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return s
+}
+
+func TestCodecConvert(t *testing.T) {
+	s := newVersionedTestScheme(t)
+	codec := NewCodec(s, testGroupVersionV2)
+	data := []byte(`{"apiVersion":"iam.marmotedu.com/v1","kind":"Secret","metadata":{"name":"foo"}}`)
+
+	obj, gvk, err := codec.DecodeObject(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secret, ok := obj.(*SecretV2)
+	if !ok || *gvk != testGroupVersionV2.WithKind("Secret") || secret.Name != "foo" || secret.ExpiresSeconds != 3600 {
+		t.Errorf("expected the defaulted v1 payload to be converted to v2, got %v %#v", gvk, obj)
+	}
+
+	secret = &SecretV2{}
+	if err := codec.Decode(data, secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.Kind != "Secret" || secret.APIVersion != testGroupVersionV2.String() || secret.ExpiresSeconds != 3600 {
+		t.Errorf("expected the v1 payload to be decoded into the v2 object, got %#v", secret)
+	}
+
+	encoded, err := codec.Encode(&Secret{Expires: 60})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk, _ := peekKind(encoded); gvk != testGroupVersionV2.WithKind("Secret") {
+		t.Errorf("expected the object to be converted to v2 on encode, got %s", encoded)
+	}
+}
+
+type Token struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Scope string `json:"scope"`
+}
+
+type internalToken struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+type TokenV2 struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func TestCodecConvertUnconvertedFields(t *testing.T) {
+	s := scheme.NewScheme()
+	s.AddKnownTypeWithName(testGroupVersion.WithKind("Token"), &Token{})
+	s.AddKnownTypeWithName(testGroupVersionV2.WithKind("Token"), &TokenV2{})
+	s.AddKnownTypeWithName(scheme.GroupVersion{Group: testGroupVersion.Group, Version: scheme.APIVersionInternal}.
+		WithKind("Token"), &internalToken{})
+	codec := NewCodec(s, testGroupVersionV2)
+	data := []byte(`{"apiVersion":"iam.marmotedu.com/v1","kind":"Token","metadata":{"name":"foo"},"scope":"all"}`)
+
+	// the scope is lost in the internal version, the rest of the object is converted to v2
+	obj, gvk, err := codec.DecodeObject(data)
+	unconverted, ok := err.(*conversion.UnconvertedFieldsError)
+	if !ok || len(unconverted.Fields) != 1 || unconverted.Fields[0] != "scope" {
+		t.Fatalf("expected the scope to be reported as unconverted, got %v", err)
+	}
+	token, ok := obj.(*TokenV2)
+	if !ok || *gvk != testGroupVersionV2.WithKind("Token") || token.Name != "foo" {
+		t.Errorf("expected the v1 payload to be converted to v2, got %v %#v", gvk, obj)
+	}
+
+	token = &TokenV2{}
+	if err := codec.Decode(data, token); !conversion.IsUnconvertedFields(err) {
+		t.Errorf("expected an unconverted fields error, got %v", err)
+	}
+	if token.Name != "foo" || token.APIVersion != testGroupVersionV2.String() {
+		t.Errorf("expected the v1 payload to be decoded into the v2 object, got %#v", token)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// maxFrameLength is the maximum size of a frame read by the length delimited framer.
+const maxFrameLength = 64 << 20
+
+// Framer is a factory for creating readers and writers that obey a particular framing pattern.
+type Framer interface {
+	NewFrameReader(r io.Reader) FrameReader
+	NewFrameWriter(w io.Writer) FrameWriter
+}
+
+// FrameReader reads the frames of a stream one by one.
+type FrameReader interface {
+	// ReadFrame returns the next frame, or io.EOF at the end of the stream.
+	ReadFrame() ([]byte, error)
+}
+
+// FrameWriter writes the frames of a stream one by one.
+type FrameWriter interface {
+	// WriteFrame writes a frame. The frame must not be modified until WriteFrame returns.
+	WriteFrame(frame []byte) error
+	// Close terminates the stream. It does not close the underlying writer.
+	Close() error
+}
+
+var (
+	// NewlineFramer separates frames with newlines, as in newline delimited JSON. Frames
+	// must not contain newlines.
+	NewlineFramer Framer = newlineFramer{}
+
+	// YAMLFramer separates YAML documents with "---" lines.
+	YAMLFramer Framer = yamlFramer{}
+
+	// LengthDelimitedFramer prefixes frames with their length as a 4 bytes big endian integer.
+	LengthDelimitedFramer Framer = lengthDelimitedFramer{}
+)
+
+type newlineFramer struct{}
+
+func (newlineFramer) NewFrameReader(r io.Reader) FrameReader {
+	return &newlineFrameReader{r: bufio.NewReader(r)}
+}
+
+func (newlineFramer) NewFrameWriter(w io.Writer) FrameWriter {
+	return &newlineFrameWriter{w: w}
+}
+
+type newlineFrameReader struct {
+	r *bufio.Reader
+}
+
+func (r *newlineFrameReader) ReadFrame() ([]byte, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if frame := bytes.TrimSpace(line); len(frame) > 0 {
+			return frame, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+type newlineFrameWriter struct {
+	w io.Writer
+}
+
+func (w *newlineFrameWriter) WriteFrame(frame []byte) error {
+	if bytes.IndexByte(frame, '\n') >= 0 {
+		return fmt.Errorf("newline delimited frames must not contain newlines")
+	}
+	if _, err := w.w.Write(frame); err != nil {
+		return err
+	}
+	_, err := w.w.Write([]byte{'\n'})
+
+	return err
+}
+
+func (w *newlineFrameWriter) Close() error {
+	return nil
+}
+
+type yamlFramer struct{}
+
+func (yamlFramer) NewFrameReader(r io.Reader) FrameReader {
+	return &yamlFrameReader{r: bufio.NewReader(r)}
+}
+
+func (yamlFramer) NewFrameWriter(w io.Writer) FrameWriter {
+	return &yamlFrameWriter{w: w}
+}
+
+const yamlSeparator = "---"
+
+type yamlFrameReader struct {
+	r *bufio.Reader
+}
+
+func (r *yamlFrameReader) ReadFrame() ([]byte, error) {
+	var frame bytes.Buffer
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if bytes.Equal(bytes.TrimRight(line, " \t\r\n"), []byte(yamlSeparator)) {
+			if len(bytes.TrimSpace(frame.Bytes())) > 0 {
+				return frame.Bytes(), nil
+			}
+			frame.Reset()
+
+			continue
+		}
+		frame.Write(line)
+		if err != nil {
+			if err == io.EOF && len(bytes.TrimSpace(frame.Bytes())) > 0 {
+				return frame.Bytes(), nil
+			}
+
+			return nil, err
+		}
+	}
+}
+
+type yamlFrameWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (w *yamlFrameWriter) WriteFrame(frame []byte) error {
+	if w.started {
+		if _, err := io.WriteString(w.w, yamlSeparator+"\n"); err != nil {
+			return err
+		}
+	}
+	w.started = true
+
+	if _, err := w.w.Write(frame); err != nil {
+		return err
+	}
+	if len(frame) > 0 && frame[len(frame)-1] != '\n' {
+		_, err := w.w.Write([]byte{'\n'})
+
+		return err
+	}
+
+	return nil
+}
+
+func (w *yamlFrameWriter) Close() error {
+	return nil
+}
+
+type lengthDelimitedFramer struct{}
+
+func (lengthDelimitedFramer) NewFrameReader(r io.Reader) FrameReader {
+	return &lengthDelimitedFrameReader{r: r}
+}
+
+func (lengthDelimitedFramer) NewFrameWriter(w io.Writer) FrameWriter {
+	return &lengthDelimitedFrameWriter{w: w}
+}
+
+type lengthDelimitedFrameReader struct {
+	r io.Reader
+}
+
+func (r *lengthDelimitedFrameReader) ReadFrame() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:])
+	if length > maxFrameLength {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the maximum frame length of %d bytes", length, maxFrameLength)
+	}
+
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(r.r, frame); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+type lengthDelimitedFrameWriter struct {
+	w io.Writer
+}
+
+func (w *lengthDelimitedFrameWriter) WriteFrame(frame []byte) error {
+	if len(frame) > maxFrameLength {
+		return fmt.Errorf("frame of %d bytes exceeds the maximum frame length of %d bytes", len(frame), maxFrameLength)
+	}
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(frame)))
+	if _, err := w.w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(frame)
+
+	return err
+}
+
+func (w *lengthDelimitedFrameWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// SerializerOptions holds the options which control the behavior of the JSON and YAML serializers.
+type SerializerOptions struct {
+	// Strict configures the serializer to return a *StrictDecodingError when the decoded
+	// data has unknown, duplicate or case-mismatched fields. The object is decoded anyway.
+	Strict bool
+}
+
+type jsonSerializer struct {
+	options SerializerOptions
+}
+
+// NewJSONSerializer returns a Serializer encoding objects in compact JSON.
+func NewJSONSerializer() Serializer {
+	return jsonSerializer{}
+}
+
+// NewJSONSerializerWithOptions returns a Serializer encoding objects in compact JSON,
+// configured by the given options.
+func NewJSONSerializerWithOptions(options SerializerOptions) Serializer {
+	return jsonSerializer{options: options}
+}
+
+func (jsonSerializer) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (s jsonSerializer) Decode(data []byte, v interface{}) error {
+	if s.options.Strict {
+		return DecodeStrict(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// toGeneric converts v to its JSON representation as generic maps, slices and scalars.
+// Integers are kept as int64 or uint64 when they fit.
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(generic), nil
+}
+
+// fromGeneric decodes generic maps, slices and scalars into v as if they were JSON.
+func fromGeneric(generic interface{}, v interface{}) error {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = convertNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = convertNumbers(e)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+	}
+
+	return v
+}
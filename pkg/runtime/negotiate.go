@@ -6,6 +6,10 @@ package runtime
 
 import (
 	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/marmotedu/component-base/pkg/json"
 )
@@ -53,3 +57,138 @@ func (s *apimachineryClientNegotiatorSerializer) Encode(v interface{}) ([]byte,
 func NewSimpleClientNegotiator() ClientNegotiator {
 	return &apimachineryClientNegotiator{}
 }
+
+// acceptedMediaType is a media range of an Accept header.
+type acceptedMediaType struct {
+	Type    string
+	SubType string
+	Quality float64
+}
+
+// parseAccept parses an Accept header into media ranges sorted by decreasing quality.
+// Media ranges with the same quality keep the order of the header.
+func parseAccept(header string) []acceptedMediaType {
+	var accepted []acceptedMediaType
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		if quality == 0 {
+			continue
+		}
+
+		t, subType := splitMediaType(mediaType)
+		accepted = append(accepted, acceptedMediaType{Type: t, SubType: subType, Quality: quality})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].Quality > accepted[j].Quality
+	})
+
+	return accepted
+}
+
+func (a acceptedMediaType) matches(info SerializerInfo) bool {
+	return (a.Type == "*" || strings.EqualFold(a.Type, info.MediaTypeType)) &&
+		(a.SubType == "*" || strings.EqualFold(a.SubType, info.MediaTypeSubType))
+}
+
+// NegotiateOutputMediaType returns the serializer to encode a response with, from the
+// Accept header of the request. The media ranges are tried by decreasing quality, and
+// the first registered serializer is used when the header is empty.
+func NegotiateOutputMediaType(accept string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	return negotiateOutput(accept, ns, false)
+}
+
+// NegotiateOutputStreamSerializer returns the serializer to stream a response with, from
+// the Accept header of the request. Only serializers with a StreamSerializer are considered.
+func NegotiateOutputStreamSerializer(accept string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	return negotiateOutput(accept, ns, true)
+}
+
+func negotiateOutput(accept string, ns NegotiatedSerializer, stream bool) (SerializerInfo, error) {
+	var candidates []SerializerInfo
+	for _, info := range ns.SupportedMediaTypes() {
+		if !stream || info.StreamSerializer != nil {
+			candidates = append(candidates, info)
+		}
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		if len(candidates) > 0 {
+			return candidates[0], nil
+		}
+	} else {
+		for _, accepted := range parseAccept(accept) {
+			for _, info := range candidates {
+				if accepted.matches(info) {
+					return info, nil
+				}
+			}
+		}
+	}
+
+	return SerializerInfo{}, NegotiateError{ContentType: accept, Stream: stream}
+}
+
+// NegotiateInputSerializer returns the serializer to decode a request with, from its
+// Content-Type header. The first registered serializer is used when the header is empty.
+func NegotiateInputSerializer(contentType string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	mediaTypes := ns.SupportedMediaTypes()
+	if strings.TrimSpace(contentType) == "" {
+		if len(mediaTypes) > 0 {
+			return mediaTypes[0], nil
+		}
+
+		return SerializerInfo{}, NegotiateError{ContentType: contentType}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return SerializerInfo{}, NegotiateError{ContentType: contentType}
+	}
+	if info, ok := SerializerInfoForMediaType(mediaTypes, mediaType); ok {
+		return info, nil
+	}
+
+	return SerializerInfo{}, NegotiateError{ContentType: contentType}
+}
+
+type clientNegotiator struct {
+	serializer  NegotiatedSerializer
+	contentType string
+}
+
+var _ ClientNegotiator = &clientNegotiator{}
+
+func (n *clientNegotiator) Encoder() (Encoder, error) {
+	info, err := NegotiateInputSerializer(n.contentType, n.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Serializer, nil
+}
+
+func (n *clientNegotiator) Decoder() (Decoder, error) {
+	info, err := NegotiateInputSerializer(n.contentType, n.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Serializer, nil
+}
+
+// NewClientNegotiator returns a ClientNegotiator using the serializer registered for the
+// content type. Encoder and Decoder return a NegotiateError if there is none.
+func NewClientNegotiator(serializer NegotiatedSerializer, contentType string) ClientNegotiator {
+	return &clientNegotiator{serializer: serializer, contentType: contentType}
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"testing"
+)
+
+func TestNegotiateOutputMediaType(t *testing.T) {
+	ns := NewDefaultSerializerRegistry()
+	testCases := []struct {
+		accept    string
+		mediaType string
+	}{
+		{"", ContentTypeJSON},
+		{"application/yaml", ContentTypeYAML},
+		{"text/html, application/cbor;q=0.5, application/msgpack;q=0.8", ContentTypeMsgpack},
+		{"application/json;q=0.1, application/*;q=0.9", ContentTypeJSON},
+		{"application/x-protobuf, application/json;q=0.9", ContentTypeProtobuf},
+		{"APPLICATION/YAML;charset=utf-8", ContentTypeYAML},
+		{"*/*", ContentTypeJSON},
+		{"application/yaml;q=0, */*;q=0.1", ContentTypeJSON},
+	}
+
+	for _, tc := range testCases {
+		info, err := NegotiateOutputMediaType(tc.accept, ns)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.accept, err)
+
+			continue
+		}
+		if info.MediaType != tc.mediaType {
+			t.Errorf("%q: expected %s, got %s", tc.accept, tc.mediaType, info.MediaType)
+		}
+	}
+
+	_, err := NegotiateOutputMediaType("text/html, application/yaml;q=0", ns)
+	if e, ok := err.(NegotiateError); !ok || e.ContentType != "text/html, application/yaml;q=0" || e.Stream {
+		t.Errorf("expected a negotiate error, got %v", err)
+	}
+}
+
+func TestNegotiateOutputStreamSerializer(t *testing.T) {
+	ns := NewSerializerRegistry(
+		SerializerInfo{MediaType: ContentTypeJSON, Serializer: NewJSONSerializer()},
+		SerializerInfo{MediaType: ContentTypeYAML, Serializer: NewYAMLSerializer(), StreamSerializer: &StreamSerializerInfo{
+			Serializer: NewYAMLSerializer(),
+			Framer:     YAMLFramer,
+		}},
+	)
+
+	info, err := NegotiateOutputStreamSerializer("", ns)
+	if err != nil || info.MediaType != ContentTypeYAML {
+		t.Errorf("expected the yaml stream serializer, got %v, %v", info.MediaType, err)
+	}
+	if _, err := NegotiateOutputStreamSerializer(ContentTypeJSON, ns); err == nil || !err.(NegotiateError).Stream {
+		t.Errorf("expected a stream negotiate error, got %v", err)
+	}
+}
+
+func TestNegotiateInputSerializer(t *testing.T) {
+	ns := NewDefaultSerializerRegistry()
+
+	info, err := NegotiateInputSerializer("application/yaml; charset=utf-8", ns)
+	if err != nil || info.MediaType != ContentTypeYAML {
+		t.Errorf("expected the yaml serializer, got %v, %v", info.MediaType, err)
+	}
+	if _, err := NegotiateInputSerializer("text/plain", ns); err == nil {
+		t.Errorf("expected a negotiate error")
+	}
+
+	decoder, err := NewClientNegotiator(ns, ContentTypeYAML).Decoder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := &Policy{}
+	if err := decoder.Decode([]byte("name: foo"), policy); err != nil || policy.Name != "foo" {
+		t.Errorf("unexpected decoded policy %#v, %v", policy, err)
+	}
+	if _, err := NewClientNegotiator(ns, "text/plain").Encoder(); err == nil {
+		t.Errorf("expected a negotiate error")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"strings"
+)
+
+// Media types of the serializers provided by this package.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeYAML     = "application/yaml"
+	ContentTypeCBOR     = "application/cbor"
+	ContentTypeMsgpack  = "application/msgpack"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Serializer is the core interface for transforming objects into a serialized format and back.
+// Implementations may choose to perform conversion of the object, but no assumptions should be made.
+type Serializer interface {
+	Encoder
+	Decoder
+}
+
+// SerializerInfo contains information about a specific serialization format.
+type SerializerInfo struct {
+	// MediaType is the value that represents this serializer over the wire.
+	MediaType string
+	// MediaTypeType is the first part of the MediaType ("application" in "application/json").
+	MediaTypeType string
+	// MediaTypeSubType is the second part of the MediaType ("json" in "application/json").
+	MediaTypeSubType string
+	// EncodesAsText indicates this serializer can be encoded to UTF-8 safely.
+	EncodesAsText bool
+	// Serializer is the individual object serializer for this media type.
+	Serializer Serializer
+	// StreamSerializer, if set, describes the streaming serialization format
+	// for this media type.
+	StreamSerializer *StreamSerializerInfo
+}
+
+// StreamSerializerInfo contains information about a specific stream serialization format.
+type StreamSerializerInfo struct {
+	// EncodesAsText indicates this serializer can be encoded to UTF-8 safely.
+	EncodesAsText bool
+	// Serializer is the serializer for an individual object in the stream.
+	Serializer Serializer
+	// Framer is the factory for retrieving streams that separate objects on the wire.
+	Framer Framer
+}
+
+// NegotiatedSerializer is an interface used for obtaining encoders, decoders, and serializers
+// for multiple supported media types.
+type NegotiatedSerializer interface {
+	// SupportedMediaTypes is the media types supported for reading and writing single objects,
+	// in order of preference.
+	SupportedMediaTypes() []SerializerInfo
+}
+
+// SerializerRegistry is a NegotiatedSerializer holding serializers registered by media type.
+type SerializerRegistry struct {
+	infos []SerializerInfo
+}
+
+var _ NegotiatedSerializer = &SerializerRegistry{}
+
+// NewSerializerRegistry creates a SerializerRegistry with the given serializers. The first
+// one is used when a client expresses no preference.
+func NewSerializerRegistry(infos ...SerializerInfo) *SerializerRegistry {
+	r := &SerializerRegistry{}
+	for _, info := range infos {
+		r.Register(info)
+	}
+
+	return r
+}
+
+// NewDefaultSerializerRegistry creates a SerializerRegistry with the JSON, YAML, CBOR,
+// msgpack and protobuf serializers, JSON being the default.
+func NewDefaultSerializerRegistry() *SerializerRegistry {
+	return NewSerializerRegistry(
+		SerializerInfo{
+			MediaType:     ContentTypeJSON,
+			EncodesAsText: true,
+			Serializer:    NewJSONSerializer(),
+			StreamSerializer: &StreamSerializerInfo{
+				EncodesAsText: true,
+				Serializer:    NewJSONSerializer(),
+				Framer:        NewlineFramer,
+			},
+		},
+		SerializerInfo{
+			MediaType:     ContentTypeYAML,
+			EncodesAsText: true,
+			Serializer:    NewYAMLSerializer(),
+			StreamSerializer: &StreamSerializerInfo{
+				EncodesAsText: true,
+				Serializer:    NewYAMLSerializer(),
+				Framer:        YAMLFramer,
+			},
+		},
+		SerializerInfo{
+			MediaType:  ContentTypeCBOR,
+			Serializer: NewCBORSerializer(),
+			StreamSerializer: &StreamSerializerInfo{
+				Serializer: NewCBORSerializer(),
+				Framer:     LengthDelimitedFramer,
+			},
+		},
+		SerializerInfo{
+			MediaType:  ContentTypeMsgpack,
+			Serializer: NewMsgpackSerializer(),
+			StreamSerializer: &StreamSerializerInfo{
+				Serializer: NewMsgpackSerializer(),
+				Framer:     LengthDelimitedFramer,
+			},
+		},
+		SerializerInfo{
+			MediaType:  ContentTypeProtobuf,
+			Serializer: NewProtobufSerializer(),
+			StreamSerializer: &StreamSerializerInfo{
+				Serializer: NewProtobufSerializer(),
+				Framer:     LengthDelimitedFramer,
+			},
+		},
+	)
+}
+
+// Register adds a serializer, replacing the one registered for the same media type if any.
+// MediaTypeType and MediaTypeSubType are set from MediaType when empty.
+func (r *SerializerRegistry) Register(info SerializerInfo) {
+	if info.MediaTypeType == "" && info.MediaTypeSubType == "" {
+		info.MediaTypeType, info.MediaTypeSubType = splitMediaType(info.MediaType)
+	}
+
+	for i := range r.infos {
+		if r.infos[i].MediaType == info.MediaType {
+			r.infos[i] = info
+
+			return
+		}
+	}
+	r.infos = append(r.infos, info)
+}
+
+// SupportedMediaTypes implements NegotiatedSerializer.
+func (r *SerializerRegistry) SupportedMediaTypes() []SerializerInfo {
+	return r.infos
+}
+
+// SerializerInfoForMediaType returns the serializer registered for the media type, ignoring
+// its parameters.
+func SerializerInfoForMediaType(types []SerializerInfo, mediaType string) (SerializerInfo, bool) {
+	mediaType = strings.TrimSpace(strings.Split(mediaType, ";")[0])
+	for _, info := range types {
+		if strings.EqualFold(info.MediaType, mediaType) {
+			return info, true
+		}
+	}
+
+	return SerializerInfo{}, false
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	parts := strings.SplitN(mediaType, "/", 2)
+	if len(parts) != 2 {
+		return mediaType, ""
+	}
+
+	return parts[0], parts[1]
+}
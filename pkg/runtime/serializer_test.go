@@ -0,0 +1,120 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+func TestSerializersRoundTrip(t *testing.T) {
+	secret := &Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "iam.marmotedu.com/v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{ID: 18446744073709551615, Name: "foo", Extend: metav1.Extend{"a": "b"}},
+		Expires:    -1,
+	}
+
+	for _, info := range NewDefaultSerializerRegistry().SupportedMediaTypes() {
+		if info.MediaType == ContentTypeProtobuf {
+			continue
+		}
+
+		t.Run(info.MediaType, func(t *testing.T) {
+			data, err := info.Serializer.Encode(secret)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded := &Secret{}
+			if err := info.Serializer.Decode(data, decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, secret) {
+				t.Errorf("expected %#v, got %#v", secret, decoded)
+			}
+		})
+	}
+}
+
+func TestYAMLSerializer(t *testing.T) {
+	data, err := NewYAMLSerializer().Encode(&Policy{Name: "foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "name: foo\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, data)
+	}
+
+	policy := &Policy{}
+	if err := NewYAMLSerializer().Decode([]byte("kind: Policy\nname: bar\n"), policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Kind != "Policy" || policy.Name != "bar" {
+		t.Errorf("unexpected decoded policy %#v", policy)
+	}
+}
+
+func TestProtobufSerializer(t *testing.T) {
+	s := NewProtobufSerializer()
+	data, err := s.Encode(&wrappers.StringValue{Value: "foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded := &wrappers.StringValue{}
+	if err := s.Decode(data, decoded); err != nil || decoded.Value != "foo" {
+		t.Errorf("unexpected decoded message %v, %v", decoded, err)
+	}
+
+	if _, err := s.Encode(&Policy{}); err == nil {
+		t.Errorf("expected an error encoding an object which is not a protobuf message")
+	}
+}
+
+func TestFramers(t *testing.T) {
+	frames := [][]byte{[]byte("a: 1\n"), []byte("b: 2\n"), []byte("c: 3\n")}
+	for name, framer := range map[string]Framer{
+		"yaml":             YAMLFramer,
+		"length delimited": LengthDelimitedFramer,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := framer.NewFrameWriter(&buf)
+			for _, frame := range frames {
+				if err := w.WriteFrame(frame); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			r := framer.NewFrameReader(&buf)
+			for _, expected := range frames {
+				frame, err := r.ReadFrame()
+				if err != nil || !bytes.Equal(frame, expected) {
+					t.Fatalf("expected frame %q, got %q, %v", expected, frame, err)
+				}
+			}
+			if _, err := r.ReadFrame(); err != io.EOF {
+				t.Errorf("expected the end of the stream, got %v", err)
+			}
+		})
+	}
+
+	r := NewlineFramer.NewFrameReader(bytes.NewBufferString("{\"a\":1}\n\n{\"b\":2}"))
+	for _, expected := range []string{`{"a":1}`, `{"b":2}`} {
+		if frame, err := r.ReadFrame(); err != nil || string(frame) != expected {
+			t.Errorf("expected frame %q, got %q, %v", expected, frame, err)
+		}
+	}
+	if err := NewlineFramer.NewFrameWriter(&bytes.Buffer{}).WriteFrame([]byte("a\nb")); err == nil {
+		t.Errorf("expected an error writing a frame containing a newline")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"bytes"
+	// The token API is not provided by jsoniter, JSON arrays are read with the standard
+	// library whatever the build tags.
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamEncoder writes objects to an io.Writer one by one, each object being encoded in
+// its own frame. Only one object is held in memory at a time, so that large lists can be
+// written in constant memory:
+//
+//	e := runtime.NewStreamEncoder(w, runtime.NewJSONSerializer(), runtime.JSONArrayFramer)
+//	for rows.Next() {
+//		...
+//		if err := e.Encode(&secret); err != nil {
+//			return err
+//		}
+//	}
+//	return e.Close()
+type StreamEncoder struct {
+	encoder Encoder
+	writer  FrameWriter
+}
+
+// NewStreamEncoder creates a StreamEncoder writing the objects encoded by encoder to w,
+// separated by the framer.
+func NewStreamEncoder(w io.Writer, encoder Encoder, framer Framer) *StreamEncoder {
+	return &StreamEncoder{encoder: encoder, writer: framer.NewFrameWriter(w)}
+}
+
+// Encode writes an object to the stream.
+func (e *StreamEncoder) Encode(v interface{}) error {
+	data, err := e.encoder.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	return e.writer.WriteFrame(data)
+}
+
+// Close terminates the stream, e.g. writes the end of a JSON array. It does not close
+// the underlying writer.
+func (e *StreamEncoder) Close() error {
+	return e.writer.Close()
+}
+
+// StreamDecoder reads objects from an io.Reader one by one.
+type StreamDecoder struct {
+	decoder Decoder
+	reader  FrameReader
+}
+
+// NewStreamDecoder creates a StreamDecoder reading the frames of r separated by the framer,
+// and decoding them with decoder.
+func NewStreamDecoder(r io.Reader, decoder Decoder, framer Framer) *StreamDecoder {
+	return &StreamDecoder{decoder: decoder, reader: framer.NewFrameReader(r)}
+}
+
+// Decode decodes the next object of the stream into v. It returns io.EOF at the end of
+// the stream.
+func (d *StreamDecoder) Decode(v interface{}) error {
+	frame, err := d.reader.ReadFrame()
+	if err != nil {
+		return err
+	}
+
+	return d.decoder.Decode(frame, v)
+}
+
+// JSONArrayFramer writes frames as the items of a JSON array, and reads the items of a
+// JSON array as frames. Frames must be JSON values.
+var JSONArrayFramer Framer = jsonArrayFramer{}
+
+type jsonArrayFramer struct{}
+
+func (jsonArrayFramer) NewFrameReader(r io.Reader) FrameReader {
+	return &jsonArrayFrameReader{decoder: stdjson.NewDecoder(r)}
+}
+
+func (jsonArrayFramer) NewFrameWriter(w io.Writer) FrameWriter {
+	return &jsonArrayFrameWriter{w: w}
+}
+
+type jsonArrayFrameReader struct {
+	decoder *stdjson.Decoder
+	started bool
+	done    bool
+}
+
+func (r *jsonArrayFrameReader) ReadFrame() ([]byte, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	if !r.started {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token != stdjson.Delim('[') {
+			return nil, fmt.Errorf("expected the start of a JSON array, got %v", token)
+		}
+		r.started = true
+	}
+
+	if !r.decoder.More() {
+		if _, err := r.decoder.Token(); err != nil {
+			return nil, err
+		}
+		r.done = true
+
+		return nil, io.EOF
+	}
+
+	var frame stdjson.RawMessage
+	if err := r.decoder.Decode(&frame); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+type jsonArrayFrameWriter struct {
+	w       io.Writer
+	started bool
+	closed  bool
+}
+
+func (w *jsonArrayFrameWriter) WriteFrame(frame []byte) error {
+	if w.closed {
+		return fmt.Errorf("write to a closed JSON array")
+	}
+
+	separator := []byte{','}
+	if !w.started {
+		separator[0] = '['
+		w.started = true
+	}
+	if _, err := w.w.Write(separator); err != nil {
+		return err
+	}
+	_, err := w.w.Write(bytes.TrimSpace(frame))
+
+	return err
+}
+
+func (w *jsonArrayFrameWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	end := "]"
+	if !w.started {
+		end = "[]"
+	}
+	_, err := io.WriteString(w.w, end)
+
+	return err
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestStreamEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		name       string
+		serializer Serializer
+		framer     Framer
+	}{
+		{"ndjson", NewJSONSerializer(), NewlineFramer},
+		{"json array", NewJSONSerializer(), JSONArrayFramer},
+		{"yaml", NewYAMLSerializer(), YAMLFramer},
+		{"msgpack", NewMsgpackSerializer(), LengthDelimitedFramer},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			const count = 1000
+			r, w := io.Pipe()
+			go func() {
+				e := NewStreamEncoder(w, tc.serializer, tc.framer)
+				for i := 0; i < count; i++ {
+					if err := e.Encode(&Policy{Name: fmt.Sprintf("policy-%d", i)}); err != nil {
+						w.CloseWithError(err)
+
+						return
+					}
+				}
+				w.CloseWithError(e.Close())
+			}()
+
+			d := NewStreamDecoder(r, tc.serializer, tc.framer)
+			for i := 0; i < count; i++ {
+				policy := &Policy{}
+				if err := d.Decode(policy); err != nil {
+					t.Fatalf("unexpected error decoding item %d: %v", i, err)
+				}
+				if expected := fmt.Sprintf("policy-%d", i); policy.Name != expected {
+					t.Fatalf("expected %s, got %s", expected, policy.Name)
+				}
+			}
+			if err := d.Decode(&Policy{}); err != io.EOF {
+				t.Errorf("expected the end of the stream, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJSONArrayFramer(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewStreamEncoder(&buf, NewJSONSerializer(), JSONArrayFramer)
+	if err := e.Close(); err != nil || buf.String() != "[]" {
+		t.Errorf("expected an empty array, got %q, %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	e = NewStreamEncoder(&buf, NewJSONSerializer(), JSONArrayFramer)
+	for _, name := range []string{"a", "b"} {
+		if err := e.Encode(&Policy{Name: name}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[{"name":"a"},{"name":"b"}]`; buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+	if err := e.Encode(&Policy{}); err == nil {
+		t.Errorf("expected an error writing to a closed array")
+	}
+
+	d := NewStreamDecoder(bytes.NewBufferString(`{"name":"a"}`), NewJSONSerializer(), JSONArrayFramer)
+	if err := d.Decode(&Policy{}); err == nil {
+		t.Errorf("expected an error reading an object as an array")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"bytes"
+	"encoding"
+	// The token API is not provided by jsoniter, the JSON documents are checked with
+	// the standard library whatever the build tags.
+	stdjson "encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// StrictDecodingError is returned by strict decoders when the data has unknown fields,
+// duplicate fields, or fields which only match the fields of the object when ignoring case.
+// The object has been decoded when this error is returned.
+type StrictDecodingError struct {
+	Errors field.ErrorList
+}
+
+func (e *StrictDecodingError) Error() string {
+	return fmt.Sprintf("strict decoding error: %s", e.Errors.ToAggregate().Error())
+}
+
+// IsStrictDecodingError returns true if the error is a *StrictDecodingError.
+func IsStrictDecodingError(err error) bool {
+	_, ok := err.(*StrictDecodingError)
+
+	return ok
+}
+
+// DecodeStrict decodes JSON data into v, and returns a *StrictDecodingError when the data
+// has unknown, duplicate or case-mismatched fields.
+func DecodeStrict(data []byte, v interface{}) error {
+	warnings, err := DecodeWithWarnings(data, v)
+	if err != nil {
+		return err
+	}
+	if len(warnings) > 0 {
+		return &StrictDecodingError{Errors: warnings}
+	}
+
+	return nil
+}
+
+// DecodeWithWarnings decodes JSON data into v leniently, and returns the unknown, duplicate
+// and case-mismatched fields of the data as warnings:
+//   - unknown fields are reported as Forbidden errors;
+//   - duplicate fields are reported as Duplicate errors, the last value is decoded;
+//   - fields matching the name of a field of v only when ignoring case are reported as
+//     Invalid errors, and are decoded into that field.
+func DecodeWithWarnings(data []byte, v interface{}) (field.ErrorList, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	return CheckStrict(data, reflect.TypeOf(v)), nil
+}
+
+// CheckStrict returns the unknown, duplicate and case-mismatched fields of the JSON data
+// decoded into a value of type t. data must be valid JSON.
+func CheckStrict(data []byte, t reflect.Type) field.ErrorList {
+	decoder := stdjson.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	c := &strictChecker{decoder: decoder}
+	if err := c.check(t, nil); err != nil {
+		return field.ErrorList{field.Invalid(nil, string(data), err.Error())}
+	}
+
+	return c.errs
+}
+
+type strictChecker struct {
+	decoder *stdjson.Decoder
+	errs    field.ErrorList
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*stdjson.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// check consumes the next value of the decoder, which is decoded into a value of type t.
+// The members of objects are only checked against t when t is a struct; t is nil when the
+// value is not checked, in which case only duplicate fields are reported.
+func (c *strictChecker) check(t reflect.Type, fldPath *field.Path) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && (reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType)) {
+		t = nil
+	}
+
+	token, err := c.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case stdjson.Delim('{'):
+		return c.checkObject(t, fldPath)
+	case stdjson.Delim('['):
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for i := 0; c.decoder.More(); i++ {
+			if err := c.check(elemType, fldPath.Index(i)); err != nil {
+				return err
+			}
+		}
+		_, err := c.decoder.Token()
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *strictChecker) checkObject(t reflect.Type, fldPath *field.Path) error {
+	var fields *structFields
+	var elemType reflect.Type
+	if t != nil {
+		switch t.Kind() {
+		case reflect.Struct:
+			fields = cachedStructFields(t)
+		case reflect.Map:
+			elemType = t.Elem()
+		}
+	}
+
+	seen := map[string]bool{}
+	for c.decoder.More() {
+		token, err := c.decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		childPath := fldPath.Child(key)
+
+		if seen[key] {
+			c.errs = append(c.errs, field.Duplicate(childPath, key))
+		}
+		seen[key] = true
+
+		valueType := elemType
+		if fields != nil {
+			var name string
+			valueType, name = fields.lookup(key)
+			switch {
+			case valueType == nil:
+				c.errs = append(c.errs, field.Forbidden(childPath, "unknown field"))
+			case name != key:
+				c.errs = append(c.errs, field.Invalid(childPath, key, fmt.Sprintf("does not match the case of field %q", name)))
+			}
+		}
+
+		if err := c.check(valueType, childPath); err != nil {
+			return err
+		}
+	}
+	_, err := c.decoder.Token()
+
+	return err
+}
+
+// structFields are the JSON fields of a struct type.
+type structFields struct {
+	byName      map[string]reflect.Type
+	byFoldedKey map[string]string
+}
+
+// lookup returns the type and the name of the field matching key, or nil if there is none.
+// Like encoding/json, keys are matched case-insensitively when there is no exact match.
+func (f *structFields) lookup(key string) (reflect.Type, string) {
+	if t, ok := f.byName[key]; ok {
+		return t, key
+	}
+	if name, ok := f.byFoldedKey[strings.ToLower(key)]; ok {
+		return f.byName[name], name
+	}
+
+	return nil, ""
+}
+
+var structFieldsCache sync.Map // map[reflect.Type]*structFields
+
+func cachedStructFields(t reflect.Type) *structFields {
+	if f, ok := structFieldsCache.Load(t); ok {
+		return f.(*structFields)
+	}
+
+	fields := &structFields{byName: map[string]reflect.Type{}, byFoldedKey: map[string]string{}}
+	collectStructFields(t, fields, map[reflect.Type]bool{})
+	f, _ := structFieldsCache.LoadOrStore(t, fields)
+
+	return f.(*structFields)
+}
+
+// collectStructFields adds the JSON fields of the struct type t to fields. The fields of
+// anonymous struct fields without JSON name are promoted, as encoding/json does. Fields
+// found at a lower depth take precedence.
+func collectStructFields(t reflect.Type, fields *structFields, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if _, exists := fields.byName[name]; exists {
+			continue
+		}
+		fields.byName[name] = sf.Type
+		if _, exists := fields.byFoldedKey[strings.ToLower(name)]; !exists {
+			fields.byFoldedKey[strings.ToLower(name)] = name
+		}
+	}
+
+	for _, et := range embedded {
+		collectStructFields(et, fields, visited)
+	}
+}
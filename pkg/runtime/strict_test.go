@@ -0,0 +1,117 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+type strictItem struct {
+	Name  string `json:"name"`
+	Value int    `json:"value,omitempty"`
+}
+
+type strictSpec struct {
+	Items  []strictItem      `json:"items"`
+	Labels map[string]string `json:"labels"`
+	Any    interface{}       `json:"any"`
+}
+
+func strictErrors(errs field.ErrorList) []string {
+	var got []string
+	for _, err := range errs {
+		got = append(got, string(err.Type)+" "+err.Field)
+	}
+
+	return got
+}
+
+func TestDecodeWithWarnings(t *testing.T) {
+	data := []byte(`{
+		"apiVersion": "iam.marmotedu.com/v1",
+		"metadata": {"name": "foo", "nmae": "bar", "Extend": {"a": 1, "a": 2}},
+		"expires": 1,
+		"expires": 2,
+		"Expires2": 3
+	}`)
+
+	secret := &Secret{}
+	warnings, err := DecodeWithWarnings(data, secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.Name != "foo" || secret.Expires != 2 || secret.Extend["a"] != float64(2) {
+		t.Errorf("expected the data to be decoded leniently, got %#v", secret)
+	}
+
+	expected := []string{
+		string(field.ErrorTypeForbidden) + " metadata.nmae",
+		string(field.ErrorTypeInvalid) + " metadata.Extend",
+		string(field.ErrorTypeDuplicate) + " metadata.Extend.a",
+		string(field.ErrorTypeDuplicate) + " expires",
+		string(field.ErrorTypeForbidden) + " Expires2",
+	}
+	if got := strictErrors(warnings); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected warnings %v, got %v", expected, got)
+	}
+}
+
+func TestCheckStrictNested(t *testing.T) {
+	data := []byte(`{"items":[{"name":"a"},{"name":"b","vaule":1}],"labels":{"x":"y"},"any":{"z":1,"z":2}}`)
+
+	expected := []string{
+		string(field.ErrorTypeForbidden) + " items[1].vaule",
+		string(field.ErrorTypeDuplicate) + " any.z",
+	}
+	if got := strictErrors(CheckStrict(data, reflect.TypeOf(&strictSpec{}))); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected errors %v, got %v", expected, got)
+	}
+}
+
+func TestStrictSerializers(t *testing.T) {
+	testCases := []struct {
+		serializer Serializer
+		data       string
+	}{
+		{NewJSONSerializerWithOptions(SerializerOptions{Strict: true}), `{"name":"foo","nmae":"bar"}`},
+		{NewYAMLSerializerWithOptions(SerializerOptions{Strict: true}), "name: foo\nnmae: bar\n"},
+	}
+
+	for _, tc := range testCases {
+		item := &strictItem{}
+		err := tc.serializer.Decode([]byte(tc.data), item)
+		if !IsStrictDecodingError(err) {
+			t.Errorf("expected a strict decoding error, got %v", err)
+		}
+		if item.Name != "foo" {
+			t.Errorf("expected the object to be decoded, got %#v", item)
+		}
+	}
+
+	if err := NewYAMLSerializerWithOptions(SerializerOptions{Strict: true}).Decode([]byte("name: a\nname: b\n"),
+		&strictItem{}); err == nil {
+		t.Errorf("expected an error decoding duplicate yaml keys")
+	}
+}
+
+func TestStrictCodec(t *testing.T) {
+	data := []byte(`{"apiVersion":"iam.marmotedu.com/v1","kind":"Secret","expries":1}`)
+
+	if _, _, err := NewCodec(newTestScheme()).DecodeObject(data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	obj, _, err := NewStrictCodec(newTestScheme()).DecodeObject(data)
+	strictErr, ok := err.(*StrictDecodingError)
+	if !ok || len(strictErr.Errors) != 1 || strictErr.Errors[0].Field != "expries" {
+		t.Fatalf("expected a strict decoding error, got %v", err)
+	}
+	if secret, ok := obj.(*Secret); !ok || secret.Expires != 3600 {
+		t.Errorf("expected the defaulted object to be returned, got %#v", obj)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+type yamlSerializer struct {
+	options SerializerOptions
+}
+
+// NewYAMLSerializer returns a Serializer encoding objects in YAML. Objects are converted
+// to and from JSON, so the json struct tags and the JSON marshalers of the objects apply.
+func NewYAMLSerializer() Serializer {
+	return yamlSerializer{}
+}
+
+// NewYAMLSerializerWithOptions returns a Serializer encoding objects in YAML, configured by
+// the given options. In strict mode, duplicate keys are rejected by the YAML parser.
+func NewYAMLSerializerWithOptions(options SerializerOptions) Serializer {
+	return yamlSerializer{options: options}
+}
+
+func (yamlSerializer) Encode(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(data)
+}
+
+func (s yamlSerializer) Decode(data []byte, v interface{}) error {
+	data, err := yamlToJSON(data, s.options.Strict)
+	if err != nil {
+		return err
+	}
+	if s.options.Strict {
+		return DecodeStrict(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// jsonToYAML converts JSON to YAML, keeping the order of the object members.
+func jsonToYAML(data []byte) ([]byte, error) {
+	// JSON is a subset of YAML, decoding objects into a MapSlice keeps their order.
+	var obj yaml.MapSlice
+	if err := yaml.Unmarshal(data, &obj); err == nil {
+		return yaml.Marshal(obj)
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(v)
+}
+
+// yamlToJSON converts a YAML document to JSON. Duplicate keys are an error in strict mode.
+func yamlToJSON(data []byte, strict bool) ([]byte, error) {
+	unmarshal := yaml.Unmarshal
+	if strict {
+		unmarshal = yaml.UnmarshalStrict
+	}
+
+	var v interface{}
+	if err := unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	v, err := convertYAMLValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// convertYAMLValue converts the maps decoded from YAML, which may have keys of any type,
+// to maps with string keys.
+func convertYAMLValue(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			var key string
+			switch typedKey := k.(type) {
+			case string:
+				key = typedKey
+			case int, int64, uint64, float64, bool:
+				key = fmt.Sprint(typedKey)
+			default:
+				return nil, fmt.Errorf("unsupported map key of type %T: %v", k, k)
+			}
+			converted, err := convertYAMLValue(e)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = converted
+		}
+
+		return m, nil
+	case []interface{}:
+		for i, e := range t {
+			converted, err := convertYAMLValue(e)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = converted
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scheme
+
+import (
+	"reflect"
+
+	"github.com/marmotedu/component-base/pkg/conversion"
+)
+
+// APIVersionInternal may be used if you are registering a type that should not
+// be considered stable or serialized - it is a convention only and has no
+// special behavior in this package. Versions converted by a Scheme go through
+// the internal version of a kind when it is registered.
+const APIVersionInternal = "__internal"
+
+// Converter allows access to the converter for the scheme.
+func (s *Scheme) Converter() *conversion.Converter {
+	return s.converter
+}
+
+// AddConversionFunc registers a function that converts between a and b by passing objects of those
+// types to the provided function. The function *must* accept objects of a and b - this machinery will not enforce
+// any other guarantee.
+func (s *Scheme) AddConversionFunc(a, b interface{}, fn conversion.ConversionFunc) error {
+	return s.converter.RegisterUntypedConversionFunc(a, b, fn)
+}
+
+// Convert will attempt to convert in into out. Both must be pointers. When no conversion
+// function is registered between the types of in and out, and both are registered objects
+// of a kind which also has an internal version, in is converted to the internal version
+// first, and then to out. Otherwise, in is converted to out field by field.
+//
+// The fields which cannot be converted are reported by a *conversion.UnconvertedFieldsError,
+// and the rest of the object is converted: the fields of in lost in the internal version
+// are reported with the fields of the internal version lost in out.
+func (s *Scheme) Convert(in, out interface{}, context interface{}) error {
+	meta := &conversion.Meta{Context: context}
+	if s.converter.HasConversionFunc(in, out) {
+		return s.converter.Convert(in, out, meta)
+	}
+
+	hub := s.internalVersionOf(in, out)
+	if hub == nil {
+		return s.converter.Convert(in, out, meta)
+	}
+
+	err := s.converter.Convert(in, hub, meta)
+	toHub, ok := err.(*conversion.UnconvertedFieldsError)
+	if err != nil && !ok {
+		return err
+	}
+
+	err = s.converter.Convert(hub, out, meta)
+	fromHub, ok := err.(*conversion.UnconvertedFieldsError)
+	if err != nil && !ok {
+		return err
+	}
+
+	return mergeUnconverted(in, out, toHub, fromHub)
+}
+
+// mergeUnconverted merges the unconverted fields of the conversions of in to the internal
+// version and of the internal version to out. It returns nil if there is none.
+func mergeUnconverted(in, out interface{}, errs ...*conversion.UnconvertedFieldsError) error {
+	var fields []string
+	seen := map[string]bool{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		for _, f := range err.Fields {
+			if !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &conversion.UnconvertedFieldsError{
+		Source: reflect.TypeOf(in).Elem(),
+		Dest:   reflect.TypeOf(out).Elem(),
+		Fields: fields,
+	}
+}
+
+// ConvertToVersion attempts to convert an input object to its matching kind in the target
+// group version, and returns it as a new object. Unversioned objects and objects already in
+// the target version are copied. When some fields cannot be converted, the object is
+// returned with a *conversion.UnconvertedFieldsError.
+func (s *Scheme) ConvertToVersion(in Object, target GroupVersion) (Object, error) {
+	v, err := enforcePtr(in)
+	if err != nil {
+		return nil, err
+	}
+	t := v.Type()
+
+	kinds, ok := s.typeToGVK[t]
+	if !ok {
+		return nil, NewNotRegisteredErrForType(t)
+	}
+
+	if gvk, ok := s.unversionedTypes[t]; ok {
+		return copyWithKind(v, gvk), nil
+	}
+	for _, gvk := range kinds {
+		if gvk.GroupVersion() == target {
+			return copyWithKind(v, gvk), nil
+		}
+	}
+
+	gvk := target.WithKind(kinds[0].Kind)
+	out, err := s.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Convert(in, out, nil)
+	if err != nil && !conversion.IsUnconvertedFields(err) {
+		return nil, err
+	}
+	out.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return out, err
+}
+
+// internalVersionOf returns a new object of the internal version of the kind of in and
+// out, or nil if there is none, or if in or out already is of the internal version.
+func (s *Scheme) internalVersionOf(in, out interface{}) interface{} {
+	inType, outType := reflect.TypeOf(in), reflect.TypeOf(out)
+	if inType.Kind() != reflect.Ptr || outType.Kind() != reflect.Ptr {
+		return nil
+	}
+
+	for _, gvk := range s.typeToGVK[inType.Elem()] {
+		internal, ok := s.gvkToType[gvk.GroupKind().WithVersion(APIVersionInternal)]
+		if !ok || internal == inType.Elem() || internal == outType.Elem() {
+			continue
+		}
+
+		return reflect.New(internal).Interface()
+	}
+
+	return nil
+}
+
+func copyWithKind(v reflect.Value, gvk GroupVersionKind) Object {
+	out := reflect.New(v.Type())
+	out.Elem().Set(v)
+	obj := out.Interface().(Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return obj
+}
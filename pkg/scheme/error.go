@@ -0,0 +1,98 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scheme
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type notRegisteredErr struct {
+	gvk GroupVersionKind
+	t   reflect.Type
+}
+
+// NewNotRegisteredErrForKind returns an error reporting that the kind is not registered.
+func NewNotRegisteredErrForKind(gvk GroupVersionKind) error {
+	return &notRegisteredErr{gvk: gvk}
+}
+
+// NewNotRegisteredErrForType returns an error reporting that the type is not registered.
+func NewNotRegisteredErrForType(t reflect.Type) error {
+	return &notRegisteredErr{t: t}
+}
+
+func (k *notRegisteredErr) Error() string {
+	if k.t != nil {
+		return fmt.Sprintf("no kind is registered for the type %v", k.t)
+	}
+	if len(k.gvk.Kind) == 0 {
+		return fmt.Sprintf("no version %q has been registered", k.gvk.GroupVersion())
+	}
+	if k.gvk.Version == "" {
+		return fmt.Sprintf("no kind %q is registered for the group %q", k.gvk.Kind, k.gvk.Group)
+	}
+
+	return fmt.Sprintf("no kind %q is registered for version %q", k.gvk.Kind, k.gvk.GroupVersion())
+}
+
+// IsNotRegisteredError returns true if the error indicates the provided
+// object or input data is not registered.
+func IsNotRegisteredError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*notRegisteredErr)
+
+	return ok
+}
+
+type missingKindErr struct {
+	data string
+}
+
+// NewMissingKindErr returns an error reporting that the serialized data has no kind.
+func NewMissingKindErr(data string) error {
+	return &missingKindErr{data}
+}
+
+func (k *missingKindErr) Error() string {
+	return fmt.Sprintf("Object 'Kind' is missing in '%s'", k.data)
+}
+
+// IsMissingKind returns true if the error indicates that the provided object
+// is missing a 'Kind' field.
+func IsMissingKind(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*missingKindErr)
+
+	return ok
+}
+
+type missingVersionErr struct {
+	data string
+}
+
+// NewMissingVersionErr returns an error reporting that the serialized data has no version.
+func NewMissingVersionErr(data string) error {
+	return &missingVersionErr{data}
+}
+
+func (k *missingVersionErr) Error() string {
+	return fmt.Sprintf("Object 'apiVersion' is missing in '%s'", k.data)
+}
+
+// IsMissingVersion returns true if the error indicates that the provided object
+// is missing a 'Version' field.
+func IsMissingVersion(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*missingVersionErr)
+
+	return ok
+}
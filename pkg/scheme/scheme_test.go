@@ -0,0 +1,155 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package scheme
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/conversion"
+)
+
+type typeMeta struct {
+	APIVersion string
+	Kind       string
+}
+
+func (obj *typeMeta) GetObjectKind() ObjectKind { return obj }
+
+func (obj *typeMeta) SetGroupVersionKind(gvk GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+
+func (obj *typeMeta) GroupVersionKind() GroupVersionKind {
+	return FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+type User struct {
+	typeMeta
+	Name string
+}
+
+type Status struct {
+	typeMeta
+	Code int
+}
+
+func TestScheme(t *testing.T) {
+	v1 := GroupVersion{Group: "iam.marmotedu.com", Version: "v1"}
+	v2 := GroupVersion{Group: "iam.marmotedu.com", Version: "v2"}
+
+	s := NewScheme()
+	s.AddKnownTypes(v1, &User{})
+	s.AddKnownTypeWithName(v2.WithKind("Account"), &User{})
+	s.AddUnversionedTypes(GroupVersion{Version: "v1"}, &Status{})
+
+	gvks, unversioned, err := s.ObjectKinds(&User{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []GroupVersionKind{v1.WithKind("User"), v2.WithKind("Account")}
+	if unversioned || !reflect.DeepEqual(gvks, expected) {
+		t.Errorf("expected kinds %v, got %v (unversioned %v)", expected, gvks, unversioned)
+	}
+
+	if !s.Recognizes(v2.WithKind("Account")) || s.Recognizes(v2.WithKind("User")) {
+		t.Errorf("unexpected recognized kinds %v", s.AllKinds())
+	}
+
+	obj, err := s.New(v2.WithKind("Account"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := obj.(*User); !ok {
+		t.Errorf("expected a *User, got %T", obj)
+	}
+
+	// unversioned kinds can be created in the context of any group and version.
+	if obj, err := s.New(v2.WithKind("Status")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if unversioned, ok := s.IsUnversioned(obj); !ok || !unversioned {
+		t.Errorf("expected %T to be unversioned", obj)
+	}
+
+	if _, err := s.New(v1.WithKind("Group")); !IsNotRegisteredError(err) {
+		t.Errorf("expected a not registered error, got %v", err)
+	}
+	if _, _, err := s.ObjectKinds(&typeMeta{}); !IsNotRegisteredError(err) {
+		t.Errorf("expected a not registered error, got %v", err)
+	}
+
+	if versions := s.PrioritizedVersionsAllGroups(); len(versions) != 3 || versions[0] != v1 {
+		t.Errorf("unexpected versions %v", versions)
+	}
+}
+
+func TestSchemeDefault(t *testing.T) {
+	s := NewScheme()
+	s.AddKnownTypes(GroupVersion{Version: "v1"}, &User{})
+	s.AddTypeDefaultingFunc(&User{}, func(obj interface{}) {
+		if user := obj.(*User); user.Name == "" {
+			user.Name = "admin"
+		}
+	})
+
+	user := &User{}
+	s.Default(user)
+	if user.Name != "admin" {
+		t.Errorf("expected the user to be defaulted, got %v", user)
+	}
+
+	status := &Status{}
+	s.Default(status)
+	if status.Code != 0 {
+		t.Errorf("expected objects without defaulting functions to be left untouched, got %v", status)
+	}
+}
+
+type UserV2 struct {
+	typeMeta
+	DisplayName string
+}
+
+type internalUser struct {
+	typeMeta
+	Name string
+}
+
+func TestConvertToVersion(t *testing.T) {
+	v1 := GroupVersion{Group: "iam.marmotedu.com", Version: "v1"}
+	v2 := GroupVersion{Group: "iam.marmotedu.com", Version: "v2"}
+	internal := GroupVersion{Group: "iam.marmotedu.com", Version: APIVersionInternal}
+
+	s := NewScheme()
+	s.AddKnownTypes(v1, &User{})
+	s.AddKnownTypeWithName(v2.WithKind("User"), &UserV2{})
+	s.AddKnownTypeWithName(internal.WithKind("User"), &internalUser{})
+	if err := s.AddConversionFunc(&internalUser{}, &UserV2{}, func(a, b interface{}, _ conversion.Scope) error {
+		b.(*UserV2).DisplayName = a.(*internalUser).Name
+
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// v1 is converted to the internal version field by field, and then to v2.
+	out, err := s.ConvertToVersion(&User{Name: "colin"}, v2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	user, ok := out.(*UserV2)
+	if !ok || user.DisplayName != "colin" || user.GroupVersionKind() != v2.WithKind("User") {
+		t.Errorf("unexpected converted object %#v", out)
+	}
+
+	// the v2 user has no conversion function to the internal version and DisplayName is lost.
+	if _, err := s.ConvertToVersion(user, v1); !conversion.IsUnconvertedFields(err) {
+		t.Errorf("expected an unconverted fields error, got %v", err)
+	}
+
+	if versions := s.PrioritizedVersionsAllGroups(); len(versions) != 2 {
+		t.Errorf("expected the internal version not to be observed, got %v", versions)
+	}
+}
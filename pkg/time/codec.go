@@ -0,0 +1,188 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// Special layouts, formatting times as numbers.
+const (
+	// LayoutUnix formats times as the number of seconds elapsed since January 1, 1970 UTC.
+	LayoutUnix = "unix"
+	// LayoutUnixMilli formats times as the number of milliseconds elapsed since January 1, 1970 UTC.
+	LayoutUnixMilli = "unixmilli"
+)
+
+var (
+	layoutsLock sync.RWMutex
+	layouts     = map[string]string{
+		"datetime":    defaultDateTimeFormat,
+		"date":        "2006-01-02",
+		"rfc3339":     time.RFC3339,
+		"rfc3339nano": time.RFC3339Nano,
+		"unix":        LayoutUnix,
+		"unixmilli":   LayoutUnixMilli,
+	}
+
+	defaultCodec = Codec{Layout: defaultDateTimeFormat}
+)
+
+// RegisterLayout makes a layout available by name, e.g. to select it from a flag.
+func RegisterLayout(name, layout string) {
+	layoutsLock.Lock()
+	defer layoutsLock.Unlock()
+
+	layouts[name] = layout
+}
+
+// LookupLayout returns the layout registered with the name.
+func LookupLayout(name string) (string, bool) {
+	layoutsLock.RLock()
+	defer layoutsLock.RUnlock()
+
+	layout, ok := layouts[name]
+
+	return layout, ok
+}
+
+// Layouts returns the sorted names of the registered layouts.
+func Layouts() []string {
+	layoutsLock.RLock()
+	defer layoutsLock.RUnlock()
+
+	names := make([]string, 0, len(layouts))
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Codec formats and parses times with a layout, in a time zone.
+type Codec struct {
+	// Layout is a layout as defined by the time package, LayoutUnix or LayoutUnixMilli.
+	Layout string
+	// Location is the time zone in which the times are formatted, and in which the times
+	// without time zone are parsed. If nil, times are formatted in their own time zone, and
+	// parsed in the local time zone.
+	Location *time.Location
+}
+
+// NewCodec returns the codec for the layout registered with the name, or for the layout
+// itself if no layout is registered with this name, in the named time zone, e.g. "UTC" or
+// "Asia/Shanghai". The local time zone is used if zone is empty.
+func NewCodec(layout, zone string) (Codec, error) {
+	if registered, ok := LookupLayout(layout); ok {
+		layout = registered
+	}
+
+	loc := time.Local
+	if len(zone) > 0 {
+		var err error
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return Codec{}, err
+		}
+	}
+
+	return Codec{Layout: layout, Location: loc}, nil
+}
+
+// DefaultCodec returns the codec used by Time and NullTime.
+func DefaultCodec() Codec {
+	layoutsLock.RLock()
+	defer layoutsLock.RUnlock()
+
+	return defaultCodec
+}
+
+// SetDefaultCodec sets the codec used by Time and NullTime. The default codec formats times
+// with the "2006-01-02 15:04:05" layout, and parses them in the local time zone.
+func SetDefaultCodec(c Codec) {
+	layoutsLock.Lock()
+	defer layoutsLock.Unlock()
+
+	defaultCodec = c
+}
+
+func (c Codec) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+
+	return c.Location
+}
+
+// Format returns the textual representation of t.
+func (c Codec) Format(t time.Time) string {
+	switch c.Layout {
+	case LayoutUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case LayoutUnixMilli:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	default:
+		if c.Location != nil {
+			t = t.In(c.Location)
+		}
+
+		return t.Format(c.Layout)
+	}
+}
+
+// Parse parses a time formatted by Format. The empty string is the zero time.
+func (c Codec) Parse(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+
+	switch c.Layout {
+	case LayoutUnix, LayoutUnixMilli:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing time %q as %s: invalid number", value, c.Layout)
+		}
+		if c.Layout == LayoutUnix {
+			return time.Unix(n, 0).In(c.location()), nil
+		}
+
+		return time.Unix(0, n*int64(time.Millisecond)).In(c.location()), nil
+	default:
+		return time.ParseInLocation(c.Layout, value, c.location())
+	}
+}
+
+// EncodeJSON returns the JSON encoding of t, a number for the unix layouts and a string
+// otherwise.
+func (c Codec) EncodeJSON(t time.Time) ([]byte, error) {
+	s := c.Format(t)
+	if c.Layout == LayoutUnix || c.Layout == LayoutUnixMilli {
+		return []byte(s), nil
+	}
+
+	return json.Marshal(s)
+}
+
+// DecodeJSON parses a time encoded by EncodeJSON. Unix times are accepted as strings too.
+func (c Codec) DecodeJSON(data []byte) (time.Time, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %s: %v", data, err)
+		}
+
+		return c.Parse(s)
+	}
+
+	return c.Parse(string(data))
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	sqldriver "database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+const dateFormat = "2006-01-02"
+
+// Date is a date without time and time zone, e.g. a birthday or a holiday. It is formatted
+// as "2006-01-02" in JSON, YAML, flags and databases.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t, in the time zone of t.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Today returns the current date in the local time zone.
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// ParseDate parses a date formatted as "2006-01-02". The date part of datetimes, as returned
+// by some database drivers, is accepted too.
+func ParseDate(s string) (Date, error) {
+	if len(s) > len(dateFormat) && (s[len(dateFormat)] == ' ' || s[len(dateFormat)] == 'T') {
+		s = s[:len(dateFormat)]
+	}
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return DateOf(t), nil
+}
+
+// String returns the date formatted as "2006-01-02", or the empty string for the zero date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero returns true for the zero date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// IsValid returns true if the date is a day of the calendar, e.g. not February 30.
+func (d Date) IsValid() bool {
+	return DateOf(d.In(time.UTC)) == d
+}
+
+// In returns the time of the midnight starting the date, in the given time zone.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days after d.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+// DaysSince returns the number of days from s to d.
+func (d Date) DaysSince(s Date) int {
+	return int(d.In(time.UTC).Sub(s.In(time.UTC)) / (24 * time.Hour))
+}
+
+// Before returns true if d is before e.
+func (d Date) Before(e Date) bool {
+	return d.In(time.UTC).Before(e.In(time.UTC))
+}
+
+// After returns true if d is after e.
+func (d Date) After(e Date) bool {
+	return e.Before(d)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The empty string is the zero date.
+func (d *Date) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*d = Date{}
+
+		return nil
+	}
+
+	parsed, err := ParseDate(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. The zero date is null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. null is a no-op.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid date %s: %v", data, err)
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Date) MarshalYAML() (interface{}, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+
+	return d.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Date) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// Set implements github.com/spf13/pflag.Value.
+func (d *Date) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
+
+// Type implements github.com/spf13/pflag.Value.
+func (d *Date) Type() string {
+	return "date"
+}
+
+// Value implements the driver Valuer interface. The zero date is NULL.
+func (d Date) Value() (sqldriver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+
+	return d.String(), nil
+}
+
+// Scan implements the Scanner interface. NULL and the zero dates of MySQL are the zero date.
+func (d *Date) Scan(v interface{}) error {
+	switch typed := v.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = DateOf(typed)
+	case []byte:
+		return d.Scan(string(typed))
+	case string:
+		if strings.HasPrefix(typed, "0000-00-00") {
+			*d = Date{}
+
+			return nil
+		}
+
+		return d.UnmarshalText([]byte(typed))
+	default:
+		return fmt.Errorf("can not convert %v to date", v)
+	}
+
+	return nil
+}
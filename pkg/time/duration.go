@@ -0,0 +1,197 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	sqldriver "database/sql/driver"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// Duration wraps time.Duration, so that it is formatted as a human readable string, e.g.
+// "1h30m0s", in JSON, YAML and flags. Durations formatted as Go durations or as ISO 8601
+// durations, e.g. "PT1H30M", are accepted. Durations are stored as nanoseconds in databases.
+type Duration struct {
+	time.Duration
+}
+
+// iso8601Duration matches the ISO 8601 durations with weeks, days, hours, minutes and
+// seconds. Years and months are ambiguous, and are not supported.
+var iso8601Duration = regexp.MustCompile(`^([-+]?)P(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?` +
+	`(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// iso8601Units are the units of the submatches of iso8601Duration.
+var iso8601Units = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+// ParseDuration parses a Go duration, e.g. "1h30m", or an ISO 8601 duration, e.g. "PT1H30M".
+func ParseDuration(s string) (Duration, error) {
+	if strings.ContainsAny(s, "PT") {
+		d, err := ParseISO8601Duration(s)
+
+		return Duration{d}, err
+	}
+
+	d, err := time.ParseDuration(s)
+
+	return Duration{d}, err
+}
+
+// ParseISO8601Duration parses an ISO 8601 duration, e.g. "PT1H30M" or "P1DT12H". Days are
+// 24 hours and weeks 7 days. Years and months are not supported.
+func ParseISO8601Duration(s string) (time.Duration, error) {
+	matches := iso8601Duration.FindStringSubmatch(s)
+	if matches == nil || strings.HasSuffix(s, "P") || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+
+	var total float64
+	for i, unit := range iso8601Units {
+		value := matches[i+2]
+		if len(value) == 0 {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q: %v", s, err)
+		}
+		total += f * float64(unit)
+	}
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: out of range", s)
+	}
+
+	d := time.Duration(math.Round(total))
+	if matches[1] == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// ISO8601 returns the duration formatted as an ISO 8601 duration, in hours, minutes and
+// seconds, e.g. "PT1H30M".
+func (d Duration) ISO8601() string {
+	if d.Duration == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	remaining := d.Duration
+	if remaining < 0 {
+		b.WriteByte('-')
+		remaining = -remaining
+	}
+	b.WriteString("PT")
+	if hours := remaining / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+		remaining -= hours * time.Hour
+	}
+	if minutes := remaining / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+		remaining -= minutes * time.Minute
+	}
+	if remaining > 0 {
+		b.WriteString(strconv.FormatFloat(remaining.Seconds(), 'f', -1, 64))
+		b.WriteByte('S')
+	}
+
+	return b.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(data []byte) error {
+	parsed, err := ParseDuration(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. null is a no-op.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s: %v", data, err)
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// Set implements github.com/spf13/pflag.Value.
+func (d *Duration) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
+
+// Type implements github.com/spf13/pflag.Value.
+func (d *Duration) Type() string {
+	return "duration"
+}
+
+// Value implements the driver Valuer interface.
+func (d Duration) Value() (sqldriver.Value, error) {
+	return int64(d.Duration), nil
+}
+
+// Scan implements the Scanner interface. Integers are nanoseconds, and strings are Go or
+// ISO 8601 durations. NULL is the zero duration.
+func (d *Duration) Scan(v interface{}) error {
+	switch typed := v.(type) {
+	case nil:
+		*d = Duration{}
+	case int64:
+		*d = Duration{time.Duration(typed)}
+	case []byte:
+		return d.Scan(string(typed))
+	case string:
+		if n, err := strconv.ParseInt(typed, 10, 64); err == nil {
+			*d = Duration{time.Duration(n)}
+
+			return nil
+		}
+
+		return d.UnmarshalText([]byte(typed))
+	default:
+		return fmt.Errorf("can not convert %v to duration", v)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	sqldriver "database/sql/driver"
+	"time"
+)
+
+// NullTime is a time which may be null, for nullable columns and optional fields. It is
+// formatted with the default codec, and null when not valid.
+type NullTime struct {
+	Time  time.Time
+	Valid bool // Valid is true if Time is not NULL
+}
+
+// NewNullTime returns a NullTime, which is valid if t is not the zero time.
+func NewNullTime(t time.Time) NullTime {
+	return NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t NullTime) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+
+	return DefaultCodec().EncodeJSON(t.Time)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *NullTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = NullTime{}
+
+		return nil
+	}
+
+	value, err := DefaultCodec().DecodeJSON(data)
+	if err != nil {
+		return err
+	}
+	*t = NullTime{Time: value, Valid: true}
+
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (t NullTime) Value() (sqldriver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+
+	return t.Time, nil
+}
+
+// Scan implements the Scanner interface.
+func (t *NullTime) Scan(v interface{}) error {
+	value, valid, err := scanTime(v)
+	if err != nil {
+		return err
+	}
+	*t = NullTime{Time: value, Valid: valid}
+
+	return nil
+}
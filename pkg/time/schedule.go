@@ -0,0 +1,370 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes the recurring activation times of a job.
+type Schedule interface {
+	// Next returns the first activation time strictly after t, or the zero time if
+	// there is none.
+	Next(t time.Time) time.Time
+
+	// Prev returns the last activation time strictly before t, or the zero time if
+	// there is none.
+	Prev(t time.Time) time.Time
+}
+
+// searchYears is how far Next and Prev look for an activation time, e.g. for the
+// specs which only match on February 29th.
+const searchYears = 5
+
+// CronSchedule is a Schedule parsed from a cron expression. Each field is a bit set of the
+// values it matches.
+type CronSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+
+	// Location is the time zone the expression is evaluated in. When nil, the expression
+	// is evaluated in the time zone of the times given to Next and Prev.
+	Location *time.Location
+}
+
+var _ Schedule = &CronSchedule{}
+
+// field describes the range and the names of the values of a cron field.
+type field struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	seconds = field{name: "second", min: 0, max: 59}
+	minutes = field{name: "minute", min: 0, max: 59}
+	hours   = field{name: "hour", min: 0, max: 23}
+	doms    = field{name: "day of month", min: 1, max: 31}
+	months  = field{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7.
+	dows = field{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// starBit is set on the fields given as "*" or "?", so that the day of month and the
+// day of week can be combined like cron does.
+const starBit = 1 << 63
+
+// descriptors are the predefined schedules.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseSchedule parses a cron expression:
+//
+//	[TZ=<zone>] [second] minute hour day-of-month month day-of-week
+//
+// The fields accept "*", "?", values, ranges ("1-5"), steps ("*/15", "0-30/10") and
+// comma-separated lists of them. Months and days of week may be given by their three
+// letters English names ("JAN", "MON"). Like cron, a time matches when both the day of
+// month and the day of week match, or either of them if both are restricted.
+//
+// The descriptors @yearly (or @annually), @monthly, @weekly, @daily (or @midnight) and
+// @hourly are accepted, as well as "@every <duration>" for the activations separated by a
+// fixed duration, given as a Go or ISO 8601 duration, e.g. "@every 1h30m".
+//
+// The time zone, given with TZ= or CRON_TZ=, is the name of a location of the IANA time
+// zone database, e.g. "TZ=Asia/Shanghai 0 9 * * MON-FRI".
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("empty schedule")
+	}
+
+	var loc *time.Location
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid schedule %q: missing fields after the time zone", spec)
+		}
+		zone := spec[strings.Index(spec, "=")+1 : i]
+		var err error
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@every ") || strings.HasPrefix(spec, "@every\t") {
+		d, err := ParseDuration(strings.TrimSpace(spec[len("@every"):]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+		if d.Duration < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: the delay must be at least one second", spec)
+		}
+
+		return ConstantDelaySchedule{Delay: d.Duration}, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule %q: unknown descriptor", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 or 6 fields, found %d", spec, len(fields))
+	}
+
+	s := &CronSchedule{Location: loc}
+	for i, f := range []struct {
+		bits  *uint64
+		field field
+	}{
+		{&s.Second, seconds}, {&s.Minute, minutes}, {&s.Hour, hours},
+		{&s.Dom, doms}, {&s.Month, months}, {&s.Dow, dows},
+	} {
+		b, err := parseField(fields[i], f.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+		*f.bits = b
+	}
+	if s.Dow&(1<<7) != 0 {
+		s.Dow |= 1
+	}
+
+	return s, nil
+}
+
+// MustParseSchedule is like ParseSchedule but panics if the spec cannot be parsed.
+func MustParseSchedule(spec string) Schedule {
+	s, err := ParseSchedule(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// parseField returns the bit set of the values matched by a comma-separated list of
+// ranges.
+func parseField(s string, f field) (uint64, error) {
+	var b uint64
+	for _, expr := range strings.Split(s, ",") {
+		r, err := parseRange(expr, f)
+		if err != nil {
+			return 0, err
+		}
+		b |= r
+	}
+
+	return b, nil
+}
+
+// parseRange returns the bit set of the values matched by "*", "?", a value or a range,
+// with an optional step.
+func parseRange(expr string, f field) (uint64, error) {
+	rangeExpr, stepExpr := expr, ""
+	hasStep := false
+	if i := strings.Index(expr, "/"); i >= 0 {
+		rangeExpr, stepExpr, hasStep = expr[:i], expr[i+1:], true
+	}
+
+	var (
+		start, end uint
+		star       bool
+		err        error
+	)
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		start, end, star = f.min, f.max, true
+	case strings.Contains(rangeExpr, "-"):
+		bounds := strings.SplitN(rangeExpr, "-", 2)
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(bounds[1], f); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid %s range %q: beginning of range after end of range", f.name, expr)
+		}
+	default:
+		if start, err = parseValue(rangeExpr, f); err != nil {
+			return 0, err
+		}
+		end = start
+		// "5/10" is "5-max/10"
+		if hasStep {
+			end = f.max
+		}
+	}
+
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepExpr, 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("invalid %s step %q", f.name, expr)
+		}
+		step = uint(n)
+		star = false
+	}
+
+	var b uint64
+	for v := start; v <= end; v += step {
+		b |= 1 << v
+	}
+	if star {
+		b |= starBit
+	}
+
+	return b, nil
+}
+
+// parseValue parses a number or a name of the field.
+func parseValue(s string, f field) (uint, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", f.name, s)
+	}
+	if uint(v) < f.min || uint(v) > f.max {
+		return 0, fmt.Errorf("%s %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+
+	return uint(v), nil
+}
+
+// Next implements Schedule.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	orig := t.Location()
+	t = t.In(s.location(t))
+	// start at the next whole second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+
+	limit := t.Year() + searchYears
+	for t.Year() <= limit {
+		switch {
+		case !s.has(s.Month, uint(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.has(s.Hour, uint(t.Hour())):
+			t = t.Add(time.Hour - sinceHour(t))
+		case !s.has(s.Minute, uint(t.Minute())):
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+		case !s.has(s.Second, uint(t.Second())):
+			t = t.Add(time.Second)
+		default:
+			return t.In(orig)
+		}
+	}
+
+	return time.Time{}
+}
+
+// Prev implements Schedule.
+func (s *CronSchedule) Prev(t time.Time) time.Time {
+	orig := t.Location()
+	t = t.In(s.location(t))
+	// start at the previous whole second
+	if t.Nanosecond() > 0 {
+		t = t.Add(-time.Duration(t.Nanosecond()))
+	} else {
+		t = t.Add(-time.Second)
+	}
+
+	// each step moves to the last second of the previous month, day, hour or minute
+	limit := t.Year() - searchYears
+	for t.Year() >= limit {
+		switch {
+		case !s.has(s.Month, uint(t.Month())):
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Add(-time.Second)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Second)
+		case !s.has(s.Hour, uint(t.Hour())):
+			t = t.Add(-sinceHour(t) - time.Second)
+		case !s.has(s.Minute, uint(t.Minute())):
+			t = t.Add(-time.Duration(t.Second())*time.Second - time.Second)
+		case !s.has(s.Second, uint(t.Second())):
+			t = t.Add(-time.Second)
+		default:
+			return t.In(orig)
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) location(t time.Time) *time.Location {
+	if s.Location != nil {
+		return s.Location
+	}
+
+	return t.Location()
+}
+
+func (s *CronSchedule) has(b uint64, v uint) bool {
+	return b&(1<<v) != 0
+}
+
+// dayMatches returns true if both the day of month and the day of week of t match, or
+// either of them if both fields are restricted.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.has(s.Dom, uint(t.Day()))
+	dow := s.has(s.Dow, uint(t.Weekday()))
+	if s.Dom&starBit != 0 || s.Dow&starBit != 0 {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// sinceHour returns the time elapsed on the wall clock since the beginning of the hour,
+// which is not t.Truncate(time.Hour) in the time zones with a non-hour offset.
+func sinceHour(t time.Time) time.Duration {
+	return time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// ConstantDelaySchedule activates every Delay, at whole seconds. It is what
+// "@every <duration>" is parsed to.
+type ConstantDelaySchedule struct {
+	Delay time.Duration
+}
+
+var _ Schedule = ConstantDelaySchedule{}
+
+// Next implements Schedule. It returns t plus the delay, rounded down to the second.
+func (s ConstantDelaySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Delay - time.Duration(t.Nanosecond()))
+}
+
+// Prev implements Schedule. It returns t minus the delay, rounded down to the second.
+func (s ConstantDelaySchedule) Prev(t time.Time) time.Time {
+	return t.Add(-s.Delay - time.Duration(t.Nanosecond()))
+}
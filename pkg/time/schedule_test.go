@@ -0,0 +1,165 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/util/clock"
+	"github.com/marmotedu/component-base/pkg/util/wait"
+)
+
+func TestScheduleNextPrev(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+
+	at := func(s string, loc *time.Location) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04:05", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return v
+	}
+
+	testCases := []struct {
+		spec       string
+		from       string
+		loc        *time.Location
+		next, prev string
+	}{
+		{"*/15 * * * *", "2020-10-01 09:07:30", time.UTC, "2020-10-01 09:15:00", "2020-10-01 09:00:00"},
+		{"*/15 * * * *", "2020-10-01 09:15:00", time.UTC, "2020-10-01 09:30:00", "2020-10-01 09:00:00"},
+		{"30 */10 * * * *", "2020-10-01 23:55:00", time.UTC, "2020-10-02 00:00:30", "2020-10-01 23:50:30"},
+		{"0 9 * * MON-FRI", "2020-10-02 10:00:00", time.UTC, "2020-10-05 09:00:00", "2020-10-02 09:00:00"},
+		{"0 0 29 2 *", "2021-01-01 00:00:00", time.UTC, "2024-02-29 00:00:00", "2020-02-29 00:00:00"},
+		{"0 0 1,15 * 0", "2020-10-02 00:00:00", time.UTC, "2020-10-04 00:00:00", "2020-10-01 00:00:00"},
+		{"0 0 * * 7", "2020-10-02 00:00:00", time.UTC, "2020-10-04 00:00:00", "2020-09-27 00:00:00"},
+		{"0 0 L * *", "", nil, "", ""},
+		{"@hourly", "2020-12-31 23:30:00", time.UTC, "2021-01-01 00:00:00", "2020-12-31 23:00:00"},
+		{"@yearly", "2020-06-01 00:00:00", time.UTC, "2021-01-01 00:00:00", "2020-01-01 00:00:00"},
+		{"@weekly", "2020-10-01 00:00:00", time.UTC, "2020-10-04 00:00:00", "2020-09-27 00:00:00"},
+		{"TZ=Asia/Shanghai 0 9 * * *", "2020-10-01 00:30:00", time.UTC, "2020-10-01 01:00:00", "2020-09-30 01:00:00"},
+		{"CRON_TZ=Asia/Shanghai 0 9 * * *", "2020-10-01 08:00:00", shanghai, "2020-10-01 09:00:00", "2020-09-30 09:00:00"},
+		{"0 * * * *", "2020-10-01 09:29:00", kolkata, "2020-10-01 10:00:00", "2020-10-01 09:00:00"},
+		{"0 0 30 2 *", "2020-10-01 00:00:00", time.UTC, "", ""},
+		{"60 * * * *", "", nil, "", ""},
+		{"5-1 * * * *", "", nil, "", ""},
+		{"*/0 * * * *", "", nil, "", ""},
+		{"* * * *", "", nil, "", ""},
+		{"@fortnightly", "", nil, "", ""},
+		{"TZ=Mars/Olympus 0 0 * * *", "", nil, "", ""},
+	}
+
+	for _, tc := range testCases {
+		s, err := ParseSchedule(tc.spec)
+		if tc.loc == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.spec)
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.spec, err)
+
+			continue
+		}
+
+		from := at(tc.from, tc.loc)
+		for _, c := range []struct {
+			name     string
+			got      time.Time
+			expected string
+		}{{"next", s.Next(from), tc.next}, {"prev", s.Prev(from), tc.prev}} {
+			if len(c.expected) == 0 {
+				if !c.got.IsZero() {
+					t.Errorf("%s %s: expected no activation, got %v", tc.spec, c.name, c.got)
+				}
+
+				continue
+			}
+			if expected := at(c.expected, tc.loc); !c.got.Equal(expected) || c.got.Location() != tc.loc {
+				t.Errorf("%s %s: expected %v, got %v", tc.spec, c.name, expected, c.got)
+			}
+		}
+	}
+}
+
+func TestConstantDelaySchedule(t *testing.T) {
+	s, err := ParseSchedule("@every PT1M30S")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	from := time.Date(2020, 10, 1, 9, 0, 0, 500, time.UTC)
+	if next := s.Next(from); !next.Equal(time.Date(2020, 10, 1, 9, 1, 30, 0, time.UTC)) {
+		t.Errorf("unexpected next activation %v", next)
+	}
+	if prev := s.Prev(from); !prev.Equal(time.Date(2020, 10, 1, 8, 58, 30, 0, time.UTC)) {
+		t.Errorf("unexpected previous activation %v", prev)
+	}
+	if _, err := ParseSchedule("@every 10ms"); err == nil {
+		t.Errorf("expected an error for a delay below one second")
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	start := time.Date(2020, 10, 1, 9, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	s := NewScheduler(fakeClock)
+
+	var (
+		lock sync.Mutex
+		runs []time.Time
+	)
+	ran := make(chan struct{})
+	if err := s.AddSpec("report", "*/5 * * * *", func(context.Context) {
+		lock.Lock()
+		runs = append(runs, fakeClock.Now())
+		lock.Unlock()
+		ran <- struct{}{}
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.AddSpec("report", "@daily", func(context.Context) {}); err == nil {
+		t.Errorf("expected an error adding a duplicate job")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	for i := 1; i <= 3; i++ {
+		if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatalf("the scheduler is not waiting: %v", err)
+		}
+		fakeClock.Step(5 * time.Minute)
+		<-ran
+		if prev := s.Prev("report"); !prev.Equal(start.Add(time.Duration(i) * 5 * time.Minute)) {
+			t.Errorf("unexpected previous activation %v", prev)
+		}
+	}
+
+	cancel()
+	<-done
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(runs) != 3 || !runs[2].Equal(start.Add(15*time.Minute)) {
+		t.Errorf("unexpected runs %v", runs)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/util/clock"
+	"github.com/marmotedu/component-base/pkg/util/runtime"
+	"github.com/marmotedu/component-base/pkg/util/wait"
+)
+
+// Job is run by a Scheduler at the activation times of its schedule.
+type Job func(ctx context.Context)
+
+// Scheduler runs jobs on schedules. Each job runs in its own goroutine of a wait.Group,
+// so that a job never overlaps with itself: the activations missed while it runs are
+// skipped. The time is read from a clock.Clock, so that tests can drive the scheduler
+// with a clock.FakeClock.
+type Scheduler struct {
+	clock clock.Clock
+
+	lock    sync.Mutex
+	entries map[string]*entry
+	ctx     context.Context
+	group   wait.Group
+}
+
+type entry struct {
+	schedule Schedule
+	job      Job
+	next     time.Time
+	prev     time.Time
+}
+
+// NewScheduler creates a Scheduler reading the time from c, or from the real clock if c
+// is nil.
+func NewScheduler(c clock.Clock) *Scheduler {
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	return &Scheduler{clock: c, entries: map[string]*entry{}}
+}
+
+// Add adds a job with a unique name. When the scheduler is running, the job is scheduled
+// immediately.
+func (s *Scheduler) Add(name string, schedule Schedule, job Job) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.entries[name]; ok {
+		return fmt.Errorf("job %q already exists", name)
+	}
+	e := &entry{schedule: schedule, job: job}
+	s.entries[name] = e
+	if s.ctx != nil {
+		s.start(s.ctx, e)
+	}
+
+	return nil
+}
+
+// AddSpec is like Add, with a schedule parsed by ParseSchedule.
+func (s *Scheduler) AddSpec(name string, spec string, job Job) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	return s.Add(name, schedule, job)
+}
+
+// Run runs the jobs until ctx is done, then waits for the running jobs to return. The
+// jobs are given ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.lock.Lock()
+	if s.ctx != nil {
+		s.lock.Unlock()
+
+		panic("scheduler is already running")
+	}
+	s.ctx = ctx
+	for _, e := range s.entries {
+		s.start(ctx, e)
+	}
+	s.lock.Unlock()
+
+	<-ctx.Done()
+	s.group.Wait()
+
+	s.lock.Lock()
+	s.ctx = nil
+	s.lock.Unlock()
+}
+
+// Next returns the next activation time of a job, or the zero time if the job does not
+// exist or has never been scheduled.
+func (s *Scheduler) Next(name string) time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.entries[name]; ok {
+		return e.next
+	}
+
+	return time.Time{}
+}
+
+// Prev returns the last activation time of a job, or the zero time if it has not run yet.
+func (s *Scheduler) Prev(name string) time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.entries[name]; ok {
+		return e.prev
+	}
+
+	return time.Time{}
+}
+
+// start runs e in the group. s.lock must be held.
+func (s *Scheduler) start(ctx context.Context, e *entry) {
+	e.next = e.schedule.Next(s.clock.Now())
+	s.group.StartWithContext(ctx, func(ctx context.Context) {
+		s.run(ctx, e)
+	})
+}
+
+// run waits for the activation times of e and runs its job, until ctx is done or the
+// schedule has no more activation.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	for {
+		s.lock.Lock()
+		next := e.next
+		s.lock.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := s.clock.NewTimer(next.Sub(s.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C():
+		}
+
+		func() {
+			defer runtime.HandleCrash()
+			e.job(ctx)
+		}()
+
+		s.lock.Lock()
+		e.prev = next
+		e.next = e.schedule.Next(s.clock.Now())
+		s.lock.Unlock()
+	}
+}
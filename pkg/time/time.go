@@ -8,6 +8,7 @@ package time
 import (
 	sqldriver "database/sql/driver"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -15,15 +16,34 @@ const (
 	defaultDateTimeFormat = "2006-01-02 15:04:05"
 )
 
-// Time format json time field by myself.
+// scanLayouts are the layouts of the times returned as text by the database drivers,
+// e.g. by MySQL drivers without parseTime.
+var scanLayouts = []string{"2006-01-02 15:04:05.999999999", time.RFC3339Nano, "2006-01-02"}
+
+// Time format json time field by myself. Only the JSON encoding uses the default codec,
+// the text encoding is the RFC 3339 one of time.Time.
 type Time struct {
 	time.Time
 }
 
-// MarshalJSON on Time format Time field with %Y-%m-%d %H:%M:%S.
+// MarshalJSON on Time format Time field with the default codec, %Y-%m-%d %H:%M:%S by default.
 func (t Time) MarshalJSON() ([]byte, error) {
-	formatted := fmt.Sprintf("\"%s\"", t.Format(defaultDateTimeFormat))
-	return []byte(formatted), nil
+	return DefaultCodec().EncodeJSON(t.Time)
+}
+
+// UnmarshalJSON parses a time formatted with the default codec. null is a no-op.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	value, err := DefaultCodec().DecodeJSON(data)
+	if err != nil {
+		return err
+	}
+	t.Time = value
+
+	return nil
 }
 
 // Value insert timestamp into mysql need this function.
@@ -35,27 +55,75 @@ func (t Time) Value() (sqldriver.Value, error) {
 	return t.Time, nil
 }
 
-// Scan valueof time.Time.
+// Scan valueof time.Time. Times returned as string or []byte by the database drivers are
+// parsed in the time zone of the default codec, and int64 values are unix times. NULL and
+// the zero dates of MySQL are the zero time.
 func (t *Time) Scan(v interface{}) error {
-	value, ok := v.(time.Time)
-	if ok {
-		*t = Time{Time: value}
-		return nil
+	value, _, err := scanTime(v)
+	if err != nil {
+		return err
+	}
+	*t = Time{Time: value}
+
+	return nil
+}
+
+// scanTime converts a value returned by a database driver to a time, valid is false for NULL.
+func scanTime(v interface{}) (value time.Time, valid bool, err error) {
+	switch typed := v.(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case time.Time:
+		return typed, true, nil
+	case int64:
+		return time.Unix(typed, 0), true, nil
+	case []byte:
+		return parseScanned(string(typed))
+	case string:
+		return parseScanned(typed)
+	default:
+		return time.Time{}, false, fmt.Errorf("can not convert %v to timestamp", v)
 	}
-	return fmt.Errorf("can not convert %v to timestamp", v)
 }
 
-// ToTime convert string to Time.
+func parseScanned(s string) (time.Time, bool, error) {
+	// the zero dates of MySQL
+	if strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}, false, nil
+	}
+
+	codec := DefaultCodec()
+	if value, err := codec.Parse(s); err == nil {
+		return value, true, nil
+	}
+	for _, layout := range scanLayouts {
+		if value, err := time.ParseInLocation(layout, s, codec.location()); err == nil {
+			return value, true, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("can not convert %q to timestamp", s)
+}
+
+// ToTime convert string to Time, parsed with the default codec.
 func ToTime(str string) (Time, error) {
-	var jt Time
-	loc, _ := time.LoadLocation("Local")
-	value, err := time.ParseInLocation(defaultDateTimeFormat, str, loc)
+	value, err := DefaultCodec().Parse(str)
 	if err != nil {
-		return jt, err
+		return Time{}, err
 	}
-	return Time{
-		Time: value,
-	}, nil
+
+	return Time{Time: value}, nil
+}
+
+// ToTimeInLocation convert string formatted with layout to Time, in the given time zone
+// if the string does not specify one.
+func ToTimeInLocation(str, layout string, loc *time.Location) (Time, error) {
+	value, err := Codec{Layout: layout, Location: loc}.Parse(str)
+	if err != nil {
+		return Time{}, err
+	}
+
+	return Time{Time: value}, nil
 }
 
 // Now returns the current time.
@@ -0,0 +1,174 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func withDefaultCodec(t *testing.T, layout, zone string) {
+	codec, err := NewCodec(layout, zone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	previous := DefaultCodec()
+	SetDefaultCodec(codec)
+	t.Cleanup(func() {
+		SetDefaultCodec(previous)
+	})
+}
+
+func TestCodec(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	value := time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		layout   string
+		zone     string
+		expected string
+	}{
+		{"datetime", "UTC", `"2020-10-01 08:30:00"`},
+		{"datetime", "Asia/Shanghai", `"2020-10-01 16:30:00"`},
+		{"rfc3339", "Asia/Shanghai", `"2020-10-01T16:30:00+08:00"`},
+		{"unix", "", `1601541000`},
+		{"unixmilli", "", `1601541000000`},
+		{"Jan 2 2006 15h04", "UTC", `"Oct 1 2020 08h30"`},
+	}
+
+	for _, tc := range testCases {
+		codec, err := NewCodec(tc.layout, tc.zone)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := codec.EncodeJSON(value)
+		if err != nil || string(data) != tc.expected {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.layout, tc.expected, data, err)
+		}
+		decoded, err := codec.DecodeJSON(data)
+		if err != nil || !decoded.Equal(value) {
+			t.Errorf("%s: expected %v, got %v (%v)", tc.layout, value, decoded, err)
+		}
+	}
+
+	codec := Codec{Layout: defaultDateTimeFormat, Location: shanghai}
+	if parsed, _ := codec.Parse("2020-10-01 16:30:00"); !parsed.Equal(value) {
+		t.Errorf("expected times without zone to be parsed in the codec location, got %v", parsed)
+	}
+	if parsed, _ := (Codec{Layout: LayoutUnix}).DecodeJSON([]byte(`"1601541000"`)); !parsed.Equal(value) {
+		t.Errorf("expected unix times to be accepted as strings, got %v", parsed)
+	}
+	slashes := Codec{Layout: "2006/01/02", Location: time.UTC}
+	if parsed, err := slashes.DecodeJSON([]byte(`"2020\/10\/01"`)); err != nil || !parsed.Equal(value.Truncate(24*time.Hour)) {
+		t.Errorf("expected the JSON escapes to be decoded, got %v (%v)", parsed, err)
+	}
+	if _, err := NewCodec("datetime", "Mars/Olympus"); err == nil {
+		t.Errorf("expected an error for an unknown time zone")
+	}
+
+	RegisterLayout("compact", "20060102150405")
+	if layout, ok := LookupLayout("compact"); !ok || layout != "20060102150405" {
+		t.Errorf("expected the layout to be registered, got %q", layout)
+	}
+}
+
+type object struct {
+	Created  Time     `json:"created"`
+	Deleted  NullTime `json:"deleted"`
+	Archived NullTime `json:"archived"`
+}
+
+func TestTimeJSON(t *testing.T) {
+	withDefaultCodec(t, "rfc3339", "UTC")
+
+	value := time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC)
+	obj := object{Created: Time{value}, Deleted: NewNullTime(value)}
+	data, err := json.Marshal(obj)
+	expected := `{"created":"2020-10-01T08:30:00Z","deleted":"2020-10-01T08:30:00Z","archived":null}`
+	if err != nil || string(data) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, data, err)
+	}
+
+	decoded := object{Archived: NewNullTime(value)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.Created.Equal(value) || !decoded.Deleted.Valid || !decoded.Deleted.Time.Equal(value) || decoded.Archived.Valid {
+		t.Errorf("unexpected object %#v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"created":"2020-10-01 08:30:00"}`), &decoded); err == nil {
+		t.Errorf("expected an error for a time not formatted with the default codec")
+	}
+
+	withDefaultCodec(t, "datetime", "UTC")
+	if text, err := (Time{value}).MarshalText(); err != nil || string(text) != "2020-10-01T08:30:00Z" {
+		t.Errorf("expected the RFC 3339 text encoding, got %s (%v)", text, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	withDefaultCodec(t, "datetime", "UTC")
+
+	value := time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC)
+	for _, v := range []interface{}{
+		value,
+		"2020-10-01 08:30:00",
+		[]byte("2020-10-01 08:30:00.000"),
+		"2020-10-01T08:30:00Z",
+		value.Unix(),
+	} {
+		var scanned Time
+		if err := scanned.Scan(v); err != nil || !scanned.Equal(value) {
+			t.Errorf("%v: expected %v, got %v (%v)", v, value, scanned, err)
+		}
+		var null NullTime
+		if err := null.Scan(v); err != nil || !null.Valid || !null.Time.Equal(value) {
+			t.Errorf("%v: expected %v, got %v (%v)", v, value, null, err)
+		}
+	}
+
+	for _, v := range []interface{}{nil, "0000-00-00 00:00:00"} {
+		scanned := Now()
+		if err := scanned.Scan(v); err != nil || !scanned.IsZero() {
+			t.Errorf("%v: expected the zero time, got %v (%v)", v, scanned, err)
+		}
+		null := NewNullTime(value)
+		if err := null.Scan(v); err != nil || null.Valid {
+			t.Errorf("%v: expected an invalid time, got %v (%v)", v, null, err)
+		}
+	}
+
+	var scanned Time
+	if err := scanned.Scan("yesterday"); err == nil {
+		t.Errorf("expected an error scanning an invalid time")
+	}
+	if err := scanned.Scan(1.5); err == nil {
+		t.Errorf("expected an error scanning a float")
+	}
+
+	if v, err := (NullTime{}).Value(); v != nil || err != nil {
+		t.Errorf("expected an invalid time to be NULL, got %v (%v)", v, err)
+	}
+}
+
+func TestToTime(t *testing.T) {
+	withDefaultCodec(t, "datetime", "UTC")
+
+	parsed, err := ToTime("2020-10-01 08:30:00")
+	if err != nil || !parsed.Equal(time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time %v (%v)", parsed, err)
+	}
+
+	loc := time.FixedZone("UTC+8", 8*3600)
+	parsed, err = ToTimeInLocation("2020/10/01 16:30", "2006/01/02 15:04", loc)
+	if err != nil || !parsed.Equal(time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time %v (%v)", parsed, err)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	sqldriver "database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// TimeOfDay is a time of a day without date and time zone, e.g. the opening hour of a
+// business. It is formatted as "15:04:05", with the fractional seconds if any, in JSON,
+// YAML, flags and databases.
+type TimeOfDay struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// TimeOfDayOf returns the time of the day of t, in the time zone of t.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(), Nanosecond: t.Nanosecond()}
+}
+
+// ParseTimeOfDay parses a time of the day formatted as "15:04", "15:04:05" or
+// "15:04:05.999999999".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	layout := "15:04:05.999999999"
+	if len(s) == len("15:04") {
+		layout = "15:04"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return TimeOfDay{}, err
+	}
+
+	return TimeOfDayOf(t), nil
+}
+
+// String returns the time formatted as "15:04:05", followed by the fractional seconds if any.
+func (t TimeOfDay) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond == 0 {
+		return s
+	}
+
+	return s + time.Date(0, 1, 1, 0, 0, 0, t.Nanosecond, time.UTC).Format(".999999999")
+}
+
+// IsValid returns true if the time is a time of a day, e.g. not 25:00.
+func (t TimeOfDay) IsValid() bool {
+	return t.Hour >= 0 && t.Hour < 24 && t.Minute >= 0 && t.Minute < 60 && t.Second >= 0 && t.Second < 60 &&
+		t.Nanosecond >= 0 && t.Nanosecond < int(time.Second)
+}
+
+// SinceMidnight returns the duration elapsed since the midnight.
+func (t TimeOfDay) SinceMidnight() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute +
+		time.Duration(t.Second)*time.Second + time.Duration(t.Nanosecond)
+}
+
+// On returns the time at this time of the day on the date, in the given time zone.
+func (t TimeOfDay) On(d Date, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, t.Nanosecond, loc)
+}
+
+// Before returns true if t is before u.
+func (t TimeOfDay) Before(u TimeOfDay) bool {
+	return t.SinceMidnight() < u.SinceMidnight()
+}
+
+// After returns true if t is after u.
+func (t TimeOfDay) After(u TimeOfDay) bool {
+	return u.Before(t)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TimeOfDay) UnmarshalText(data []byte) error {
+	parsed, err := ParseTimeOfDay(string(data))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. null is a no-op.
+func (t *TimeOfDay) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid time of day %s: %v", data, err)
+	}
+
+	return t.UnmarshalText([]byte(s))
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (t TimeOfDay) MarshalYAML() (interface{}, error) {
+	return t.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *TimeOfDay) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	return t.UnmarshalText([]byte(s))
+}
+
+// Set implements github.com/spf13/pflag.Value.
+func (t *TimeOfDay) Set(value string) error {
+	return t.UnmarshalText([]byte(value))
+}
+
+// Type implements github.com/spf13/pflag.Value.
+func (t *TimeOfDay) Type() string {
+	return "timeOfDay"
+}
+
+// Value implements the driver Valuer interface.
+func (t TimeOfDay) Value() (sqldriver.Value, error) {
+	return t.String(), nil
+}
+
+// Scan implements the Scanner interface. NULL is midnight.
+func (t *TimeOfDay) Scan(v interface{}) error {
+	switch typed := v.(type) {
+	case nil:
+		*t = TimeOfDay{}
+	case time.Time:
+		*t = TimeOfDayOf(typed)
+	case []byte:
+		return t.UnmarshalText(typed)
+	case string:
+		return t.UnmarshalText([]byte(typed))
+	default:
+		return fmt.Errorf("can not convert %v to time of day", v)
+	}
+
+	return nil
+}
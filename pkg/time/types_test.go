@@ -0,0 +1,193 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package time
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+type businessHours struct {
+	Holiday   Date      `json:"holiday"   yaml:"holiday"`
+	Opens     TimeOfDay `json:"opens"     yaml:"opens"`
+	Closes    TimeOfDay `json:"closes"    yaml:"closes"`
+	Retention Duration  `json:"retention" yaml:"retention"`
+}
+
+func TestTypesEncoding(t *testing.T) {
+	hours := businessHours{
+		Holiday:   Date{Year: 2020, Month: time.October, Day: 1},
+		Opens:     TimeOfDay{Hour: 9},
+		Closes:    TimeOfDay{Hour: 17, Minute: 30, Nanosecond: 500000000},
+		Retention: Duration{90 * time.Minute},
+	}
+
+	data, err := json.Marshal(hours)
+	expected := `{"holiday":"2020-10-01","opens":"09:00:00","closes":"17:30:00.5","retention":"1h30m0s"}`
+	if err != nil || string(data) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, data, err)
+	}
+	var decoded businessHours
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, hours) {
+		t.Errorf("expected %#v, got %#v (%v)", hours, decoded, err)
+	}
+
+	data, err = yaml.Marshal(hours)
+	expected = "holiday: \"2020-10-01\"\nopens: \"09:00:00\"\ncloses: \"17:30:00.5\"\nretention: 1h30m0s\n"
+	if err != nil || string(data) != expected {
+		t.Errorf("expected %q, got %q (%v)", expected, data, err)
+	}
+	decoded = businessHours{}
+	if err := yaml.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, hours) {
+		t.Errorf("expected %#v, got %#v (%v)", hours, decoded, err)
+	}
+
+	decoded = businessHours{}
+	data = []byte(`{"holiday":null,"opens":"09:00","retention":"PT1H30M"}`)
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Holiday.IsZero() ||
+		decoded.Opens != hours.Opens || decoded.Retention != hours.Retention {
+		t.Errorf("unexpected %#v (%v)", decoded, err)
+	}
+
+	decoded = businessHours{}
+	data = []byte(`{"holiday":"2020\u002d10-01","opens":"09\u003a00","closes":"17:30:00.5","retention":"1h30m0\u0073"}`)
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, hours) {
+		t.Errorf("expected the JSON escapes to be decoded, got %#v (%v)", decoded, err)
+	}
+	var date Date
+	if err := json.Unmarshal([]byte(`"2020\/10\/01"`), &date); err == nil || !strings.Contains(err.Error(), `"2020/10/01"`) {
+		t.Errorf("expected the unescaped date to be parsed, got %v", err)
+	}
+	if data, _ := json.Marshal(Date{}); string(data) != "null" {
+		t.Errorf("expected the zero date to be null, got %s", data)
+	}
+}
+
+func TestTypesFlags(t *testing.T) {
+	var (
+		date      Date
+		opens     TimeOfDay
+		retention = Duration{time.Hour}
+	)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Var(&date, "holiday", "A holiday.")
+	fs.Var(&opens, "opens", "The opening time.")
+	fs.Var(&retention, "retention", "The retention period.")
+
+	if def := fs.Lookup("retention").DefValue; def != "1h0m0s" {
+		t.Errorf("unexpected default %q", def)
+	}
+	if err := fs.Parse([]string{"--holiday=2020-10-01", "--opens=09:30", "--retention=P1D"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if date.String() != "2020-10-01" || opens.String() != "09:30:00" || retention.Duration != 24*time.Hour {
+		t.Errorf("unexpected values %v %v %v", date, opens, retention)
+	}
+	if err := fs.Parse([]string{"--holiday=2020-02-30"}); err == nil {
+		t.Errorf("expected an error for an invalid date")
+	}
+}
+
+func TestTypesSQL(t *testing.T) {
+	value := time.Date(2020, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	var date Date
+	for _, v := range []interface{}{value, "2020-10-01", []byte("2020-10-01 09:30:00")} {
+		if err := date.Scan(v); err != nil || date != DateOf(value) {
+			t.Errorf("%v: unexpected date %v (%v)", v, date, err)
+		}
+	}
+	if v, err := date.Value(); v != "2020-10-01" || err != nil {
+		t.Errorf("unexpected value %v (%v)", v, err)
+	}
+	if err := date.Scan("0000-00-00"); err != nil || !date.IsZero() {
+		t.Errorf("expected the zero date, got %v (%v)", date, err)
+	}
+	if v, err := date.Value(); v != nil || err != nil {
+		t.Errorf("expected the zero date to be NULL, got %v (%v)", v, err)
+	}
+
+	var opens TimeOfDay
+	for _, v := range []interface{}{value, "09:30:00", []byte("09:30")} {
+		if err := opens.Scan(v); err != nil || opens != TimeOfDayOf(value) {
+			t.Errorf("%v: unexpected time of day %v (%v)", v, opens, err)
+		}
+	}
+	if v, err := opens.Value(); v != "09:30:00" || err != nil {
+		t.Errorf("unexpected value %v (%v)", v, err)
+	}
+
+	var retention Duration
+	for _, v := range []interface{}{int64(90 * time.Minute), "5400000000000", []byte("1h30m"), "PT90M"} {
+		if err := retention.Scan(v); err != nil || retention.Duration != 90*time.Minute {
+			t.Errorf("%v: unexpected duration %v (%v)", v, retention, err)
+		}
+	}
+	if v, err := retention.Value(); v != int64(90*time.Minute) || err != nil {
+		t.Errorf("unexpected value %v (%v)", v, err)
+	}
+}
+
+func TestDate(t *testing.T) {
+	d := Date{Year: 2020, Month: time.February, Day: 28}
+	if next := d.AddDays(2); next != (Date{Year: 2020, Month: time.March, Day: 1}) || next.DaysSince(d) != 2 {
+		t.Errorf("unexpected date %v", next)
+	}
+	if !d.Before(d.AddDays(1)) || d.After(d) || !d.IsValid() || (Date{Year: 2020, Month: 2, Day: 30}).IsValid() {
+		t.Errorf("unexpected comparisons")
+	}
+
+	opens := TimeOfDay{Hour: 9, Minute: 30}
+	if at := opens.On(d, time.UTC); !at.Equal(time.Date(2020, 2, 28, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time %v", at)
+	}
+	if !opens.Before(TimeOfDay{Hour: 10}) || !opens.IsValid() || (TimeOfDay{Hour: 24}).IsValid() {
+		t.Errorf("unexpected comparisons")
+	}
+}
+
+func TestISO8601Duration(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"PT0S":         0,
+		"PT1H30M":      90 * time.Minute,
+		"P1DT12H":      36 * time.Hour,
+		"P2W":          14 * 24 * time.Hour,
+		"PT0.5S":       500 * time.Millisecond,
+		"PT1,5M":       90 * time.Second,
+		"-PT1M30.25S":  -(90*time.Second + 250*time.Millisecond),
+		"PT36H0M1.5S":  36*time.Hour + 1500*time.Millisecond,
+		"P0DT0H0M0.0S": 0,
+	}
+	for s, expected := range testCases {
+		d, err := ParseISO8601Duration(s)
+		if err != nil || d != expected {
+			t.Errorf("%s: expected %v, got %v (%v)", s, expected, d, err)
+		}
+	}
+
+	for _, s := range []string{"P", "PT", "P1DT", "P1Y", "P1M", "PT1H1H", "1h", "PT-1H"} {
+		if _, err := ParseISO8601Duration(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+
+	formats := map[time.Duration]string{
+		0:                                    "PT0S",
+		90 * time.Minute:                     "PT1H30M",
+		36*time.Hour + 1500*time.Millisecond: "PT36H1.5S",
+		-time.Second:                         "-PT1S",
+	}
+	for d, expected := range formats {
+		if got := (Duration{d}).ISO8601(); got != expected {
+			t.Errorf("%v: expected %s, got %s", d, expected, got)
+		}
+	}
+}
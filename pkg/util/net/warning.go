@@ -0,0 +1,147 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MiscellaneousPersistentWarning is the warn-code of the RFC 7234 Warning headers carrying
+// arbitrary warnings, e.g. the validation warnings, which must be presented to the user.
+const MiscellaneousPersistentWarning = 299
+
+// WarningHeader is an RFC 7234 Warning header, e.g. `299 - "spec.replicas: deprecated"`.
+type WarningHeader struct {
+	// Code is the warn-code, e.g. 299.
+	Code int
+	// Agent is the warn-agent, the host or the name of the server, or "-".
+	Agent string
+	// Text is the warn-text, unquoted.
+	Text string
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// NewWarningHeader returns the value of a Warning header. The agent defaults to "-", and
+// must not contain spaces. The text must be valid UTF-8, and its control and non-printable
+// characters are replaced with spaces.
+func NewWarningHeader(code int, agent, text string) (string, error) {
+	if code < 0 || code > 999 {
+		return "", errors.New("code must be between 0 and 999")
+	}
+	if len(agent) == 0 {
+		agent = "-"
+	} else if strings.ContainsAny(agent, " \t\r\n") {
+		return "", errors.New("agent must not contain spaces")
+	}
+	if !utf8.ValidString(text) {
+		return "", errors.New("text must be valid UTF-8")
+	}
+
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || !unicode.IsPrint(r) {
+			return ' '
+		}
+
+		return r
+	}, text)
+
+	return fmt.Sprintf(`%03d %s "%s"`, code, agent, quoteEscaper.Replace(text)), nil
+}
+
+// ParseWarningHeaders parses the values of the Warning headers, and returns the warnings
+// and the errors of the values which cannot be parsed. A value may contain several
+// warnings separated by commas.
+func ParseWarningHeaders(headers []string) ([]WarningHeader, []error) {
+	var warnings []WarningHeader
+	var errs []error
+	for _, header := range headers {
+		for len(header) > 0 {
+			warning, remainder, err := ParseWarningHeader(header)
+			if err != nil {
+				errs = append(errs, err)
+
+				break
+			}
+			warnings = append(warnings, warning)
+			header = remainder
+		}
+	}
+
+	return warnings, errs
+}
+
+// ParseWarningHeader parses the first warning of the value of a Warning header, e.g.
+// `299 - "deprecated"`, and returns the remainder of the value after the comma following
+// the warning. The optional warn-date is ignored.
+func ParseWarningHeader(header string) (WarningHeader, string, error) {
+	header = strings.TrimLeft(header, " ")
+
+	parts := strings.SplitN(header, " ", 3)
+	if len(parts) != 3 {
+		return WarningHeader{}, "", fmt.Errorf("invalid warning header %q: expected code, agent and text", header)
+	}
+	code, agent, rest := parts[0], parts[1], parts[2]
+	if len(code) != 3 {
+		return WarningHeader{}, "", fmt.Errorf("invalid warning header %q: code must be 3 digits", header)
+	}
+	c, err := strconv.Atoi(code)
+	if err != nil {
+		return WarningHeader{}, "", fmt.Errorf("invalid warning header %q: %v", header, err)
+	}
+
+	text, rest, err := quotedString(rest)
+	if err != nil {
+		return WarningHeader{}, "", fmt.Errorf("invalid warning header %q: %v", header, err)
+	}
+
+	// an optional quoted date, then a comma before the next warning
+	rest = strings.TrimLeft(rest, " ")
+	if strings.HasPrefix(rest, `"`) {
+		if _, rest, err = quotedString(rest); err != nil {
+			return WarningHeader{}, "", fmt.Errorf("invalid warning header %q: %v", header, err)
+		}
+		rest = strings.TrimLeft(rest, " ")
+	}
+	if len(rest) > 0 {
+		if rest[0] != ',' {
+			return WarningHeader{}, "", fmt.Errorf("invalid warning header %q: unexpected %q", header, rest)
+		}
+		rest = strings.TrimLeft(rest[1:], " ")
+	}
+
+	return WarningHeader{Code: c, Agent: agent, Text: text}, rest, nil
+}
+
+// quotedString returns the unquoted value of the quoted-string at the start of s, and the
+// remainder of s.
+func quotedString(s string) (string, string, error) {
+	if len(s) == 0 || s[0] != '"' {
+		return "", "", errors.New("expected a quoted string")
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", "", errors.New("unterminated quoted string")
+			}
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return "", "", errors.New("unterminated quoted string")
+}
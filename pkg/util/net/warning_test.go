@@ -0,0 +1,65 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewWarningHeader(t *testing.T) {
+	tests := []struct {
+		code    int
+		agent   string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{code: 299, text: "spec.replicas: deprecated", want: `299 - "spec.replicas: deprecated"`},
+		{code: 299, agent: "apiserver", text: `a "quoted" \ text`, want: `299 apiserver "a \"quoted\" \\ text"`},
+		{code: 299, text: "two\nlines", want: `299 - "two lines"`},
+		{code: 1000, text: "x", wantErr: true},
+		{code: 299, agent: "an agent", text: "x", wantErr: true},
+		{code: 299, text: "\xff", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := NewWarningHeader(test.code, test.agent, test.text)
+		if (err != nil) != test.wantErr {
+			t.Errorf("NewWarningHeader(%d, %q, %q) error = %v, wantErr %v", test.code, test.agent, test.text, err,
+				test.wantErr)
+
+			continue
+		}
+		if got != test.want {
+			t.Errorf("NewWarningHeader(%d, %q, %q) = %q, want %q", test.code, test.agent, test.text, got, test.want)
+		}
+	}
+}
+
+func TestParseWarningHeaders(t *testing.T) {
+	header, err := NewWarningHeader(299, "", `a "quoted" text`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warnings, errs := ParseWarningHeaders([]string{
+		header,
+		`299 - "first", 299 apiserver "second" "Sat, 25 Aug 2012 23:34:45 GMT"`,
+		`199 - "stale", invalid`,
+		`29 - "short code"`,
+	})
+	want := []WarningHeader{
+		{Code: 299, Agent: "-", Text: `a "quoted" text`},
+		{Code: 299, Agent: "-", Text: "first"},
+		{Code: 299, Agent: "apiserver", Text: "second"},
+		{Code: 199, Agent: "-", Text: "stale"},
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("ParseWarningHeaders() = %#v, want %#v", warnings, want)
+	}
+	if len(errs) != 2 {
+		t.Errorf("ParseWarningHeaders() errors = %v, want 2 errors", errs)
+	}
+}
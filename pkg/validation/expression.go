@@ -0,0 +1,1084 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Expression is a compiled rule expression. The expressions are a small subset of CEL,
+// evaluated against an object:
+//
+//   - literals: 1, 1.5, 'text', "text", true, false, null and lists like ['a', 'b'];
+//   - the fields of the object, by their json names: startTime, spec.replicas, or
+//     self.spec.replicas, items[0] and labels['app'];
+//   - the operators !, -, *, /, %, +, -, <, <=, >, >=, ==, !=, in, &&, || and ?:;
+//   - the functions registered with RegisterRuleFunction, called as f(x, y) or x.f(y),
+//     e.g. size(items), name.matches('^[a-z]+$') or isDNS1123Label(name);
+//   - has(spec.field), which is true if the field is set to a non-zero value, or if
+//     the map key exists.
+//
+// Numbers, strings, times and durations can be compared; times and durations can be added
+// and subtracted.
+type Expression struct {
+	source string
+	root   node
+}
+
+// CompileExpression parses an expression.
+func CompileExpression(source string) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %v", source, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %v", source, err)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against obj. Integers are returned as int64, floats as
+// float64, and the lists, maps and structs as reflect.Value.
+func (e *Expression) Eval(obj interface{}) (interface{}, error) {
+	return e.root.eval(normalize(reflect.ValueOf(obj)))
+}
+
+// EvalBool evaluates an expression which must return a bool.
+func (e *Expression) EvalBool(obj interface{}) (bool, error) {
+	v, err := e.Eval(obj)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a bool, got %s", typeName(v))
+	}
+
+	return b, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			isFloat := false
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.' || src[i] == 'e' || src[i] == 'E' ||
+				((src[i] == '+' || src[i] == '-') && (src[i-1] == 'e' || src[i-1] == 'E'))) {
+				if !isDigit(src[i]) {
+					isFloat = true
+				}
+				i++
+			}
+			text := src[start:i]
+			var (
+				value interface{}
+				err   error
+			)
+			if isFloat {
+				value, err = strconv.ParseFloat(text, 64)
+			} else {
+				value, err = strconv.ParseInt(text, 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, value: value, pos: start})
+		case c == '\'' || c == '"':
+			start := i
+			i++
+			for i < len(src) && src[i] != c {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			value, err := unquote(src[start+1:i-1], c)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", src[start:i])
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[start:i], value: value, pos: start})
+		case isLetter(c):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		default:
+			if i+1 < len(src) {
+				switch op := src[i : i+2]; op {
+				case "<=", ">=", "==", "!=", "&&", "||":
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += 2
+
+					continue
+				}
+			}
+			if !strings.ContainsRune("()[].,!-+*/%<>?:", rune(c)) {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: string(c), pos: i})
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// unquote returns the value of the body of a string literal delimited by quote. Both quotes
+// may be escaped in both kinds of literals, the other escapes are the ones of Go.
+func unquote(body string, quote byte) (string, error) {
+	var b strings.Builder
+	for len(body) > 0 {
+		if len(body) > 1 && body[0] == '\\' && (body[1] == '\'' || body[1] == '"') {
+			b.WriteByte(body[1])
+			body = body[2:]
+
+			continue
+		}
+		r, multibyte, tail, err := strconv.UnquoteChar(body, quote)
+		if err != nil {
+			return "", err
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			b.WriteByte(byte(r))
+		} else {
+			b.WriteRune(r)
+		}
+		body = tail
+	}
+
+	return b.String(), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// binaryPrecedences are the precedences of the binary operators, higher binding tighter.
+var binaryPrecedences = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3, "in": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); (t.kind == tokOp || t.kind == tokIdent) && t.text == op {
+		p.pos++
+
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return p.errorf("expected %q", op)
+	}
+
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at %d", fmt.Sprintf(format, args...), p.peek().pos)
+}
+
+func (p *parser) parseExpr() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &condNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) parseBinary(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		precedence, ok := binaryPrecedences[t.text]
+		if !ok || (t.kind != tokOp && t.text != "in") || precedence <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(precedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") || p.accept("-") {
+		op := p.tokens[p.pos-1].text
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryNode{op: op, x: x}, nil
+	}
+
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parsePostfix(x)
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	start := p.pos
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString:
+		return &literalNode{value: t.value}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.accept("(") {
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+
+			return newCallNode(t.text, args)
+		}
+
+		return &identNode{name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+
+			return x, p.expect(")")
+		case "[":
+			items, err := p.parseArgs("]")
+			if err != nil {
+				return nil, err
+			}
+
+			return &listNode{items: items}, nil
+		}
+	}
+	p.pos = start
+	if t.kind == tokEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+// parseArgs parses a comma-separated list of expressions, up to the closing token.
+func (p *parser) parseArgs(end string) ([]node, error) {
+	var args []node
+	if p.accept(end) {
+		return args, nil
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(end) {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parsePostfix(x node) (node, error) {
+	for {
+		switch {
+		case p.accept("."):
+			t := p.peek()
+			if t.kind != tokIdent {
+				return nil, p.errorf("expected a field name")
+			}
+			p.next()
+			if !p.accept("(") {
+				x = &memberNode{x: x, name: t.text}
+
+				continue
+			}
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+			if x, err = newCallNode(t.text, append([]node{x}, args...)); err != nil {
+				return nil, err
+			}
+		case p.accept("["):
+			index, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexNode{x: x, index: index}
+		default:
+			return x, nil
+		}
+	}
+}
+
+// node is a node of the syntax tree of an expression. Nodes are evaluated against the
+// normalized value of the object.
+type node interface {
+	eval(self interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(self interface{}) (interface{}, error) {
+	items := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(self)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+
+	return reflect.ValueOf(items), nil
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(self interface{}) (interface{}, error) {
+	if n.name == "self" {
+		return self, nil
+	}
+
+	return member(self, n.name)
+}
+
+type memberNode struct {
+	x    node
+	name string
+}
+
+func (n *memberNode) eval(self interface{}) (interface{}, error) {
+	x, err := n.x.eval(self)
+	if err != nil {
+		return nil, err
+	}
+
+	return member(x, n.name)
+}
+
+type indexNode struct {
+	x, index node
+}
+
+func (n *indexNode) eval(self interface{}) (interface{}, error) {
+	x, err := n.x.eval(self)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(self)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := x.(reflect.Value)
+	if !ok {
+		return nil, fmt.Errorf("cannot index %s", typeName(x))
+	}
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, ok := index.(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid list index %v", index)
+		}
+		if i < 0 || int(i) >= v.Len() {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+
+		return normalize(v.Index(int(i))), nil
+	case reflect.Map:
+		key, err := mapKey(v, index)
+		if err != nil {
+			return nil, err
+		}
+		value := v.MapIndex(key)
+		if !value.IsValid() {
+			return nil, fmt.Errorf("no such key: %v", index)
+		}
+
+		return normalize(value), nil
+	case reflect.Struct:
+		name, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid field name %v", index)
+		}
+
+		return member(x, name)
+	default:
+		return nil, fmt.Errorf("cannot index %s", typeName(x))
+	}
+}
+
+type callNode struct {
+	fn   string
+	args []node
+}
+
+// newCallNode returns the node of a function call. The literal patterns of the matches
+// function are compiled, and cached, with the expression.
+func newCallNode(fn string, args []node) (node, error) {
+	if fn == "matches" && len(args) == 2 {
+		if literal, ok := args[1].(*literalNode); ok {
+			if pattern, ok := literal.value.(string); ok {
+				if _, err := cachedRegexp(pattern); err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+				}
+			}
+		}
+	}
+
+	return &callNode{fn: fn, args: args}, nil
+}
+
+func (n *callNode) eval(self interface{}) (interface{}, error) {
+	if n.fn == "has" {
+		return n.has(self)
+	}
+
+	fn, ok := lookupRuleFunction(n.fn)
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", n.fn)
+	}
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(self)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	result, err := fn(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.fn, err)
+	}
+
+	return normalize(reflect.ValueOf(result)), nil
+}
+
+// has returns true if the field of its argument is set to a non-zero value, or if the
+// map key exists.
+func (n *callNode) has(self interface{}) (interface{}, error) {
+	if len(n.args) != 1 {
+		return nil, fmt.Errorf("has expects a single field")
+	}
+
+	var (
+		parent interface{} = self
+		name   interface{}
+		err    error
+	)
+	switch arg := n.args[0].(type) {
+	case *identNode:
+		name = arg.name
+	case *memberNode:
+		name = arg.name
+		parent, err = arg.x.eval(self)
+	case *indexNode:
+		parent, err = arg.x.eval(self)
+		if err == nil {
+			name, err = arg.index.eval(self)
+		}
+	default:
+		return nil, fmt.Errorf("has expects a field selection")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := parent.(reflect.Value)
+	if !ok {
+		return false, nil
+	}
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Map:
+		key, err := mapKey(v, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return v.MapIndex(key).IsValid(), nil
+	case reflect.Struct:
+		s, _ := name.(string)
+		f, ok := structField(v, s)
+		if !ok {
+			return nil, fmt.Errorf("no such field: %s", s)
+		}
+
+		return !f.IsZero(), nil
+	default:
+		return nil, fmt.Errorf("cannot test the fields of %s", typeName(parent))
+	}
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n *unaryNode) eval(self interface{}) (interface{}, error) {
+	x, err := n.x.eval(self)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := x.(type) {
+	case bool:
+		if n.op == "!" {
+			return !v, nil
+		}
+	case int64:
+		if n.op == "-" {
+			return -v, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid operation %s%s", n.op, typeName(x))
+}
+
+type condNode struct {
+	cond, then, otherwise node
+}
+
+func (n *condNode) eval(self interface{}) (interface{}, error) {
+	c, err := n.cond.eval(self)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := c.(bool)
+	if !ok {
+		return nil, fmt.Errorf("expected a bool condition, got %s", typeName(c))
+	}
+	if b {
+		return n.then.eval(self)
+	}
+
+	return n.otherwise.eval(self)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(self interface{}) (interface{}, error) {
+	left, err := n.left.eval(self)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operation %s %s", typeName(left), n.op)
+		}
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(self)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operation %s %s %s", typeName(left), n.op, typeName(right))
+		}
+
+		return r, nil
+	}
+
+	right, err := n.right.eval(self)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		c, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]bool{"<": c < 0, "<=": c <= 0, ">": c > 0, ">=": c >= 0}[n.op], nil
+	case "in":
+		return contains(right, left)
+	default:
+		return arithmetic(n.op, left, right)
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// normalize converts a value to the types the expressions work on: nil, bool, int64,
+// float64, string, time.Time, and reflect.Value for the lists, maps and structs, as well as
+// for the unsigned integers greater than math.MaxInt64, which then fail to be compared or
+// computed rather than wrapping around. The structs wrapping a single embedded time or
+// scalar, e.g. a pkg/time.Time, are unwrapped.
+func normalize(v reflect.Value) interface{} {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == reflect.TypeOf(reflect.Value{}) {
+		return normalize(v.Interface().(reflect.Value))
+	}
+
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+
+		// the unsigned integers which do not fit in an int64 cannot be evaluated
+		return v
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time)
+		}
+		if v.NumField() != 1 {
+			return v
+		}
+		if sf := v.Type().Field(0); sf.Anonymous && sf.IsExported() &&
+			(sf.Type == timeType || sf.Type.Kind() != reflect.Struct) {
+			return normalize(v.Field(0))
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// member returns a field of a struct, by its json name or its Go name, or the value of a
+// string key of a map.
+func member(x interface{}, name string) (interface{}, error) {
+	v, ok := x.(reflect.Value)
+	if !ok {
+		return nil, fmt.Errorf("no such field %s in %s", name, typeName(x))
+	}
+
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Struct:
+		f, ok := structField(v, name)
+		if !ok {
+			return nil, fmt.Errorf("no such field: %s", name)
+		}
+
+		return normalize(f), nil
+	case reflect.Map:
+		key, err := mapKey(v, name)
+		if err != nil {
+			return nil, err
+		}
+		value := v.MapIndex(key)
+		if !value.IsValid() {
+			return nil, fmt.Errorf("no such key: %s", name)
+		}
+
+		return normalize(value), nil
+	default:
+		return nil, fmt.Errorf("no such field %s in %s", name, typeName(x))
+	}
+}
+
+// structField returns the field of a struct by its json name, looking into the inlined
+// structs, or by its Go name.
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		jsonName, ok := jsonName(sf)
+		if ok && jsonName == name {
+			return v.Field(i), true
+		}
+		if !ok {
+			inner := v.Field(i)
+			for inner.Kind() == reflect.Ptr && !inner.IsNil() {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct {
+				if f, ok := structField(inner, name); ok {
+					return f, true
+				}
+			}
+		}
+	}
+
+	if sf, ok := t.FieldByName(name); ok && sf.IsExported() {
+		return v.FieldByIndex(sf.Index), true
+	}
+
+	return reflect.Value{}, false
+}
+
+func mapKey(m reflect.Value, key interface{}) (reflect.Value, error) {
+	k := reflect.ValueOf(key)
+	if !k.IsValid() || !k.Type().ConvertibleTo(m.Type().Key()) {
+		return reflect.Value{}, fmt.Errorf("invalid map key %v", key)
+	}
+
+	return k.Convert(m.Type().Key()), nil
+}
+
+func typeName(x interface{}) string {
+	switch v := x.(type) {
+	case nil:
+		return "null"
+	case reflect.Value:
+		//nolint: exhaustive
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			return "list"
+		case reflect.Map:
+			return "map"
+		default:
+			return v.Type().String()
+		}
+	default:
+		return reflect.TypeOf(x).String()
+	}
+}
+
+func equal(left, right interface{}) bool {
+	if c, err := compare(left, right); err == nil {
+		return c == 0
+	}
+
+	l, lok := left.(reflect.Value)
+	r, rok := right.(reflect.Value)
+	if lok && rok {
+		return reflect.DeepEqual(l.Interface(), r.Interface())
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+// compare returns -1, 0 or 1 if left is less than, equal to or greater than right.
+func compare(left, right interface{}) (int, error) {
+	switch l := left.(type) {
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			return compareInts(l, r), nil
+		case float64:
+			return compareFloats(float64(l), r), nil
+		}
+	case float64:
+		switch r := right.(type) {
+		case int64:
+			return compareFloats(l, float64(r)), nil
+		case float64:
+			return compareFloats(l, r), nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	case bool:
+		if r, ok := right.(bool); ok && l == r {
+			return 0, nil
+		} else if ok {
+			if l {
+				return 1, nil
+			}
+
+			return -1, nil
+		}
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			switch {
+			case l.Before(r):
+				return -1, nil
+			case l.After(r):
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("cannot compare %s and %s", typeName(left), typeName(right))
+}
+
+func compareInts(l, r int64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloats(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// contains returns true if x is an item of a list, a key of a map, or a substring of a
+// string.
+func contains(collection, x interface{}) (bool, error) {
+	if s, ok := collection.(string); ok {
+		sub, ok := x.(string)
+		if !ok {
+			return false, fmt.Errorf("invalid operation %s in string", typeName(x))
+		}
+
+		return strings.Contains(s, sub), nil
+	}
+
+	v, ok := collection.(reflect.Value)
+	if !ok {
+		return false, fmt.Errorf("invalid operation in %s", typeName(collection))
+	}
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if equal(normalize(v.Index(i)), x) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	case reflect.Map:
+		key, err := mapKey(v, x)
+		if err != nil {
+			return false, nil
+		}
+
+		return v.MapIndex(key).IsValid(), nil
+	default:
+		return false, fmt.Errorf("invalid operation in %s", typeName(collection))
+	}
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/", "%":
+				if r == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				if op == "/" {
+					return l / r, nil
+				}
+
+				return l % r, nil
+			}
+		case float64:
+			return arithmetic(op, float64(l), r)
+		case time.Time:
+			if op == "+" {
+				return r.Add(time.Duration(l)), nil
+			}
+		}
+	case float64:
+		var r float64
+		switch v := right.(type) {
+		case int64:
+			r = float64(v)
+		case float64:
+			r = v
+		default:
+			return nil, fmt.Errorf("invalid operation %s %s %s", typeName(left), op, typeName(right))
+		}
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			return l / r, nil
+		}
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, nil
+		}
+	case time.Time:
+		switch r := right.(type) {
+		case int64:
+			switch op {
+			case "+":
+				return l.Add(time.Duration(r)), nil
+			case "-":
+				return l.Add(-time.Duration(r)), nil
+			}
+		case time.Time:
+			if op == "-" {
+				return int64(l.Sub(r)), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("invalid operation %s %s %s", typeName(left), op, typeName(right))
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package field
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+// MaxBadValueLength is the maximum length of the bad values of the serialized errors.
+// The longer strings, and the values whose JSON encoding is longer, are truncated.
+const MaxBadValueLength = 256
+
+// errorWire is the serialized form of an Error, e.g.
+//
+//	{
+//	  "type": "FieldValueInvalid",
+//	  "field": "spec.containers[0].image",
+//	  "badValue": "nginx:",
+//	  "detail": "must be a valid image reference",
+//	  "origin": "image"
+//	}
+type errorWire struct {
+	Type     ErrorType   `json:"type"               yaml:"type"`
+	Field    string      `json:"field"              yaml:"field"`
+	BadValue interface{} `json:"badValue,omitempty" yaml:"badValue,omitempty"`
+	Detail   string      `json:"detail,omitempty"   yaml:"detail,omitempty"`
+	Origin   string      `json:"origin,omitempty"   yaml:"origin,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The bad value is encoded as JSON, or as its Go
+// syntax when it cannot be, and truncated to MaxBadValueLength.
+func (v *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.toWire())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The bad value is decoded like by
+// json.Unmarshal into an interface{}, e.g. the numbers as float64.
+func (v *Error) UnmarshalJSON(data []byte) error {
+	var w errorWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	return v.fromWire(w)
+}
+
+// MarshalYAML implements yaml.Marshaler, with the same fields as MarshalJSON.
+func (v *Error) MarshalYAML() (interface{}, error) {
+	return v.toWire(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (v *Error) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w errorWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+	return v.fromWire(w)
+}
+
+func (v *Error) toWire() errorWire {
+	return errorWire{
+		Type:     v.Type,
+		Field:    v.Field,
+		BadValue: wireValue(v.BadValue),
+		Detail:   v.Detail,
+		Origin:   v.Origin,
+	}
+}
+
+func (v *Error) fromWire(w errorWire) error {
+	if !w.Type.known() {
+		return fmt.Errorf("unrecognized validation error: %q", string(w.Type))
+	}
+	*v = Error{Type: w.Type, Field: w.Field, BadValue: w.BadValue, Detail: w.Detail, Origin: w.Origin}
+	return nil
+}
+
+// known returns true if t is one of the ErrorType constants.
+func (t ErrorType) known() bool {
+	switch t {
+	case ErrorTypeNotFound, ErrorTypeRequired, ErrorTypeDuplicate, ErrorTypeInvalid, ErrorTypeNotSupported,
+		ErrorTypeForbidden, ErrorTypeTooLong, ErrorTypeTooMany, ErrorTypeInternal:
+		return true
+	default:
+		return false
+	}
+}
+
+// wireValue returns the serialized form of a bad value: the scalars as is, and the other
+// values as the result of decoding their JSON encoding, so that they are serialized the same
+// way in JSON and YAML.
+func wireValue(value interface{}) interface{} {
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		value = rv.Elem().Interface()
+	}
+
+	switch t := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return t
+	case string:
+		return truncate(t)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return truncate(fmt.Sprintf("%#v", value))
+	}
+	if len(data) > MaxBadValueLength {
+		return truncate(string(data))
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return string(data)
+	}
+	return decoded
+}
+
+// truncate truncates s to MaxBadValueLength bytes, on a rune boundary, and appends the
+// number of bytes truncated.
+func truncate(s string) string {
+	if len(s) <= MaxBadValueLength {
+		return s
+	}
+	n := MaxBadValueLength
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...(%d more bytes)", s[:n], len(s)-n)
+}
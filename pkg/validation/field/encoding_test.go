@@ -0,0 +1,106 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package field
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+func TestErrorJSON(t *testing.T) {
+	port := 0
+	testCases := []struct {
+		err      *Error
+		expected string
+	}{
+		{
+			Invalid(NewPath("spec", "ports").Index(0), &port, "must be greater than 0").WithOrigin("min"),
+			`{"type":"FieldValueInvalid","field":"spec.ports[0]","badValue":0,"detail":"must be greater than 0","origin":"min"}`,
+		},
+		{
+			Required(NewPath("name"), ""),
+			`{"type":"FieldValueRequired","field":"name","badValue":""}`,
+		},
+		{
+			InternalError(NewPath("spec"), fmt.Errorf("boom")),
+			`{"type":"InternalError","field":"spec","detail":"boom"}`,
+		},
+		{
+			Duplicate(NewPath("labels"), map[string]int{"a": 1}),
+			`{"type":"FieldValueDuplicate","field":"labels","badValue":{"a":1}}`,
+		},
+	}
+	for _, tc := range testCases {
+		data, err := json.Marshal(tc.err)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.err, err)
+			continue
+		}
+		assert.JSONEq(t, tc.expected, string(data))
+	}
+
+	// the values which cannot be encoded as JSON are encoded with their Go syntax
+	data, err := json.Marshal(Invalid(NewPath("handler"), func() {}, ""))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"badValue":"(func())(0x`)
+
+	list := ErrorList{
+		Invalid(NewPath("name"), "Name", "must be lower case").WithOrigin("isDNS1123Label(name)"),
+		NotSupported(NewPath("policy"), "Sometimes", []string{"Always", "Never"}).WithOrigin("oneof"),
+	}
+	data, err = json.Marshal(list)
+	assert.NoError(t, err)
+	var decoded ErrorList
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, list, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"Unknown","field":"name"}`), &Error{}))
+}
+
+func TestErrorYAML(t *testing.T) {
+	list := ErrorList{
+		TooMany(NewPath("items"), 3, 2).WithOrigin("max"),
+		Forbidden(NewPath("spec", "hostNetwork"), "disallowed by policy"),
+	}
+	data, err := yaml.Marshal(list)
+	assert.NoError(t, err)
+	assert.Equal(t, `- type: FieldValueTooMany
+  field: items
+  badValue: 3
+  detail: must have at most 2 items
+  origin: max
+- type: FieldValueForbidden
+  field: spec.hostNetwork
+  badValue: ""
+  detail: disallowed by policy
+`, string(data))
+
+	var decoded ErrorList
+	assert.NoError(t, yaml.Unmarshal(data, &decoded))
+	assert.Equal(t, list, decoded)
+}
+
+func TestBadValueTruncation(t *testing.T) {
+	long := strings.Repeat("é", MaxBadValueLength)
+	data, err := json.Marshal(Invalid(NewPath("description"), long, ""))
+	assert.NoError(t, err)
+	var decoded Error
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	value, _ := decoded.BadValue.(string)
+	assert.Equal(t, strings.Repeat("é", MaxBadValueLength/2)+fmt.Sprintf("...(%d more bytes)", MaxBadValueLength), value)
+
+	data, err = json.Marshal(Invalid(NewPath("items"), make([]int, MaxBadValueLength), ""))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	value, _ = decoded.BadValue.(string)
+	assert.True(t, strings.HasPrefix(value, "[0,0,0,"), value)
+	assert.True(t, strings.HasSuffix(value, fmt.Sprintf("...(%d more bytes)", 2*MaxBadValueLength+1-MaxBadValueLength)), value)
+}
@@ -7,6 +7,7 @@ package field
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -22,10 +23,21 @@ type Error struct {
 	Field    string
 	BadValue interface{}
 	Detail   string
+
+	// Origin identifies the check which reported the error, e.g. the validate tag or the
+	// rule, for the clients to handle the errors without parsing their details. It is not
+	// part of the error message.
+	Origin string
 }
 
 var _ error = &Error{}
 
+// WithOrigin sets the origin of the error, and returns the error.
+func (v *Error) WithOrigin(origin string) *Error {
+	v.Origin = origin
+	return v
+}
+
 // Error implements the error interface.
 func (v *Error) Error() string {
 	return fmt.Sprintf("%s: %s", v.Field, v.ErrorBody())
@@ -144,26 +156,26 @@ func (t ErrorType) String() string {
 // NotFound returns a *Error indicating "value not found".  This is
 // used to report failure to find a requested value (e.g. looking up an ID).
 func NotFound(field *Path, value interface{}) *Error {
-	return &Error{ErrorTypeNotFound, field.String(), value, ""}
+	return &Error{Type: ErrorTypeNotFound, Field: field.String(), BadValue: value, Detail: ""}
 }
 
 // Required returns a *Error indicating "value required".  This is used
 // to report required values that are not provided (e.g. empty strings, null
 // values, or empty arrays).
 func Required(field *Path, detail string) *Error {
-	return &Error{ErrorTypeRequired, field.String(), "", detail}
+	return &Error{Type: ErrorTypeRequired, Field: field.String(), BadValue: "", Detail: detail}
 }
 
 // Duplicate returns a *Error indicating "duplicate value".  This is
 // used to report collisions of values that must be unique (e.g. names or IDs).
 func Duplicate(field *Path, value interface{}) *Error {
-	return &Error{ErrorTypeDuplicate, field.String(), value, ""}
+	return &Error{Type: ErrorTypeDuplicate, Field: field.String(), BadValue: value, Detail: ""}
 }
 
 // Invalid returns a *Error indicating "invalid value".  This is used
 // to report malformed values (e.g. failed regex match, too long, out of bounds).
 func Invalid(field *Path, value interface{}, detail string) *Error {
-	return &Error{ErrorTypeInvalid, field.String(), value, detail}
+	return &Error{Type: ErrorTypeInvalid, Field: field.String(), BadValue: value, Detail: detail}
 }
 
 // NotSupported returns a *Error indicating "unsupported value".
@@ -178,7 +190,7 @@ func NotSupported(field *Path, value interface{}, validValues []string) *Error {
 		}
 		detail = "supported values: " + strings.Join(quotedValues, ", ")
 	}
-	return &Error{ErrorTypeNotSupported, field.String(), value, detail}
+	return &Error{Type: ErrorTypeNotSupported, Field: field.String(), BadValue: value, Detail: detail}
 }
 
 // Forbidden returns a *Error indicating "forbidden".  This is used to
@@ -186,7 +198,7 @@ func NotSupported(field *Path, value interface{}, validValues []string) *Error {
 // some conditions, but which are not permitted by current conditions (e.g.
 // security policy).
 func Forbidden(field *Path, detail string) *Error {
-	return &Error{ErrorTypeForbidden, field.String(), "", detail}
+	return &Error{Type: ErrorTypeForbidden, Field: field.String(), BadValue: "", Detail: detail}
 }
 
 // TooLong returns a *Error indicating "too long".  This is used to
@@ -194,7 +206,7 @@ func Forbidden(field *Path, detail string) *Error {
 // Invalid, but the returned error will not include the too-long
 // value.
 func TooLong(field *Path, value interface{}, maxLength int) *Error {
-	return &Error{ErrorTypeTooLong, field.String(), value, fmt.Sprintf("must have at most %d bytes", maxLength)}
+	return &Error{Type: ErrorTypeTooLong, Field: field.String(), BadValue: value, Detail: fmt.Sprintf("must have at most %d bytes", maxLength)}
 }
 
 // TooMany returns a *Error indicating "too many". This is used to
@@ -202,10 +214,10 @@ func TooLong(field *Path, value interface{}, maxLength int) *Error {
 // but the returned error indicates quantity instead of length.
 func TooMany(field *Path, actualQuantity, maxQuantity int) *Error {
 	return &Error{
-		ErrorTypeTooMany,
-		field.String(),
-		actualQuantity,
-		fmt.Sprintf("must have at most %d items", maxQuantity),
+		Type:     ErrorTypeTooMany,
+		Field:    field.String(),
+		BadValue: actualQuantity,
+		Detail:   fmt.Sprintf("must have at most %d items", maxQuantity),
 	}
 }
 
@@ -213,7 +225,7 @@ func TooMany(field *Path, actualQuantity, maxQuantity int) *Error {
 // to signal that an error was found that was not directly related to user
 // input.  The err argument must be non-nil.
 func InternalError(field *Path, err error) *Error {
-	return &Error{ErrorTypeInternal, field.String(), nil, err.Error()}
+	return &Error{Type: ErrorTypeInternal, Field: field.String(), BadValue: nil, Detail: err.Error()}
 }
 
 // ErrorList holds a set of Errors.  It is plausible that we might one day have
@@ -247,13 +259,36 @@ func (list ErrorList) ToAggregate() utilerrors.Aggregate {
 	return utilerrors.NewAggregate(errs)
 }
 
-func fromAggregate(agg utilerrors.Aggregate) ErrorList {
-	errs := agg.Errors()
-	list := make(ErrorList, len(errs))
-	for i := range errs {
-		list[i] = errs[i].(*Error)
+// ToAggregateWithErrors converts the ErrorList into an errors.Aggregate, like ToAggregate
+// does, followed by the errors which are not related to a field. FromAggregate splits
+// them back.
+func (list ErrorList) ToAggregateWithErrors(errs ...error) utilerrors.Aggregate {
+	all := []error{}
+	if agg := list.ToAggregate(); agg != nil {
+		all = append(all, agg.Errors()...)
 	}
-	return list
+	return utilerrors.NewAggregate(append(all, errs...))
+}
+
+// FromAggregate converts an errors.Aggregate, e.g. made by ToAggregate, back into an
+// ErrorList. The nested aggregates are flattened, and the errors which are not *Error are
+// returned apart, in order.
+func FromAggregate(agg utilerrors.Aggregate) (ErrorList, []error) {
+	agg = utilerrors.Flatten(agg)
+	if agg == nil {
+		return nil, nil
+	}
+
+	var list ErrorList
+	var others []error
+	for _, err := range agg.Errors() {
+		if e, ok := err.(*Error); ok {
+			list = append(list, e)
+		} else {
+			others = append(others, err)
+		}
+	}
+	return list, others
 }
 
 // Filter removes items from the ErrorList that match the provided fns.
@@ -263,5 +298,94 @@ func (list ErrorList) Filter(fns ...utilerrors.Matcher) ErrorList {
 		return nil
 	}
 	// FilterOut takes an Aggregate and returns an Aggregate
-	return fromAggregate(err.(utilerrors.Aggregate))
+	list, _ = FromAggregate(err.(utilerrors.Aggregate))
+	return list
+}
+
+// Dedup returns the errors of the list without the duplicates of a previous error, which
+// have the same message and origin.
+func (list ErrorList) Dedup() ErrorList {
+	if list == nil {
+		return nil
+	}
+
+	type key struct {
+		msg    string
+		origin string
+	}
+	seen := map[key]bool{}
+	result := make(ErrorList, 0, len(list))
+	for _, err := range list {
+		k := key{err.Error(), err.Origin}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		result = append(result, err)
+	}
+	return result
+}
+
+// Sort sorts the errors by field, with the indexes in numeric order, e.g. items[2] before
+// items[10], then by type and detail. The order of the equal errors is kept.
+func (list ErrorList) Sort() {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Field != b.Field {
+			return naturalLess(a.Field, b.Field)
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Detail < b.Detail
+	})
+}
+
+// GroupByField returns the errors of the list by field, in order.
+func (list ErrorList) GroupByField() map[string]ErrorList {
+	groups := map[string]ErrorList{}
+	for _, err := range list {
+		groups[err.Field] = append(groups[err.Field], err)
+	}
+	return groups
+}
+
+// GroupByType returns the errors of the list by type, in order.
+func (list ErrorList) GroupByType() map[ErrorType]ErrorList {
+	groups := map[ErrorType]ErrorList{}
+	for _, err := range list {
+		groups[err.Type] = append(groups[err.Type], err)
+	}
+	return groups
+}
+
+// naturalLess compares two strings, the runs of digits by their numeric value.
+func naturalLess(a, b string) bool {
+	for len(a) > 0 && len(b) > 0 {
+		da, db := digitsPrefix(a), digitsPrefix(b)
+		if len(da) > 0 && len(db) > 0 {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func digitsPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
 }
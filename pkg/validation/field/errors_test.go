@@ -6,8 +6,11 @@ package field
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+
+	utilerrors "github.com/marmotedu/errors"
 )
 
 func TestMakeFuncs(t *testing.T) {
@@ -161,3 +164,55 @@ func TestNotSupported(t *testing.T) {
 		t.Errorf("Expected: %s\n, but got: %s\n", expected, notSupported.ErrorBody())
 	}
 }
+
+func TestErrListHelpers(t *testing.T) {
+	list := ErrorList{
+		Invalid(NewPath("items").Index(10), "x", "d"),
+		Required(NewPath("name"), ""),
+		Invalid(NewPath("items").Index(2), "y", "d"),
+		Invalid(NewPath("items").Index(10), "x", "d"),
+		Invalid(NewPath("items").Index(10), "x", "d").WithOrigin("rule"),
+		Duplicate(NewPath("items").Index(2), "y"),
+	}
+
+	deduped := list.Dedup()
+	if len(deduped) != 5 || deduped[3] != list[4] {
+		t.Errorf("unexpected deduplicated errors: %v", deduped)
+	}
+
+	deduped.Sort()
+	expected := ErrorList{list[5], list[2], list[0], list[4], list[1]}
+	if !reflect.DeepEqual(expected, deduped) {
+		t.Errorf("expected sorted errors %v, got %v", expected, deduped)
+	}
+
+	byField := list.GroupByField()
+	if len(byField) != 3 || len(byField["items[10]"]) != 3 || byField["name"][0] != list[1] {
+		t.Errorf("unexpected errors by field: %v", byField)
+	}
+	byType := list.GroupByType()
+	if len(byType) != 3 || len(byType[ErrorTypeInvalid]) != 4 || byType[ErrorTypeDuplicate][0] != list[5] {
+		t.Errorf("unexpected errors by type: %v", byType)
+	}
+}
+
+func TestFromAggregate(t *testing.T) {
+	list := ErrorList{Invalid(NewPath("f"), "v", "d"), Required(NewPath("g"), "")}
+	other := fmt.Errorf("not a field error")
+
+	agg := list.ToAggregateWithErrors(other)
+	if len(agg.Errors()) != 3 {
+		t.Fatalf("expected 3 errors, got %v", agg)
+	}
+	got, others := FromAggregate(utilerrors.NewAggregate([]error{agg, utilerrors.NewAggregate(nil)}))
+	if !reflect.DeepEqual(list, got) || len(others) != 1 || others[0] != other {
+		t.Errorf("expected %v and %v, got %v and %v", list, other, got, others)
+	}
+
+	if got, others := FromAggregate(nil); got != nil || others != nil {
+		t.Errorf("expected nothing, got %v and %v", got, others)
+	}
+	if agg := ErrorList(nil).ToAggregateWithErrors(); agg != nil {
+		t.Errorf("expected nil, got %v", agg)
+	}
+}
@@ -78,3 +78,50 @@ func (p *Path) String() string {
 	}
 	return buf.String()
 }
+
+// ParsePath parses the string representation of a Path, as produced by String, e.g.
+// "spec.containers[0].env[HOME]". The names containing a '.' or a '[' cannot be told
+// apart from several elements, and the subscripts containing "]." or "][" from several
+// subscripts. ParsePath("") returns a nil Path, whose string is "".
+func ParsePath(s string) (*Path, error) {
+	var p *Path
+	for i := 0; i < len(s); {
+		if s[i] == '[' {
+			end := subscriptEnd(s, i+1)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated subscript at %d", s, i)
+			}
+			p = &Path{index: s[i+1 : end], parent: p}
+			i = end + 1
+			continue
+		}
+
+		if p != nil {
+			if s[i] != '.' {
+				return nil, fmt.Errorf("invalid path %q: expected '.' or '[' at %d", s, i)
+			}
+			i++
+		}
+		end := i
+		for end < len(s) && s[end] != '.' && s[end] != '[' {
+			end++
+		}
+		if end == i {
+			return nil, fmt.Errorf("invalid path %q: empty field name at %d", s, i)
+		}
+		p = &Path{name: s[i:end], parent: p}
+		i = end
+	}
+	return p, nil
+}
+
+// subscriptEnd returns the index of the ']' closing a subscript starting at start, which
+// is followed by the end of s, a '.' or a '['. It returns -1 if there is none.
+func subscriptEnd(s string, start int) int {
+	for i := start; i < len(s); i++ {
+		if s[i] == ']' && (i+1 == len(s) || s[i+1] == '.' || s[i+1] == '[') {
+			return i
+		}
+	}
+	return -1
+}
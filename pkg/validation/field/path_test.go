@@ -4,7 +4,10 @@
 
 package field
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestPath(t *testing.T) {
 	testCases := []struct {
@@ -109,3 +112,37 @@ func TestPathMultiArg(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePath(t *testing.T) {
+	testCases := []*Path{
+		nil,
+		NewPath(""),
+		NewPath("").Index(0),
+		NewPath("").Child("name"),
+		NewPath("root"),
+		NewPath("spec", "containers").Index(0).Child("image"),
+		NewPath("metadata", "labels").Key("app.kubernetes.io/name"),
+		NewPath("data").Key("a[0]").Key(""),
+		NewPath("matrix").Index(1).Index(2),
+	}
+	for _, expected := range testCases {
+		s := expected.String()
+		p, err := ParsePath(s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", s, err)
+			continue
+		}
+		if !reflect.DeepEqual(expected, p) {
+			t.Errorf("%q: expected %#v, got %#v", s, expected, p)
+		}
+		if p.String() != s {
+			t.Errorf("%q: expected the same string, got %q", s, p.String())
+		}
+	}
+
+	for _, s := range []string{".name", "name.", "a..b", "a[0", "a[0]b", "[0]b", "a.[0]"} {
+		if _, err := ParsePath(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
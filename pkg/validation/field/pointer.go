@@ -0,0 +1,105 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package field
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// segment is an element of a Path: a field name, or a subscript (index or map key).
+type segment struct {
+	value string
+	index bool
+}
+
+// segments returns the elements of the path from its root. The empty root of NewPath(""),
+// which stands for the object itself, is skipped.
+func (p *Path) segments() []segment {
+	var result []segment
+	for ; p != nil; p = p.parent {
+		if p.parent == nil && len(p.name) == 0 && len(p.index) == 0 {
+			break
+		}
+		if len(p.name) > 0 {
+			result = append(result, segment{value: p.name})
+		} else {
+			result = append(result, segment{value: p.index, index: true})
+		}
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// JSONPointer returns the RFC 6901 JSON Pointer of the path, e.g. "/spec/containers/0/name"
+// for "spec.containers[0].name". The pointer of the root path NewPath("") is "".
+func (p *Path) JSONPointer() string {
+	var b strings.Builder
+	for _, s := range p.segments() {
+		b.WriteString("/")
+		b.WriteString(pointerEscaper.Replace(s.value))
+	}
+	return b.String()
+}
+
+var (
+	arrayIndexRegexp    = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+	invalidEscapeRegexp = regexp.MustCompile(`~([^01]|$)`)
+)
+
+// ParseJSONPointer parses an RFC 6901 JSON Pointer into a Path. A pointer does not tell
+// the array indexes from the object keys, and the map keys from the field names: the
+// tokens which are array indexes become indexes, the tokens which cannot be field names,
+// e.g. "app.kubernetes.io/name", become keys, and the others become field names. The
+// pointer "" is the root path NewPath("").
+func ParseJSONPointer(pointer string) (*Path, error) {
+	if len(pointer) == 0 {
+		return NewPath(""), nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with '/'", pointer)
+	}
+
+	var p *Path
+	for _, token := range strings.Split(pointer[1:], "/") {
+		if invalidEscapeRegexp.MatchString(token) {
+			return nil, fmt.Errorf("invalid JSON pointer %q: invalid escape in %q", pointer, token)
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch {
+		case arrayIndexRegexp.MatchString(token), len(token) == 0, strings.ContainsAny(token, ".[]"):
+			p = &Path{index: token, parent: p}
+		default:
+			p = &Path{name: token, parent: p}
+		}
+	}
+	return p, nil
+}
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// JSONPath returns the JSONPath expression of the path, e.g. "$.spec.containers[0].name",
+// with the keys which are not identifiers quoted, e.g. "$.metadata.labels['app/name']".
+func (p *Path) JSONPath() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range p.segments() {
+		switch {
+		case s.index && arrayIndexRegexp.MatchString(s.value):
+			fmt.Fprintf(&b, "[%s]", s.value)
+		case identifierRegexp.MatchString(s.value):
+			b.WriteString("." + s.value)
+		default:
+			fmt.Fprintf(&b, "['%s']", strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s.value))
+		}
+	}
+	return b.String()
+}
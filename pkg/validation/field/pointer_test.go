@@ -0,0 +1,55 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package field
+
+import (
+	"testing"
+)
+
+func TestJSONPointer(t *testing.T) {
+	testCases := []struct {
+		path     *Path
+		pointer  string
+		jsonPath string
+	}{
+		{NewPath(""), "", "$"},
+		{NewPath("").Index(0), "/0", "$[0]"},
+		{NewPath("spec", "containers").Index(0).Child("name"), "/spec/containers/0/name", "$.spec.containers[0].name"},
+		{
+			NewPath("metadata", "labels").Key("app.kubernetes.io/name"),
+			"/metadata/labels/app.kubernetes.io~1name",
+			"$.metadata.labels['app.kubernetes.io/name']",
+		},
+		{NewPath("data").Key("a~b").Key(""), "/data/a~0b/", "$.data['a~b']['']"},
+		{NewPath("data").Key("it's"), "/data/it's", `$.data['it\'s']`},
+	}
+	for _, tc := range testCases {
+		if pointer := tc.path.JSONPointer(); pointer != tc.pointer {
+			t.Errorf("%q: expected the pointer %q, got %q", tc.path, tc.pointer, pointer)
+		}
+		if jsonPath := tc.path.JSONPath(); jsonPath != tc.jsonPath {
+			t.Errorf("%q: expected the JSONPath %q, got %q", tc.path, tc.jsonPath, jsonPath)
+		}
+		p, err := ParseJSONPointer(tc.pointer)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.pointer, err)
+			continue
+		}
+		if p.JSONPointer() != tc.pointer {
+			t.Errorf("%q: expected the same pointer, got %q", tc.pointer, p.JSONPointer())
+		}
+	}
+
+	p, err := ParseJSONPointer("/metadata/labels/app.kubernetes.io~1name/0")
+	if err != nil || p.String() != "metadata.labels[app.kubernetes.io/name][0]" {
+		t.Errorf("unexpected path %q, error %v", p, err)
+	}
+
+	for _, pointer := range []string{"spec", "/spec~", "/spec~2"} {
+		if _, err := ParseJSONPointer(pointer); err == nil {
+			t.Errorf("%q: expected an error", pointer)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package field
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is wrapped by the errors returned when a path does not exist in a value.
+var ErrNotFound = errors.New("not found")
+
+// document is implemented by the values which can be converted into a tree of
+// map[string]interface{}, []interface{} and scalars, e.g. the jsonutils.JSONObject values.
+type document interface {
+	Interface() interface{}
+}
+
+// Resolve returns the value at the path in obj. The field names and the keys select the
+// struct fields by their json names, and the map entries by their keys. The indexes
+// select the slice and array elements, and the map entries. The pointers and the
+// interfaces are dereferenced, and the values implementing Interface() interface{}, e.g.
+// jsonutils.JSONObject, are resolved through its result. The error wraps ErrNotFound if
+// the path does not exist in obj.
+func Resolve(obj interface{}, p *Path) (interface{}, error) {
+	v := reflect.ValueOf(obj)
+	for i, s := range p.segments() {
+		v = indirect(v)
+		if !v.IsValid() {
+			return nil, fmt.Errorf("%s: %w", prefix(p, i+1), ErrNotFound)
+		}
+		if d, ok := asDocument(v); ok {
+			v = indirect(reflect.ValueOf(d.Interface()))
+		}
+
+		child, err := lookup(v, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", prefix(p, i+1), err)
+		}
+		v = child
+	}
+
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if d, ok := asDocument(v); ok {
+		return d.Interface(), nil
+	}
+	return v.Interface(), nil
+}
+
+// Set sets the value at the path in the value obj points to. The missing map entries,
+// nil maps and nil pointers are created, and an index equal to the length of a slice
+// appends to the slice. The value must be assignable to the type of the target, or be a
+// number converted to a numeric type, or nil for the zero value.
+func Set(obj interface{}, p *Path, value interface{}) error {
+	ptr := reflect.ValueOf(obj)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("cannot set %q in %T: not a pointer", p.String(), obj)
+	}
+
+	target := reflect.ValueOf(value)
+	result, err := modify(ptr.Elem(), p, p.segments(), 0, true, func(v reflect.Value) (reflect.Value, error) {
+		return convert(target, v.Type())
+	})
+	if err != nil {
+		return err
+	}
+	ptr.Elem().Set(result)
+	return nil
+}
+
+// Delete deletes the value at the path in the value obj points to: the map entries are
+// deleted, the slice elements are removed, and the struct fields and array elements are
+// set to their zero value.
+func Delete(obj interface{}, p *Path) error {
+	ptr := reflect.ValueOf(obj)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("cannot delete %q in %T: not a pointer", p.String(), obj)
+	}
+	segments := p.segments()
+	if len(segments) == 0 {
+		return fmt.Errorf("cannot delete the root of %T", obj)
+	}
+
+	last := segments[len(segments)-1]
+	result, err := modify(ptr.Elem(), p, segments[:len(segments)-1], 0, false, func(v reflect.Value) (reflect.Value, error) {
+		return remove(v, last)
+	})
+	if err != nil {
+		return err
+	}
+	ptr.Elem().Set(result)
+	return nil
+}
+
+// modify applies op to the value at segments in v, and returns v updated. v may not be
+// settable, e.g. a map entry, so the callers store the updated value themselves. The
+// missing values are created if create is true. depth is the number of elements of p
+// before segments, for the error messages.
+func modify(
+	v reflect.Value,
+	p *Path,
+	segments []segment,
+	depth int,
+	create bool,
+	op func(reflect.Value) (reflect.Value, error),
+) (reflect.Value, error) {
+	if len(segments) == 0 {
+		return op(v)
+	}
+	s, at := segments[0], prefix(p, depth+1)
+
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if !create {
+				return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+			}
+			v = reflect.New(v.Type().Elem())
+		}
+		elem, err := modify(v.Elem(), p, segments, depth, create, op)
+		if err != nil {
+			return v, err
+		}
+		v.Elem().Set(elem)
+		return v, nil
+	case reflect.Interface:
+		elem := v.Elem()
+		if !elem.IsValid() {
+			if !create || (s.index && arrayIndexRegexp.MatchString(s.value)) {
+				return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+			}
+			elem = reflect.ValueOf(map[string]interface{}{})
+		}
+		elem, err := modify(elem, p, segments, depth, create, op)
+		if err != nil {
+			return v, err
+		}
+		result := reflect.New(v.Type()).Elem()
+		result.Set(elem)
+		return result, nil
+	case reflect.Struct:
+		index, ok := structField(v.Type(), s.value)
+		if !ok {
+			return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+		}
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+		f := result
+		for _, i := range index {
+			if f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					if !create || !f.CanSet() {
+						return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+					}
+					f.Set(reflect.New(f.Type().Elem()))
+				}
+				f = f.Elem()
+			}
+			f = f.Field(i)
+		}
+		child, err := modify(f, p, segments[1:], depth+1, create, op)
+		if err != nil {
+			return v, err
+		}
+		f.Set(child)
+		return result, nil
+	case reflect.Map:
+		key, err := mapKey(v.Type(), s.value)
+		if err != nil {
+			return v, fmt.Errorf("%s: %v", at, err)
+		}
+		if v.IsNil() {
+			if !create {
+				return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+			}
+			v = reflect.MakeMap(v.Type())
+		}
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			if !create {
+				return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+			}
+			elem = reflect.Zero(v.Type().Elem())
+		}
+		child := reflect.New(v.Type().Elem()).Elem()
+		child.Set(elem)
+		if child, err = modify(child, p, segments[1:], depth+1, create, op); err != nil {
+			return v, err
+		}
+		v.SetMapIndex(key, child)
+		return v, nil
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(s.value)
+		if err != nil || i < 0 {
+			return v, fmt.Errorf("%s: invalid index %q", at, s.value)
+		}
+		result := v
+		switch {
+		case i < v.Len() && v.Kind() == reflect.Array:
+			result = reflect.New(v.Type()).Elem()
+			result.Set(v)
+		case i == v.Len() && v.Kind() == reflect.Slice && create:
+			result = reflect.Append(v, reflect.Zero(v.Type().Elem()))
+		case i >= v.Len():
+			return v, fmt.Errorf("%s: %w", at, ErrNotFound)
+		}
+		child, err := modify(result.Index(i), p, segments[1:], depth+1, create, op)
+		if err != nil {
+			return v, err
+		}
+		result.Index(i).Set(child)
+		return result, nil
+	default:
+		return v, fmt.Errorf("%s: cannot select %q in a %s", at, s.value, v.Type())
+	}
+}
+
+// lookup returns the child of v selected by a segment.
+func lookup(v reflect.Value, s segment) (reflect.Value, error) {
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Struct:
+		index, ok := structField(v.Type(), s.value)
+		if !ok {
+			return v, ErrNotFound
+		}
+		for _, i := range index {
+			if v = indirect(v); !v.IsValid() {
+				return v, ErrNotFound
+			}
+			v = v.Field(i)
+		}
+		return v, nil
+	case reflect.Map:
+		key, err := mapKey(v.Type(), s.value)
+		if err != nil {
+			return v, err
+		}
+		if v = v.MapIndex(key); !v.IsValid() {
+			return v, ErrNotFound
+		}
+		return v, nil
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(s.value)
+		if err != nil || i < 0 {
+			return v, fmt.Errorf("invalid index %q", s.value)
+		}
+		if i >= v.Len() {
+			return v, ErrNotFound
+		}
+		return v.Index(i), nil
+	default:
+		return v, fmt.Errorf("cannot select %q in a %s", s.value, v.Type())
+	}
+}
+
+// remove removes the child of v selected by a segment, and returns v updated.
+func remove(v reflect.Value, s segment) (reflect.Value, error) {
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v, ErrNotFound
+		}
+		elem, err := remove(v.Elem(), s)
+		if err != nil {
+			return v, err
+		}
+		if v.Kind() == reflect.Ptr {
+			v.Elem().Set(elem)
+			return v, nil
+		}
+		result := reflect.New(v.Type()).Elem()
+		result.Set(elem)
+		return result, nil
+	case reflect.Map:
+		key, err := mapKey(v.Type(), s.value)
+		if err != nil {
+			return v, err
+		}
+		if !v.MapIndex(key).IsValid() {
+			return v, ErrNotFound
+		}
+		v.SetMapIndex(key, reflect.Value{})
+		return v, nil
+	case reflect.Slice:
+		i, err := strconv.Atoi(s.value)
+		if err != nil || i < 0 || i >= v.Len() {
+			return v, ErrNotFound
+		}
+		result := reflect.MakeSlice(v.Type(), 0, v.Len()-1)
+		result = reflect.AppendSlice(result, v.Slice(0, i))
+		return reflect.AppendSlice(result, v.Slice(i+1, v.Len())), nil
+	default:
+		return modify(v, nil, []segment{s}, 0, false, func(child reflect.Value) (reflect.Value, error) {
+			return reflect.Zero(child.Type()), nil
+		})
+	}
+}
+
+// convert converts a value to be assigned to a target type.
+func convert(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case !v.IsValid():
+		return reflect.Zero(t), nil
+	case v.Type().AssignableTo(t):
+		return v, nil
+	case isNumber(v.Kind()) && isNumber(t.Kind()):
+		return v.Convert(t), nil
+	case t.Kind() == reflect.Ptr:
+		elem, err := convert(v, t.Elem())
+		if err != nil {
+			return v, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	default:
+		return v, fmt.Errorf("cannot assign a %s to a %s", v.Type(), t)
+	}
+}
+
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// structField returns the index sequence of the field of a struct type with the json name,
+// through the inline embedded structs. The names are matched case-insensitively if none
+// matches exactly, like encoding/json does.
+func structField(t reflect.Type, name string) ([]int, bool) {
+	var folded []int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if tag[0] == "-" && len(tag) == 1 {
+			continue
+		}
+
+		if sf.Anonymous && len(tag[0]) == 0 {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if index, ok := structField(ft, name); ok {
+					return append([]int{i}, index...), true
+				}
+				continue
+			}
+		}
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+
+		fieldName := tag[0]
+		if len(fieldName) == 0 {
+			fieldName = sf.Name
+		}
+		if fieldName == name {
+			return []int{i}, true
+		}
+		if folded == nil && strings.EqualFold(fieldName, name) {
+			folded = []int{i}
+		}
+	}
+	return folded, folded != nil
+}
+
+// mapKey converts a segment into a key of a map type, whose keys are strings or integers.
+func mapKey(t reflect.Type, s string) (reflect.Value, error) {
+	kt := t.Key()
+	//nolint: exhaustive
+	switch kt.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(s).Convert(kt), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, kt.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid key %q: %v", s, err)
+		}
+		return reflect.ValueOf(i).Convert(kt), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, kt.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid key %q: %v", s, err)
+		}
+		return reflect.ValueOf(u).Convert(kt), nil
+	case reflect.Interface:
+		return reflect.ValueOf(s), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported key type %s", kt)
+	}
+}
+
+// indirect dereferences the pointers and interfaces of v. It returns an invalid value for
+// nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		if _, ok := asDocument(v); ok {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// asDocument returns the document interface of v, if v is a pointer implementing it.
+func asDocument(v reflect.Value) (document, bool) {
+	if v.Kind() != reflect.Ptr || !v.CanInterface() {
+		return nil, false
+	}
+	d, ok := v.Interface().(document)
+	return d, ok
+}
+
+// prefix returns the string of the n first elements of the path, for the error messages.
+func prefix(p *Path, n int) string {
+	segments := p.segments()
+	if n > len(segments) {
+		n = len(segments)
+	}
+	var result *Path
+	for _, s := range segments[:n] {
+		if s.index {
+			result = &Path{index: s.value, parent: result}
+		} else {
+			result = &Path{name: s.value, parent: result}
+		}
+	}
+	return strconv.Quote(result.String())
+}
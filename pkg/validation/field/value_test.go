@@ -0,0 +1,152 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package field
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/component-base/pkg/json"
+)
+
+type testMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type testContainer struct {
+	Image string `json:"image"`
+	Ports []int  `json:"ports,omitempty"`
+}
+
+type testSpec struct {
+	testMeta   `json:",inline"`
+	Containers []testContainer           `json:"containers"`
+	Volumes    map[string]*testContainer `json:"volumes,omitempty"`
+	Replicas   *int32                    `json:"replicas,omitempty"`
+	Extra      interface{}               `json:"extra,omitempty"`
+	Hidden     string                    `json:"-"`
+}
+
+// testDocument is converted into a tree, like jsonutils.JSONObject.
+type testDocument struct {
+	tree interface{}
+}
+
+func (d *testDocument) Interface() interface{} {
+	return d.tree
+}
+
+func TestResolve(t *testing.T) {
+	replicas := int32(3)
+	spec := &testSpec{
+		testMeta:   testMeta{Name: "web", Labels: map[string]string{"app.kubernetes.io/name": "nginx"}},
+		Containers: []testContainer{{Image: "nginx", Ports: []int{80, 443}}},
+		Replicas:   &replicas,
+		Extra:      map[string]interface{}{"list": []interface{}{"a", map[string]interface{}{"b": true}}},
+	}
+
+	testCases := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"[]", *spec},
+		{"name", "web"},
+		{"metadata", nil},
+		{"labels[app.kubernetes.io/name]", "nginx"},
+		{"containers[0].ports[1]", 443},
+		{"containers[0].Image", "nginx"},
+		{"replicas", int32(3)},
+		{"extra.list[1].b", true},
+		{"volumes", map[string]*testContainer(nil)},
+		{"Hidden", nil},
+		{"containers[1]", nil},
+		{"containers[0].image.x", nil},
+	}
+	for _, tc := range testCases {
+		p, err := ParsePath(tc.path)
+		assert.NoError(t, err)
+		value, err := Resolve(spec, p)
+		switch tc.path {
+		case "metadata", "Hidden", "containers[1]":
+			assert.True(t, errors.Is(err, ErrNotFound), "%s: %v", tc.path, err)
+		case "containers[0].image.x":
+			assert.Error(t, err)
+			assert.False(t, errors.Is(err, ErrNotFound))
+		default:
+			assert.NoError(t, err, tc.path)
+			assert.Equal(t, tc.expected, value, tc.path)
+		}
+	}
+
+	var tree interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"spec":{"containers":[{"name":"nginx"}]}}`), &tree))
+	document := &testDocument{tree: tree}
+	value, err := Resolve(document, NewPath("spec", "containers").Index(0).Child("name"))
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx", value)
+	value, err = Resolve(map[string]interface{}{"doc": document}, NewPath("doc", "spec", "containers"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "nginx"}}, value)
+}
+
+func TestSetAndDelete(t *testing.T) {
+	spec := &testSpec{Containers: []testContainer{{Image: "nginx"}}}
+
+	set := func(path string, value interface{}) error {
+		p, err := ParsePath(path)
+		assert.NoError(t, err)
+
+		return Set(spec, p, value)
+	}
+	assert.NoError(t, set("name", "web"))
+	assert.NoError(t, set("labels[app]", "nginx"))
+	assert.NoError(t, set("containers[0].ports[0]", 80))
+	assert.NoError(t, set("containers[1].image", "redis"))
+	assert.NoError(t, set("volumes[data].ports[0]", 8080))
+	assert.NoError(t, set("replicas", 3))
+	assert.NoError(t, set("extra.nested.value", 1.5))
+
+	replicas := int32(3)
+	assert.Equal(t, &testSpec{
+		testMeta: testMeta{Name: "web", Labels: map[string]string{"app": "nginx"}},
+		Containers: []testContainer{
+			{Image: "nginx", Ports: []int{80}},
+			{Image: "redis"},
+		},
+		Volumes:  map[string]*testContainer{"data": {Ports: []int{8080}}},
+		Replicas: &replicas,
+		Extra:    map[string]interface{}{"nested": map[string]interface{}{"value": 1.5}},
+	}, spec)
+
+	assert.Error(t, set("containers[3].image", "x"))
+	assert.Error(t, set("name", 1))
+	assert.Error(t, set("unknown", "x"))
+	assert.Error(t, Set(*spec, NewPath("name"), "x"))
+
+	del := func(path string) error {
+		p, err := ParsePath(path)
+		assert.NoError(t, err)
+
+		return Delete(spec, p)
+	}
+	assert.NoError(t, del("containers[0]"))
+	assert.NoError(t, del("labels[app]"))
+	assert.NoError(t, del("volumes[data].ports[0]"))
+	assert.NoError(t, del("replicas"))
+	assert.NoError(t, del("extra.nested"))
+	assert.True(t, errors.Is(del("labels[app]"), ErrNotFound))
+	assert.True(t, errors.Is(del("containers[5]"), ErrNotFound))
+	assert.Error(t, del("[]"))
+
+	assert.Equal(t, &testSpec{
+		testMeta:   testMeta{Name: "web", Labels: map[string]string{}},
+		Containers: []testContainer{{Image: "redis"}},
+		Volumes:    map[string]*testContainer{"data": {Ports: []int{}}},
+		Extra:      map[string]interface{}{},
+	}, spec)
+}
@@ -0,0 +1,265 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/user"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	metatime "github.com/marmotedu/component-base/pkg/time"
+	"github.com/marmotedu/component-base/pkg/util/fileutil"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// DefaultCertificateExpiryWarning is the time before the expiry of a certificate validated
+// by the tls_cert tag from which a warning is reported.
+const DefaultCertificateExpiryWarning = 30 * 24 * time.Hour
+
+// the access modes of accessible
+const (
+	accessRead    = 0x4
+	accessWrite   = 0x2
+	accessExecute = 0x1
+)
+
+// accessTagFunc returns the function of a tag checking if a given string is a path the
+// process can access with mode.
+func accessTagFunc(mode uint32) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		path := fl.Field().String()
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+
+		return accessible(path, mode) == nil
+	}
+}
+
+// validateMaxMode checks if a given string is a path whose permissions are included in the
+// octal permissions of the tag parameter, e.g. `validate:"max_mode=0640"`. The permissions
+// are not checked on the platforms without unix permissions, e.g. windows.
+func validateMaxMode(fl validator.FieldLevel) bool {
+	max, err := strconv.ParseUint(fl.Param(), 8, 32)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	perm, ok := filePermissions(info)
+	if !ok {
+		return true
+	}
+
+	return uint64(perm)&^max == 0
+}
+
+// validateSecretFile checks if a given string is a regular file which cannot be accessed by
+// the group or the others, e.g. a private key. The permissions are not checked on the
+// platforms without unix permissions, e.g. windows.
+func validateSecretFile(fl validator.FieldLevel) bool {
+	info, err := os.Stat(fl.Field().String())
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	perm, ok := filePermissions(info)
+
+	return !ok || perm&0077 == 0
+}
+
+// validateOwner checks if a given string is a path owned by the user of the tag parameter,
+// a name or a uid, e.g. `validate:"owner=root"`. The owner is not checked on the platforms
+// without uids, e.g. windows.
+func validateOwner(fl validator.FieldLevel) bool {
+	info, err := os.Stat(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	uid, ok := fileOwner(info)
+	if !ok {
+		return true
+	}
+
+	want := fl.Param()
+	if _, err := strconv.ParseUint(want, 10, 32); err != nil {
+		u, err := user.Lookup(want)
+		if err != nil {
+			return false
+		}
+		want = u.Uid
+	}
+
+	return strconv.FormatUint(uint64(uid), 10) == want
+}
+
+// validateMaxSize checks if a given string is a regular file whose size is at most the size
+// of the tag parameter, in bytes or with a suffix, e.g. `validate:"max_size=1Mi"`.
+func validateMaxSize(fl validator.FieldLevel) bool {
+	max, err := parseSize(fl.Param())
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(fl.Field().String())
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().IsRegular() && info.Size() <= max
+}
+
+// validateFileType checks if a given string is a file whose content has one of the space
+// separated types of the tag parameter, extensions or MIME types, e.g.
+// `validate:"filetype=zip gz"` or `validate:"filetype=image/png"`.
+func validateFileType(fl validator.FieldLevel) bool {
+	path := fl.Field().String()
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	kind, err := fileutil.FileType(path)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range strings.Fields(fl.Param()) {
+		if t == kind.Extension || t == kind.MIME.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateTLSCert checks if a given string is a PEM file whose first certificate is valid
+// now. The tag parameter is the time before its expiry from which a warning is reported,
+// e.g. `validate:"tls_cert=168h"`, and defaults to DefaultCertificateExpiryWarning.
+func validateTLSCert(fl validator.FieldLevel) bool {
+	if _, err := certificateWarningWindow(fl.Param()); err != nil {
+		return false
+	}
+	cert, err := loadCertificate(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	now := time.Now()
+
+	return !now.Before(cert.NotBefore) && !now.After(cert.NotAfter)
+}
+
+// validateTLSKeyPair checks if a given string is a PEM certificate file matching the PEM
+// private key file of the field of the tag parameter, e.g. `validate:"tls_keypair=KeyFile"`.
+func validateTLSKeyPair(fl validator.FieldLevel) bool {
+	keyField, kind, _, ok := fl.GetStructFieldOK2()
+	if !ok || kind != reflect.String {
+		return false
+	}
+	_, err := tls.LoadX509KeyPair(fl.Field().String(), keyField.String())
+
+	return err == nil
+}
+
+// loadCertificate returns the first certificate of a PEM file.
+func loadCertificate(path string) (*x509.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// certificateWarningWindow parses the parameter of the tls_cert tag.
+func certificateWarningWindow(param string) (time.Duration, error) {
+	if len(param) == 0 {
+		return DefaultCertificateExpiryWarning, nil
+	}
+	d, err := metatime.ParseDuration(param)
+	if err != nil {
+		return 0, err
+	}
+
+	return d.Duration, nil
+}
+
+// warnCertificateExpiry adds a warning if a field tagged tls_cert is a certificate which
+// expires within the window of the tag. The invalid certificates are reported by the tag.
+func (r *Result) warnCertificateExpiry(sf reflect.StructField, v reflect.Value, fldPath *field.Path) {
+	param, ok := fieldTag(sf, "tls_cert")
+	v = indirectValue(v)
+	if !ok || !v.IsValid() || v.Kind() != reflect.String || len(v.String()) == 0 {
+		return
+	}
+	window, err := certificateWarningWindow(param)
+	if err != nil {
+		return
+	}
+	cert, err := loadCertificate(v.String())
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if now.After(cert.NotAfter) || now.Add(window).Before(cert.NotAfter) {
+		return
+	}
+	message := fmt.Sprintf("certificate expires in %s, at %s", cert.NotAfter.Sub(now).Round(time.Hour),
+		cert.NotAfter.UTC().Format(time.RFC3339))
+	r.Warnings = append(r.Warnings, Warning{Field: fldPath.String(), Message: message, Origin: "tls_cert"})
+}
+
+// sizeSuffixes are the multipliers of the suffixes of parseSize.
+var sizeSuffixes = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// parseSize parses a size in bytes, with an optional binary or decimal suffix, e.g. 512,
+// 64Ki or 10M.
+func parseSize(size string) (int64, error) {
+	s, multiplier := size, int64(1)
+	for _, suffix := range sizeSuffixes {
+		if strings.HasSuffix(s, suffix.suffix) {
+			s, multiplier = strings.TrimSuffix(s, suffix.suffix), suffix.multiplier
+
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("size must not be negative: %d", n)
+	}
+	if n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("size is too large: %s", size)
+	}
+
+	return n * multiplier, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// writeKeyPair writes a self-signed certificate valid from notBefore to notAfter, and its
+// private key, to dir.
+func writeKeyPair(t *testing.T, dir, name string, notBefore, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		0600))
+
+	return certFile, keyFile
+}
+
+func TestFileTags(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the permissions and the owners are not checked on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "validation")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "run.sh")
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\n"), 0755))
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, ioutil.WriteFile(config, []byte("name: test\n"), 0644))
+	image := filepath.Join(dir, "logo.png")
+	require.NoError(t, ioutil.WriteFile(image, append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...), 0644))
+	_, key := writeKeyPair(t, dir, "server", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+	uid := strconv.Itoa(os.Getuid())
+
+	type files struct {
+		Data   string `json:"data"   validate:"dir,readable,writable"`
+		Script string `json:"script" validate:"file,executable,max_mode=0755"`
+		Config string `json:"config" validate:"file,readable,max_size=1Ki"`
+		Key    string `json:"key"    validate:"secret_file"`
+		Logo   string `json:"logo"   validate:"filetype=jpg png"`
+	}
+
+	valid := &files{Data: dir, Script: script, Config: config, Key: key, Logo: image}
+	v := NewValidator(valid)
+	assert.Empty(t, v.Validate())
+
+	type owned struct {
+		Path string `json:"path" validate:"owner=0"`
+	}
+	if uid == "0" {
+		assert.Empty(t, NewValidator(&owned{Path: config}).Validate())
+	} else {
+		assert.Equal(t, field.ErrorList{
+			field.Invalid(field.NewPath("path"), config, "path is '"+config+"', but must be owned by 0").
+				WithOrigin("owner"),
+		}, NewValidator(&owned{Path: config}).Validate())
+	}
+
+	invalid := &files{
+		Data:   filepath.Join(dir, "missing"),
+		Script: config,
+		Config: image,
+		Key:    config,
+		Logo:   config,
+	}
+	expected := field.ErrorList{
+		field.Invalid(field.NewPath("data"), invalid.Data,
+			"data must point to an existing directory, but found '"+invalid.Data+"'").WithOrigin("dir"),
+		field.Invalid(field.NewPath("script"), config,
+			"script must point to an executable path, but found '"+config+"'").WithOrigin("executable"),
+		field.Invalid(field.NewPath("key"), config,
+			"key must point to a file which is not accessible by the group or the others, but found '"+config+"'").
+			WithOrigin("secret_file"),
+		field.Invalid(field.NewPath("logo"), config,
+			"logo is '"+config+"', but must be a file of type jpg png").WithOrigin("filetype"),
+	}
+	assert.Equal(t, expected, NewValidator(invalid).Validate())
+
+	type limited struct {
+		Script string `json:"script" validate:"max_mode=0700"`
+		Config string `json:"config" validate:"max_size=8"`
+	}
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("script"), script,
+			"script is '"+script+"', but must have at most the permissions 0700").WithOrigin("max_mode"),
+		field.Invalid(field.NewPath("config"), config,
+			"config is '"+config+"', but must be a file of at most 8 bytes").WithOrigin("max_size"),
+	}, NewValidator(&limited{Script: script, Config: config}).Validate())
+}
+
+func TestTLSTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validation")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	cert, key := writeKeyPair(t, dir, "server", now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	expiring, expiringKey := writeKeyPair(t, dir, "expiring", now.Add(-time.Hour), now.Add(10*24*time.Hour))
+	expired, _ := writeKeyPair(t, dir, "expired", now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	type tlsConfig struct {
+		CertFile string `json:"certFile" validate:"tls_cert,tls_keypair=KeyFile"`
+		KeyFile  string `json:"keyFile"  validate:"secret_file"`
+		CAFile   string `json:"caFile"   validate:"omitempty,tls_cert=8760h"`
+	}
+
+	result := NewValidator(&tlsConfig{CertFile: cert, KeyFile: key}).ValidateWithWarnings()
+	assert.True(t, result.Valid())
+	assert.Empty(t, result.Warnings)
+
+	// the certificates expiring soon are reported as warnings
+	result = NewValidator(&tlsConfig{CertFile: expiring, KeyFile: expiringKey, CAFile: cert}).ValidateWithWarnings()
+	assert.True(t, result.Valid())
+	if assert.Len(t, result.Warnings, 2) {
+		assert.Equal(t, "certFile", result.Warnings[0].Field)
+		assert.Contains(t, result.Warnings[0].Message, "certificate expires in 240h0m0s")
+		assert.Equal(t, "caFile", result.Warnings[1].Field)
+		assert.Equal(t, "tls_cert", result.Warnings[1].Origin)
+	}
+
+	// the expired certificates, and the certificates not matching their keys, are errors
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("certFile"), expired,
+			"certFile must point to a valid PEM certificate, but found '"+expired+"'").WithOrigin("tls_cert"),
+	}, NewValidator(&tlsConfig{CertFile: expired, KeyFile: key}).Validate())
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("certFile"), expiring,
+			"certFile is '"+expiring+"', but must be a PEM certificate matching the private key of KeyFile").
+			WithOrigin("tls_keypair"),
+	}, NewValidator(&tlsConfig{CertFile: expiring, KeyFile: key}).Validate())
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("caFile"), key,
+			"caFile must point to a valid PEM certificate, but found '"+key+"'").WithOrigin("tls_cert"),
+	}, NewValidator(&tlsConfig{CertFile: cert, KeyFile: key, CAFile: key}).Validate())
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"0": 0, "512": 512, "64Ki": 64 << 10, "10M": 10e6, "1Gi": 1 << 30,
+		"8388607Ti": 8388607 << 40}
+	for s, want := range tests {
+		got, err := parseSize(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, want, got, s)
+		}
+	}
+	for _, s := range []string{"", "Mi", "-1", "1.5G", "1MB", "9999999Ti", "8388608Ti", "9223372036854775808"} {
+		_, err := parseSize(s)
+		assert.Error(t, err, s)
+	}
+}
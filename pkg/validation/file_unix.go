@@ -0,0 +1,34 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package validation
+
+import (
+	"os"
+	"syscall"
+)
+
+// accessible returns nil if the process can access path with mode, a combination of
+// accessRead, accessWrite and accessExecute.
+func accessible(path string, mode uint32) error {
+	return syscall.Access(path, mode)
+}
+
+// filePermissions returns the unix permissions of a file.
+func filePermissions(info os.FileInfo) (os.FileMode, bool) {
+	return info.Mode().Perm(), true
+}
+
+// fileOwner returns the uid of the owner of a file.
+func fileOwner(info os.FileInfo) (uint32, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+
+	return stat.Uid, true
+}
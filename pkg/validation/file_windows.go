@@ -0,0 +1,38 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package validation
+
+import (
+	"errors"
+	"os"
+)
+
+// accessible returns nil if the process can access path with mode, a combination of
+// accessRead, accessWrite and accessExecute. Only the read-only attribute is checked.
+func accessible(path string, mode uint32) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if mode&accessWrite != 0 && info.Mode().Perm()&0200 == 0 {
+		return errors.New("permission denied")
+	}
+
+	return nil
+}
+
+// filePermissions returns false: the permissions reported by os.Stat are synthesized
+// from the read-only attribute, e.g. 0666 or 0444, and do not reflect the ACLs.
+func filePermissions(info os.FileInfo) (os.FileMode, bool) {
+	return 0, false
+}
+
+// fileOwner returns false: the files have no uid.
+func fileOwner(info os.FileInfo) (uint32, bool) {
+	return 0, false
+}
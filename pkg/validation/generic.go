@@ -7,10 +7,14 @@ package validation
 import (
 	"fmt"
 	"net"
+	"net/mail"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
+	metatime "github.com/marmotedu/component-base/pkg/time"
 	"github.com/marmotedu/component-base/pkg/validation/field"
 )
 
@@ -200,6 +204,240 @@ func IsValidPercent(percent string) []string {
 	return nil
 }
 
+// IsValidCIDR tests that the argument is a valid CIDR value, e.g. 10.0.0.0/8 or
+// 2001:db8::/32.
+func IsValidCIDR(fldPath *field.Path, value string) field.ErrorList {
+	var allErrors field.ErrorList
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		allErrors = append(allErrors, field.Invalid(fldPath, value, "must be a valid CIDR value, (e.g. 10.9.8.0/24 or 2001:db8::/64)"))
+	}
+	return allErrors
+}
+
+// IsValidIPv4CIDR tests that the argument is a valid IPv4 CIDR value.
+func IsValidIPv4CIDR(fldPath *field.Path, value string) field.ErrorList {
+	var allErrors field.ErrorList
+	ip, _, err := net.ParseCIDR(value)
+	if err != nil || ip.To4() == nil {
+		allErrors = append(allErrors, field.Invalid(fldPath, value, "must be a valid IPv4 CIDR value, (e.g. 10.9.8.0/24)"))
+	}
+	return allErrors
+}
+
+// IsValidIPv6CIDR tests that the argument is a valid IPv6 CIDR value.
+func IsValidIPv6CIDR(fldPath *field.Path, value string) field.ErrorList {
+	var allErrors field.ErrorList
+	ip, _, err := net.ParseCIDR(value)
+	if err != nil || ip.To4() != nil {
+		allErrors = append(allErrors, field.Invalid(fldPath, value, "must be a valid IPv6 CIDR value, (e.g. 2001:db8::/64)"))
+	}
+	return allErrors
+}
+
+// IsValidCIDRPrefix tests that the argument is a valid CIDR value whose prefix length is
+// in an inclusive range, e.g. between 16 and 24 for the subnets of a network.
+func IsValidCIDRPrefix(fldPath *field.Path, value string, min int, max int) field.ErrorList {
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		return IsValidCIDR(fldPath, value)
+	}
+	var allErrors field.ErrorList
+	if ones, _ := ipNet.Mask.Size(); ones < min || ones > max {
+		allErrors = append(allErrors, field.Invalid(fldPath, value, "prefix length "+InclusiveRangeError(min, max)))
+	}
+	return allErrors
+}
+
+// IsValidPortRange tests that the argument is a single port number or a range of port
+// numbers, e.g. 8080 or 8000-9000.
+func IsValidPortRange(value string) []string {
+	parts := strings.Split(value, "-")
+	// nolint:gomnd // no need
+	if len(parts) > 2 {
+		return []string{"must be a port number or a range of port numbers, (e.g. 8080 or 8000-9000)"}
+	}
+
+	var errs []string
+	ports := make([]int, 0, len(parts))
+	for _, part := range parts {
+		port, err := strconv.Atoi(part)
+		if err != nil {
+			return []string{"must be a port number or a range of port numbers, (e.g. 8080 or 8000-9000)"}
+		}
+		if msgs := IsValidPortNum(port); len(msgs) != 0 {
+			errs = append(errs, prefixEach(msgs, fmt.Sprintf("port %d ", port))...)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 2 && ports[0] > ports[1] {
+		errs = append(errs, "the first port of a range must not be greater than the last one")
+	}
+	return errs
+}
+
+// IsValidHostPort tests that the argument is a host and a port separated by a colon,
+// e.g. example.com:443, 10.0.0.1:80 or [::1]:8080. The host must be an IP address or a
+// DNS-1123 subdomain.
+func IsValidHostPort(value string) []string {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return []string{"must be a host and a port, (e.g. 'example.com:443' or '[::1]:8080')"}
+	}
+
+	var errs []string
+	if len(host) == 0 {
+		errs = append(errs, "host part "+EmptyError())
+	} else if net.ParseIP(host) == nil {
+		errs = append(errs, prefixEach(IsDNS1123Subdomain(host), "host part ")...)
+	}
+	if p, err := strconv.Atoi(port); err != nil {
+		errs = append(errs, "port part must be a number")
+	} else {
+		errs = append(errs, prefixEach(IsValidPortNum(p), "port part ")...)
+	}
+	return errs
+}
+
+// IsValidURL tests that the argument is an absolute URL with a host. If schemes are
+// given, the scheme of the URL must be one of them, e.g. http and https.
+func IsValidURL(value string, schemes ...string) []string {
+	u, err := url.Parse(value)
+	if err != nil || !u.IsAbs() || len(u.Host) == 0 {
+		return []string{"must be an absolute URL, (e.g. 'https://example.com/path')"}
+	}
+	if len(schemes) == 0 {
+		return nil
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("scheme must be one of '%s'", strings.Join(schemes, "', '"))}
+}
+
+// IsValidEmail tests that the argument is a bare email address, e.g. colin@example.com,
+// without display name.
+func IsValidEmail(value string) []string {
+	if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+		return []string{"must be a valid email address, (e.g. 'user@example.com')"}
+	}
+	return nil
+}
+
+const (
+	e164Fmt    string = `\+[1-9][0-9]{1,14}`
+	e164ErrMsg string = "a valid E.164 phone number must consist of a '+' and at most 15 digits, the first one non-zero"
+)
+
+var e164Regexp = regexp.MustCompile("^" + e164Fmt + "$")
+
+// IsValidE164 tests that the argument is a phone number in the E.164 format.
+func IsValidE164(value string) []string {
+	if !e164Regexp.MatchString(value) {
+		return []string{RegexError(e164ErrMsg, e164Fmt, "+8613800138000", "+14155552671")}
+	}
+	return nil
+}
+
+const (
+	semverFmt string = `(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+		`(-((0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*)(\.(0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(\+([0-9a-zA-Z-]+(\.[0-9a-zA-Z-]+)*))?`
+	semverErrMsg string = "a valid semantic version must consist of a major, a minor and a patch version, and an optional pre-release and build metadata"
+)
+
+var semverRegexp = regexp.MustCompile("^" + semverFmt + "$")
+
+// IsValidSemver tests that the argument is a semantic version, as defined by
+// https://semver.org, e.g. 1.2.3 or 1.0.0-rc.1+build.5. A leading 'v' is allowed.
+func IsValidSemver(value string) []string {
+	if !semverRegexp.MatchString(strings.TrimPrefix(value, "v")) {
+		return []string{RegexError(semverErrMsg, semverFmt, "1.2.3", "v1.0.0-rc.1")}
+	}
+	return nil
+}
+
+// IsValidCron tests that the argument is a cron schedule, as parsed by
+// time.ParseSchedule, e.g. "0 */5 * * * *", "*/10 * * * *" or "@daily".
+func IsValidCron(value string) []string {
+	if _, err := metatime.ParseSchedule(value); err != nil {
+		return []string{"must be a valid cron schedule: " + err.Error()}
+	}
+	return nil
+}
+
+// IsValidDuration tests that the argument is a duration, in the Go format, e.g. 1h30m, or
+// in the ISO 8601 format, e.g. PT1H30M.
+func IsValidDuration(value string) []string {
+	if _, err := metatime.ParseDuration(value); err != nil {
+		return []string{"must be a valid duration, (e.g. '1h30m' or 'PT1H30M')"}
+	}
+	return nil
+}
+
+const (
+	uuidFmt    string = "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}"
+	uuidErrMsg string = "a valid UUID must consist of 32 hexadecimal digits in 5 groups separated by '-'"
+)
+
+var uuidRegexp = regexp.MustCompile("^" + uuidFmt + "$")
+
+// IsValidUUID tests that the argument is a UUID in its canonical format.
+func IsValidUUID(value string) []string {
+	if !uuidRegexp.MatchString(value) {
+		return []string{RegexError(uuidErrMsg, uuidFmt, "123e4567-e89b-12d3-a456-426614174000")}
+	}
+	return nil
+}
+
+const (
+	ulidFmt    string = "[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}"
+	ulidErrMsg string = "a valid ULID must consist of 26 Crockford's base32 characters, the first one between '0' and '7'"
+)
+
+var ulidRegexp = regexp.MustCompile("^" + ulidFmt + "$")
+
+// IsValidULID tests that the argument is a ULID, see https://github.com/ulid/spec.
+func IsValidULID(value string) []string {
+	if !ulidRegexp.MatchString(value) {
+		return []string{RegexError(ulidErrMsg, ulidFmt, "01ARZ3NDEKTSV4RRFFQ69G5FAV")}
+	}
+	return nil
+}
+
+const (
+	instanceIDPrefixFmt   string = "[a-z][a-z0-9]*-"
+	instanceIDFmt         string = "[a-z0-9]{6,}"
+	prefixedInstanceIDFmt string = "(" + instanceIDPrefixFmt + ")?" + instanceIDFmt
+	instanceIDErrMsg      string = "a valid instance ID must consist of a prefix and at least 6 lower case alphanumeric characters"
+)
+
+var (
+	instanceIDRegexp         = regexp.MustCompile("^" + instanceIDFmt + "$")
+	prefixedInstanceIDRegexp = regexp.MustCompile("^" + prefixedInstanceIDFmt + "$")
+)
+
+// IsValidInstanceID tests that the argument is an instance ID, as generated by
+// idutil.GetInstanceID, e.g. secret-2v69o5. If prefix is empty, any lower case prefix ending
+// with '-' is allowed.
+func IsValidInstanceID(value string, prefix string) []string {
+	if len(prefix) == 0 {
+		if !prefixedInstanceIDRegexp.MatchString(value) {
+			return []string{RegexError(instanceIDErrMsg, prefixedInstanceIDFmt, "secret-2v69o5", "policy-lx1l6p")}
+		}
+		return nil
+	}
+
+	if !strings.HasPrefix(value, prefix) {
+		return []string{fmt.Sprintf("must start with '%s'", prefix)}
+	}
+	if !instanceIDRegexp.MatchString(strings.TrimPrefix(value, prefix)) {
+		return []string{RegexError(instanceIDErrMsg, prefix+instanceIDFmt, prefix+"2v69o5")}
+	}
+	return nil
+}
+
 // MaxLenError returns a string explanation of a "string too long" validation
 // failure.
 func MaxLenError(length int) string {
@@ -298,3 +298,151 @@ func TestIsValidPercent(t *testing.T) {
 		}
 	}
 }
+
+func TestIsValidCIDR(t *testing.T) {
+	testCases := []struct {
+		value string
+		any   bool
+		ipv4  bool
+		ipv6  bool
+	}{
+		{"10.0.0.0/8", true, true, false},
+		{"192.168.1.1/24", true, true, false},
+		{"0.0.0.0/0", true, true, false},
+		{"2001:db8::/32", true, false, true},
+		{"::/0", true, false, true},
+		{"10.0.0.0", false, false, false},
+		{"10.0.0.0/33", false, false, false},
+		{"2001:db8::/129", false, false, false},
+		{"a/8", false, false, false},
+		{"", false, false, false},
+	}
+	for _, tc := range testCases {
+		if got := len(IsValidCIDR(field.NewPath(""), tc.value)) == 0; got != tc.any {
+			t.Errorf("IsValidCIDR(%q): expected %v, got %v", tc.value, tc.any, got)
+		}
+		if got := len(IsValidIPv4CIDR(field.NewPath(""), tc.value)) == 0; got != tc.ipv4 {
+			t.Errorf("IsValidIPv4CIDR(%q): expected %v, got %v", tc.value, tc.ipv4, got)
+		}
+		if got := len(IsValidIPv6CIDR(field.NewPath(""), tc.value)) == 0; got != tc.ipv6 {
+			t.Errorf("IsValidIPv6CIDR(%q): expected %v, got %v", tc.value, tc.ipv6, got)
+		}
+	}
+
+	prefixCases := []struct {
+		value string
+		valid bool
+	}{
+		{"10.0.0.0/16", true},
+		{"10.0.0.0/24", true},
+		{"10.0.0.0/8", false},
+		{"10.0.0.0/25", false},
+		{"2001:db8::/20", true},
+		{"10.0.0.0", false},
+	}
+	for _, tc := range prefixCases {
+		if got := len(IsValidCIDRPrefix(field.NewPath(""), tc.value, 16, 24)) == 0; got != tc.valid {
+			t.Errorf("IsValidCIDRPrefix(%q, 16, 24): expected %v, got %v", tc.value, tc.valid, got)
+		}
+	}
+}
+
+func TestIsValidNetworkAndIdentityValues(t *testing.T) {
+	testCases := []struct {
+		name       string
+		fn         func(string) []string
+		goodValues []string
+		badValues  []string
+	}{
+		{
+			name:       "port range",
+			fn:         IsValidPortRange,
+			goodValues: []string{"80", "1-65535", "8000-9000", "8080-8080"},
+			badValues:  []string{"", "0", "65536", "9000-8000", "1-2-3", "80-", "-80", "http"},
+		},
+		{
+			name:       "host port",
+			fn:         IsValidHostPort,
+			goodValues: []string{"example.com:443", "localhost:80", "10.0.0.1:8080", "[::1]:8080"},
+			badValues:  []string{"", "example.com", ":80", "example.com:0", "example.com:http", "Example_com:80", "::1:80"},
+		},
+		{
+			name:       "URL",
+			fn:         func(s string) []string { return IsValidURL(s) },
+			goodValues: []string{"https://example.com", "ftp://example.com/file", "http://10.0.0.1:8080/path?q=1"},
+			badValues:  []string{"", "example.com", "/path", "https://", "http//example.com"},
+		},
+		{
+			name:       "URL with schemes",
+			fn:         func(s string) []string { return IsValidURL(s, "http", "https") },
+			goodValues: []string{"https://example.com", "HTTP://example.com"},
+			badValues:  []string{"ftp://example.com", "file:///etc/passwd"},
+		},
+		{
+			name:       "email",
+			fn:         IsValidEmail,
+			goodValues: []string{"colin@example.com", "first.last+tag@sub.example.com"},
+			badValues:  []string{"", "colin", "colin@", "@example.com", "Colin <colin@example.com>"},
+		},
+		{
+			name:       "E.164",
+			fn:         IsValidE164,
+			goodValues: []string{"+8613800138000", "+14155552671", "+12"},
+			badValues:  []string{"", "8613800138000", "+0123456", "+1234567890123456", "+1 415 555 2671"},
+		},
+		{
+			name:       "semver",
+			fn:         IsValidSemver,
+			goodValues: []string{"0.0.0", "1.2.3", "v1.2.3", "1.0.0-rc.1", "1.0.0-alpha+build.5", "1.0.0+20130313144700"},
+			badValues:  []string{"", "1", "1.2", "01.2.3", "1.2.3-", "1.2.3-01", "vv1.2.3", "1.2.3.4"},
+		},
+		{
+			name:       "cron",
+			fn:         IsValidCron,
+			goodValues: []string{"*/10 * * * *", "0 */5 * * * *", "0 0 1 JAN *", "@daily", "@every 1m"},
+			badValues:  []string{"", "* * *", "60 * * * *", "@never", "@every 10ms"},
+		},
+		{
+			name:       "duration",
+			fn:         IsValidDuration,
+			goodValues: []string{"1h30m", "10s", "0", "PT1H30M", "P1D"},
+			badValues:  []string{"", "1x", "P", "an hour"},
+		},
+		{
+			name:       "UUID",
+			fn:         IsValidUUID,
+			goodValues: []string{"123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"},
+			badValues:  []string{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"},
+		},
+		{
+			name:       "ULID",
+			fn:         IsValidULID,
+			goodValues: []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+			badValues:  []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "81ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAU"},
+		},
+		{
+			name:       "instance ID",
+			fn:         func(s string) []string { return IsValidInstanceID(s, "") },
+			goodValues: []string{"secret-2v69o5", "policy-lx1l6pqz", "2v69o5"},
+			badValues:  []string{"", "secret-2v69", "Secret-2v69o5", "secret_2v69o5", "secret-2V69O5", "-2v69o5"},
+		},
+		{
+			name:       "prefixed instance ID",
+			fn:         func(s string) []string { return IsValidInstanceID(s, "secret-") },
+			goodValues: []string{"secret-2v69o5"},
+			badValues:  []string{"policy-2v69o5", "2v69o5", "secret-"},
+		},
+	}
+	for _, tc := range testCases {
+		for _, val := range tc.goodValues {
+			if msgs := tc.fn(val); len(msgs) != 0 {
+				t.Errorf("%s: expected true for %q: %v", tc.name, val, msgs)
+			}
+		}
+		for _, val := range tc.badValues {
+			if msgs := tc.fn(val); len(msgs) == 0 {
+				t.Errorf("%s: expected false for %q", tc.name, val)
+			}
+		}
+	}
+}
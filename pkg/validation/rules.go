@@ -0,0 +1,510 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// Rule is a declarative validation rule, for the checks struct tags cannot express, e.g.
+// cross-field checks:
+//
+//	validation.Rule{
+//		Rule:    "endTime > startTime",
+//		Message: "must be after startTime",
+//		Field:   "endTime",
+//	}
+//
+// The rule is an Expression evaluated against the object it is declared for, which must
+// return true for the object to be valid.
+type Rule struct {
+	// Rule is the expression of the rule.
+	Rule string
+
+	// Message is the detail of the error reported when the rule fails. Defaults to
+	// "failed rule: <rule>".
+	Message string
+
+	// Field is the path of the field the error is reported on, relative to the object,
+	// made of json names separated by dots, e.g. "spec.endTime". Defaults to the object.
+	Field string
+
+	// Reason is the type of the error reported when the rule fails. Defaults to
+	// field.ErrorTypeInvalid.
+	Reason field.ErrorType
+
+	// ID identifies the rule in the origin of the errors it reports. Defaults to the
+	// expression of the rule.
+	ID string
+
+	// Warning reports the failures of the rule as warnings instead of errors, e.g. for the
+	// rules which will be enforced later. They are only reported by ValidateWithWarnings.
+	Warning bool
+}
+
+// origin returns the origin of the errors reported by the rule.
+func (r Rule) origin() string {
+	if len(r.ID) > 0 {
+		return r.ID
+	}
+
+	return r.Rule
+}
+
+// RuleProvider is implemented by the types declaring their validation rules.
+type RuleProvider interface {
+	ValidationRules() []Rule
+}
+
+// RuleFunction is a function which can be called by the rule expressions. Its arguments
+// are nil, bool, int64, float64, string, time.Time or, for lists, maps and structs,
+// reflect.Value.
+type RuleFunction func(args ...interface{}) (interface{}, error)
+
+var (
+	rulesLock     sync.RWMutex
+	rulesRegistry = map[reflect.Type][]compiledRule{}
+	ruleFunctions = map[string]RuleFunction{}
+
+	// expressions caches the expressions of the rules returned by RuleProviders.
+	expressions sync.Map
+	// regexps caches the regular expressions of the regexp tag and the literal patterns of
+	// the matches function. The patterns read from the objects are not cached.
+	regexps sync.Map
+)
+
+type compiledRule struct {
+	rule Rule
+	expr *Expression
+}
+
+// RegisterRules registers validation rules for the type of obj, e.g. for the types which
+// can't implement RuleProvider. The rules are compiled when they are registered.
+func RegisterRules(obj interface{}, rules ...Rule) error {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return fmt.Errorf("cannot register rules for nil")
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		expr, err := CompileExpression(rule.Rule)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, compiledRule{rule: rule, expr: expr})
+	}
+
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	rulesRegistry[t] = append(rulesRegistry[t], compiled...)
+
+	return nil
+}
+
+// RegisterRuleFunction registers a function which can be called by the rule expressions.
+// Registering a function again replaces it.
+func RegisterRuleFunction(name string, fn RuleFunction) {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+
+	ruleFunctions[name] = fn
+}
+
+func lookupRuleFunction(name string) (RuleFunction, bool) {
+	rulesLock.RLock()
+	defer rulesLock.RUnlock()
+
+	fn, ok := ruleFunctions[name]
+
+	return fn, ok
+}
+
+// ValidateRules validates the rules declared for obj and for the structs it contains, by
+// implementing RuleProvider or with RegisterRules. The errors are reported under fldPath.
+func ValidateRules(obj interface{}, fldPath *field.Path) field.ErrorList {
+	result := &Result{Errors: field.ErrorList{}}
+	validateRules(reflect.ValueOf(obj), fldPath, result)
+
+	return result.Errors
+}
+
+func validateRules(v reflect.Value, fldPath *field.Path, result *Result) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	//nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return
+		}
+		for _, rule := range rulesFor(v) {
+			err := evalRule(rule, v, fldPath)
+			if err == nil {
+				continue
+			}
+			err = err.WithOrigin(rule.rule.origin())
+			if rule.rule.Warning {
+				result.warnFailure(err)
+			} else {
+				result.Errors = append(result.Errors, err)
+			}
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			if name, ok := jsonName(sf); ok {
+				result.warnField(sf, v.Field(i), childPath(fldPath, name))
+				validateRules(v.Field(i), childPath(fldPath, name), result)
+			} else {
+				result.warnField(sf, v.Field(i), fldPath)
+				validateRules(v.Field(i), fldPath, result)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateRules(v.Index(i), indexPath(fldPath, i), result)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateRules(iter.Value(), keyPath(fldPath, fmt.Sprint(iter.Key().Interface())), result)
+		}
+	}
+}
+
+// rulesFor returns the registered rules of the type of v, and the rules v provides.
+func rulesFor(v reflect.Value) []compiledRule {
+	rulesLock.RLock()
+	rules := rulesRegistry[v.Type()]
+	rulesLock.RUnlock()
+
+	var provider RuleProvider
+	if p, ok := v.Interface().(RuleProvider); ok {
+		provider = p
+	} else if v.CanAddr() {
+		provider, _ = v.Addr().Interface().(RuleProvider)
+	} else if reflect.PtrTo(v.Type()).Implements(reflect.TypeOf((*RuleProvider)(nil)).Elem()) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		provider = ptr.Interface().(RuleProvider)
+	}
+	if provider == nil {
+		return rules
+	}
+
+	provided := provider.ValidationRules()
+	result := make([]compiledRule, 0, len(rules)+len(provided))
+	result = append(result, rules...)
+	for _, rule := range provided {
+		result = append(result, compiledRule{rule: rule})
+	}
+
+	return result
+}
+
+// evalRule returns the error reported when the rule fails on v, or nil.
+func evalRule(compiled compiledRule, v reflect.Value, fldPath *field.Path) *field.Error {
+	rule := compiled.rule
+	path := fldPath
+	if len(rule.Field) > 0 {
+		for _, name := range strings.Split(rule.Field, ".") {
+			path = childPath(path, name)
+		}
+	}
+	if path == nil {
+		path = field.NewPath("")
+	}
+
+	expr := compiled.expr
+	if expr == nil {
+		if cached, ok := expressions.Load(rule.Rule); ok {
+			expr = cached.(*Expression)
+		} else {
+			var err error
+			if expr, err = CompileExpression(rule.Rule); err != nil {
+				return field.InternalError(path, err)
+			}
+			expressions.Store(rule.Rule, expr)
+		}
+	}
+
+	ok, err := expr.EvalBool(v)
+	if err != nil {
+		return field.InternalError(path, fmt.Errorf("rule %q: %v", rule.Rule, err))
+	}
+	if ok {
+		return nil
+	}
+
+	message := rule.Message
+	if len(message) == 0 {
+		message = "failed rule: " + rule.Rule
+	}
+	switch rule.Reason {
+	case field.ErrorTypeRequired:
+		return field.Required(path, message)
+	case field.ErrorTypeForbidden:
+		return field.Forbidden(path, message)
+	case "", field.ErrorTypeInvalid:
+		return field.Invalid(path, ruleFieldValue(v, rule.Field), message)
+	default:
+		return &field.Error{Type: rule.Reason, Field: path.String(), BadValue: ruleFieldValue(v, rule.Field),
+			Detail: message}
+	}
+}
+
+// ruleFieldValue returns the value of the field a rule reports its error on, or nil.
+func ruleFieldValue(v reflect.Value, fieldPath string) interface{} {
+	if len(fieldPath) > 0 {
+		for _, name := range strings.Split(fieldPath, ".") {
+			for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					return nil
+				}
+				v = v.Elem()
+			}
+			//nolint: exhaustive
+			switch v.Kind() {
+			case reflect.Struct:
+				f, ok := structField(v, name)
+				if !ok {
+					return nil
+				}
+				v = f
+			case reflect.Map:
+				key, err := mapKey(v, name)
+				if err != nil || !v.MapIndex(key).IsValid() {
+					return nil
+				}
+				v = v.MapIndex(key)
+			default:
+				return nil
+			}
+		}
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	return v.Interface()
+}
+
+func childPath(p *field.Path, name string) *field.Path {
+	if p == nil {
+		return field.NewPath(name)
+	}
+
+	return p.Child(name)
+}
+
+func indexPath(p *field.Path, i int) *field.Path {
+	if p == nil {
+		return field.NewPath("").Index(i)
+	}
+
+	return p.Index(i)
+}
+
+func keyPath(p *field.Path, key string) *field.Path {
+	if p == nil {
+		return field.NewPath("").Key(key)
+	}
+
+	return p.Key(key)
+}
+
+func init() {
+	RegisterRuleFunction("size", ruleSize)
+	RegisterRuleFunction("matches", ruleMatches)
+	RegisterRuleFunction("contains", stringRuleFunction(strings.Contains))
+	RegisterRuleFunction("startsWith", stringRuleFunction(strings.HasPrefix))
+	RegisterRuleFunction("endsWith", stringRuleFunction(strings.HasSuffix))
+	RegisterRuleFunction("duration", ruleDuration)
+	RegisterRuleFunction("timestamp", ruleTimestamp)
+
+	// the validation helpers of this package
+	RegisterRuleFunction("isQualifiedName", isRuleFunction(IsQualifiedName))
+	RegisterRuleFunction("isValidLabelValue", isRuleFunction(IsValidLabelValue))
+	RegisterRuleFunction("isDNS1123Label", isRuleFunction(IsDNS1123Label))
+	RegisterRuleFunction("isDNS1123Subdomain", isRuleFunction(IsDNS1123Subdomain))
+	RegisterRuleFunction("isValidIP", isRuleFunction(IsValidIP))
+	RegisterRuleFunction("isValidPercent", isRuleFunction(IsValidPercent))
+	RegisterRuleFunction("isValidPortRange", isRuleFunction(IsValidPortRange))
+	RegisterRuleFunction("isValidHostPort", isRuleFunction(IsValidHostPort))
+	RegisterRuleFunction("isValidEmail", isRuleFunction(IsValidEmail))
+	RegisterRuleFunction("isValidE164", isRuleFunction(IsValidE164))
+	RegisterRuleFunction("isValidSemver", isRuleFunction(IsValidSemver))
+	RegisterRuleFunction("isValidCron", isRuleFunction(IsValidCron))
+	RegisterRuleFunction("isValidUUID", isRuleFunction(IsValidUUID))
+	RegisterRuleFunction("isValidULID", isRuleFunction(IsValidULID))
+	RegisterRuleFunction("isValidCIDR", func(args ...interface{}) (interface{}, error) {
+		s, err := stringArgs(1, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return len(IsValidCIDR(nil, s[0])) == 0, nil
+	})
+	RegisterRuleFunction("isValidPortNum", func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		port, ok := args[0].(int64)
+		if !ok {
+			return nil, fmt.Errorf("expected an int, got %s", typeName(args[0]))
+		}
+
+		return len(IsValidPortNum(int(port))) == 0, nil
+	})
+}
+
+// isRuleFunction wraps a validation helper of a string into a RuleFunction returning true
+// if the helper returns no error.
+func isRuleFunction(fn func(string) []string) RuleFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		s, err := stringArgs(1, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return len(fn(s[0])) == 0, nil
+	}
+}
+
+func stringRuleFunction(fn func(s, x string) bool) RuleFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		s, err := stringArgs(2, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(s[0], s[1]), nil
+	}
+}
+
+func stringArgs(n int, args []interface{}) ([]string, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("expected %d arguments, got %d", n, len(args))
+	}
+	result := make([]string, n)
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", typeName(arg))
+		}
+		result[i] = s
+	}
+
+	return result, nil
+}
+
+// ruleSize returns the number of characters of a string, or the number of items of a list
+// or a map.
+func ruleSize(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+
+	switch x := args[0].(type) {
+	case string:
+		return int64(utf8.RuneCountInString(x)), nil
+	case reflect.Value:
+		//nolint: exhaustive
+		switch x.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return int64(x.Len()), nil
+		}
+	case nil:
+		return int64(0), nil
+	}
+
+	return nil, fmt.Errorf("no size for %s", typeName(args[0]))
+}
+
+// ruleMatches returns true if a string matches a regular expression.
+func ruleMatches(args ...interface{}) (interface{}, error) {
+	s, err := stringArgs(2, args)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := loadRegexp(s[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return re.MatchString(s[0]), nil
+}
+
+// loadRegexp returns a cached regular expression, or compiles it without caching it.
+func loadRegexp(expr string) (*regexp.Regexp, error) {
+	if cached, ok := regexps.Load(expr); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	return regexp.Compile(expr)
+}
+
+// cachedRegexp compiles a regular expression once. It must only be called with the
+// patterns of the code, e.g. the tags and the literals of the expressions.
+func cachedRegexp(expr string) (*regexp.Regexp, error) {
+	if cached, ok := regexps.Load(expr); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Store(expr, re)
+
+	return re, nil
+}
+
+// ruleDuration parses a Go duration into nanoseconds, e.g. duration('1h').
+func ruleDuration(args ...interface{}) (interface{}, error) {
+	s, err := stringArgs(1, args)
+	if err != nil {
+		return nil, err
+	}
+	d, err := time.ParseDuration(s[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(d), nil
+}
+
+// ruleTimestamp parses a RFC 3339 time, e.g. timestamp('2020-10-01T00:00:00Z').
+func ruleTimestamp(args ...interface{}) (interface{}, error) {
+	s, err := stringArgs(1, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.Parse(time.RFC3339Nano, s[0])
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+type testWindow struct {
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+type testJob struct {
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Schedule string            `json:"schedule,omitempty"`
+	Replicas *int              `json:"replicas,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Windows  []testWindow      `json:"windows,omitempty"`
+	Timeout  time.Duration     `json:"timeout"`
+}
+
+func (testJob) ValidationRules() []Rule {
+	return []Rule{
+		{
+			Rule:    "type != 'Cron' || has(schedule)",
+			Message: "is required for cron jobs",
+			Field:   "schedule",
+			Reason:  field.ErrorTypeRequired,
+			ID:      "cron-schedule",
+		},
+		{Rule: "isDNS1123Label(name)", Message: "must be a DNS label", Field: "name"},
+		{Rule: "!has(replicas) || replicas <= 10", Field: "replicas"},
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	replicas := 3
+	start := time.Date(2020, 10, 1, 9, 0, 0, 0, time.UTC)
+	job := &testJob{
+		Name:     "backup",
+		Type:     "Cron",
+		Replicas: &replicas,
+		Labels:   map[string]string{"app": "iam"},
+		Windows:  []testWindow{{StartTime: start, EndTime: start.Add(time.Hour)}},
+		Timeout:  90 * time.Second,
+	}
+
+	testCases := map[string]interface{}{
+		"name == 'backup' && type in ['Cron', 'Once']":                            true,
+		"self.name + '-' + labels.app":                                            "backup-iam",
+		"labels['app'].startsWith('i') && 'app' in labels":                        true,
+		"has(labels.env) ? labels.env : 'dev'":                                    "dev",
+		"size(windows) == 1 && size(name) == 6":                                   true,
+		"windows[0].endTime > windows[0].startTime":                               true,
+		"windows[0].endTime - windows[0].startTime":                               int64(time.Hour),
+		"windows[0].startTime + duration('1h') == windows[0].endTime":             true,
+		"windows[0].startTime > timestamp('2020-01-01T00:00:00Z')":                true,
+		"timeout < duration('2m') && timeout >= 90000000000":                      true,
+		"replicas * 2 + 1.5":                                                      7.5,
+		"-replicas % 2 == -1 && 7 / 2 == 3":                                       true,
+		"name.matches('^[a-z]+$') && !name.contains('-')":                         true,
+		"isValidPortNum(8080) && !isQualifiedName('-x') && isValidIP('10.0.0.1')": true,
+		"has(schedule) || schedule == ''":                                         true,
+		`"it's" == 'it\'s'`:                                                       true,
+	}
+	for source, expected := range testCases {
+		expr, err := CompileExpression(source)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", source, err)
+
+			continue
+		}
+		got, err := expr.Eval(job)
+		if err != nil || !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v (%v)", source, expected, got, err)
+		}
+	}
+
+	for _, source := range []string{"", "name ==", "(name", "name.", "a ? b", "name $ 1", "'open", "f(,)"} {
+		if _, err := CompileExpression(source); err == nil {
+			t.Errorf("%s: expected a syntax error", source)
+		}
+	}
+
+	for _, source := range []string{"nmae == ''", "labels.env == ''", "name < 1", "windows[3]", "unknown(name)",
+		"name && true", "replicas / 0", "size(replicas)"} {
+		expr, err := CompileExpression(source)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", source, err)
+
+			continue
+		}
+		if _, err := expr.Eval(job); err == nil {
+			t.Errorf("%s: expected an evaluation error", source)
+		}
+	}
+}
+
+func TestExpressionsUnsigned(t *testing.T) {
+	expr, err := CompileExpression("size > 0 && size != 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type object struct {
+		Size uint64 `json:"size"`
+	}
+	if ok, err := expr.EvalBool(object{Size: 1 << 62}); err != nil || !ok {
+		t.Errorf("expected true, got %v (%v)", ok, err)
+	}
+	if ok, err := expr.EvalBool(object{Size: 1 << 63}); err == nil {
+		t.Errorf("expected an error for an unsigned integer overflowing int64, got %v", ok)
+	}
+}
+
+func TestMatchesCache(t *testing.T) {
+	expr, err := CompileExpression("name.matches('^[a-z]+-cache$') && name.matches(type)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := regexps.Load("^[a-z]+-cache$"); !ok {
+		t.Errorf("expected the literal pattern to be cached")
+	}
+
+	job := &testJob{Name: "job-cache", Type: "^job-[a-z]+$"}
+	if ok, err := expr.EvalBool(job); err != nil || !ok {
+		t.Errorf("expected true, got %v (%v)", ok, err)
+	}
+	if _, ok := regexps.Load(job.Type); ok {
+		t.Errorf("expected the pattern of the object not to be cached")
+	}
+
+	if _, err := CompileExpression("name.matches('[a-')"); err == nil {
+		t.Errorf("expected an invalid literal pattern to fail the compilation")
+	}
+}
+
+func TestLexStrings(t *testing.T) {
+	testCases := map[string]string{
+		`'a\"b'`:       `a"b`,
+		`"a\"b"`:       `a"b`,
+		`'a"b'`:        `a"b`,
+		`"a'b"`:        `a'b`,
+		`'it\'s'`:      `it's`,
+		`"it\'s"`:      `it's`,
+		`'\\"'`:        `\"`,
+		`'a\tb\u00e9'`: "a\tb\u00e9",
+	}
+	for source, expected := range testCases {
+		tokens, err := lex(source)
+		if err != nil || len(tokens) != 2 || tokens[0].kind != tokString || tokens[0].value != expected {
+			t.Errorf("%s: expected the string %q, got %v (%v)", source, expected, tokens, err)
+		}
+	}
+
+	for _, source := range []string{`'a\qb'`, `"a\"`, `'\x'`} {
+		if _, err := lex(source); err == nil {
+			t.Errorf("%s: expected an invalid string", source)
+		}
+	}
+}
+
+type testWorkflow struct {
+	Name string    `json:"name"`
+	Jobs []testJob `json:"jobs"`
+}
+
+func TestValidateRules(t *testing.T) {
+	if err := RegisterRules(testWindow{}, Rule{
+		Rule:    "endTime > startTime",
+		Message: "must be after startTime",
+		Field:   "endTime",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RegisterRules(testWindow{}, Rule{Rule: "endTime >"}); err == nil {
+		t.Errorf("expected an error registering an invalid rule")
+	}
+	RegisterRuleFunction("fails", func(...interface{}) (interface{}, error) {
+		return nil, errors.New("failure")
+	})
+
+	replicas := 20
+	start := time.Date(2020, 10, 1, 9, 0, 0, 0, time.UTC)
+	workflow := &testWorkflow{
+		Name: "nightly",
+		Jobs: []testJob{
+			{Name: "backup", Type: "Once", Windows: []testWindow{{StartTime: start, EndTime: start.Add(time.Hour)}}},
+			{Name: "Report", Type: "Cron", Replicas: &replicas, Windows: []testWindow{{StartTime: start, EndTime: start}}},
+		},
+	}
+
+	jobs := field.NewPath("spec", "jobs")
+	expected := field.ErrorList{
+		field.Required(jobs.Index(1).Child("schedule"), "is required for cron jobs").WithOrigin("cron-schedule"),
+		field.Invalid(jobs.Index(1).Child("name"), "Report", "must be a DNS label").WithOrigin("isDNS1123Label(name)"),
+		field.Invalid(jobs.Index(1).Child("replicas"), 20, "failed rule: !has(replicas) || replicas <= 10").
+			WithOrigin("!has(replicas) || replicas <= 10"),
+		field.Invalid(jobs.Index(1).Child("windows").Index(0).Child("endTime"), start, "must be after startTime").
+			WithOrigin("endTime > startTime"),
+	}
+	assert.Equal(t, expected, ValidateRules(workflow, field.NewPath("spec")))
+
+	if err := RegisterRules(testWorkflow{}, Rule{Rule: "fails()"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	errs := NewValidator(workflow).Validate()
+	if len(errs) != 5 || errs[0].Type != field.ErrorTypeInternal || errs[1].Field != "jobs[1].schedule" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
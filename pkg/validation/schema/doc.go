@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package schema generates JSON Schemas (draft 2020-12) and OpenAPI 3 components from Go
+// types and their validate tags, and validates raw JSON against them.
+package schema
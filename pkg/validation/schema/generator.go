@@ -0,0 +1,509 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"encoding"
+	// json.Marshaler is the interface honoured by all the JSON codecs.
+	stdjson "encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider is implemented by the types generating their own schema, e.g. the types with
+// a custom JSON encoding.
+type Provider interface {
+	JSONSchema() *Schema
+}
+
+var (
+	providerType      = reflect.TypeOf((*Provider)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*stdjson.Marshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+// Generator generates the schemas of Go types. The named struct types are generated once,
+// as definitions referenced with a $ref.
+type Generator struct {
+	refPrefix string
+	defs      map[string]*Schema
+	names     map[reflect.Type]string
+}
+
+// NewGenerator creates a Generator whose references point to the $defs of a JSON Schema.
+func NewGenerator() *Generator {
+	return newGenerator("#/$defs/")
+}
+
+// NewOpenAPIGenerator creates a Generator whose references point to the schemas of the
+// components of an OpenAPI 3.1 document.
+func NewOpenAPIGenerator() *Generator {
+	return newGenerator("#/components/schemas/")
+}
+
+func newGenerator(refPrefix string) *Generator {
+	return &Generator{refPrefix: refPrefix, defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// JSONSchema returns the JSON Schema of the type of v, with the definitions of the struct
+// types it uses.
+func JSONSchema(v interface{}) *Schema {
+	g := NewGenerator()
+	s := g.Schema(v)
+	s.Schema = Draft
+	s.Defs = g.Definitions()
+
+	return s
+}
+
+// OpenAPIComponents returns the OpenAPI 3.1 components of the types of values, and of the
+// struct types they use. OpenAPI 3.1 schemas are JSON Schemas draft 2020-12.
+func OpenAPIComponents(values ...interface{}) *Components {
+	g := NewOpenAPIGenerator()
+	for _, v := range values {
+		g.Schema(v)
+	}
+
+	return &Components{Schemas: g.Definitions()}
+}
+
+// Schema returns the schema of the type of v, a reference for the named struct types.
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// Definitions returns the schemas of the named struct types generated so far.
+func (g *Generator) Definitions() map[string]*Schema {
+	return g.defs
+}
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Implements(providerType):
+		return reflect.Zero(t).Interface().(Provider).JSONSchema()
+	case reflect.PtrTo(t).Implements(providerType):
+		return reflect.New(t).Interface().(Provider).JSONSchema()
+	case t == timeType:
+		return &Schema{Type: Types{TypeString}, Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: Types{TypeInteger}, Description: "Duration in nanoseconds."}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: Types{TypeString}}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		// anything
+		return &Schema{}
+	}
+
+	//nolint: exhaustive
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{TypeInteger}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{TypeInteger}, Minimum: floatPtr(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}}
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{TypeString}, ContentEncoding: "base64"}
+		}
+		s := &Schema{Type: Types{TypeArray}, Items: g.schemaOf(t.Elem())}
+		if t.Kind() == reflect.Array {
+			s.MinItems, s.MaxItems = intPtr(t.Len()), intPtr(t.Len())
+		}
+
+		return s
+	case reflect.Map:
+		return &Schema{Type: Types{TypeObject}, AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return g.objectSchema(t)
+		}
+
+		return &Schema{Ref: g.refPrefix + g.define(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+// define generates the definition of a named struct type, and returns its name.
+func (g *Generator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.defs[name]; taken {
+		pkg := t.PkgPath()
+		name = strings.ReplaceAll(pkg[strings.LastIndex(pkg, "/")+1:], ".", "_") + "." + name
+	}
+	g.names[t] = name
+	// reserve the name for the recursive types
+	g.defs[name] = &Schema{}
+	*g.defs[name] = *g.objectSchema(t)
+
+	return name
+}
+
+// objectSchema generates the schema of the fields of a struct. The embedded structs without
+// json name are inlined, like encoding/json does.
+func (g *Generator) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{TypeObject}, Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" && len(tag) == 1 {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+			inline := g.objectSchema(ft)
+			for k, v := range inline.Properties {
+				if _, ok := s.Properties[k]; !ok {
+					s.Properties[k] = v
+				}
+			}
+			s.Required = append(s.Required, inline.Required...)
+
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+
+		if qs, ok := quotedSchema(sf.Type, tag[1:]); ok {
+			// the validate tags describe the value, not its string encoding
+			required := applyTags(&Schema{}, sf.Type, sf.Tag.Get("validate"))
+			if required {
+				s.Required = append(s.Required, name)
+			}
+			s.Properties[name] = allowEmpty(qs, sf.Type, required, false)
+
+			continue
+		}
+
+		fs := g.schemaOf(sf.Type)
+		required := applyTags(fs, sf.Type, sf.Tag.Get("validate"))
+		if required {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = allowEmpty(fs, sf.Type, required, hasOmitEmpty(sf.Tag.Get("validate")))
+	}
+
+	return s
+}
+
+// quotedSchema returns the schema of a field with the string json option, which encodes the
+// booleans, the numbers and the strings, or a pointer to them, inside a JSON string. The
+// option is ignored for the other types, and for the types with their own encoding.
+func quotedSchema(t reflect.Type, options []string) (*Schema, bool) {
+	quoted := false
+	for _, option := range options {
+		if option == "string" {
+			quoted = true
+		}
+	}
+	if !quoted {
+		return nil, false
+	}
+
+	if t.Kind() == reflect.Ptr && len(t.Name()) == 0 {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return nil, false
+	}
+
+	//nolint: exhaustive
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeString}, Enum: []interface{}{"true", "false"}}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{TypeString}, Pattern: `^-?[0-9]+$`}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{TypeString}, Pattern: `^[0-9]+$`}, true
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeString}, Pattern: `^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`}, true
+	case reflect.String:
+		// the JSON encoding of the string
+		return &Schema{Type: Types{TypeString}, Pattern: `^".*"$`}, true
+	default:
+		return nil, false
+	}
+}
+
+// allowEmpty returns the schema of a field accepting the empty values the validate tags
+// let through: null for the pointers, slices and maps which are not required, and the zero
+// value of the scalars tagged omitempty.
+func allowEmpty(s *Schema, t reflect.Type, required, omitEmpty bool) *Schema {
+	kind := t.Kind()
+	if !required && (kind == reflect.Ptr || kind == reflect.Slice || kind == reflect.Map) {
+		s = nullable(s)
+	}
+	if !omitEmpty || kind == reflect.Ptr {
+		return s
+	}
+
+	var zero interface{}
+	//nolint: exhaustive
+	switch kind {
+	case reflect.String:
+		zero = ""
+	case reflect.Bool:
+		zero = false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		zero = 0
+	default:
+		return s
+	}
+
+	return &Schema{AnyOf: []*Schema{{Const: zero}, s}}
+}
+
+// nullable returns a copy of s which also accepts null.
+func nullable(s *Schema) *Schema {
+	switch {
+	case len(s.Type) > 0:
+		c := *s
+		c.Type = append(append(Types{}, s.Type...), TypeNull)
+		if len(c.Enum) > 0 {
+			c.Enum = append(append([]interface{}{}, s.Enum...), nil)
+		}
+
+		return &c
+	case len(s.Ref) > 0 || len(s.AnyOf) > 0:
+		return &Schema{AnyOf: []*Schema{{Type: Types{TypeNull}}, s}}
+	default:
+		// anything
+		return s
+	}
+}
+
+// hasOmitEmpty returns true if the validate tags of a field start with omitempty, before
+// any dive.
+func hasOmitEmpty(tag string) bool {
+	for _, name := range strings.Split(tag, ",") {
+		if name == "dive" {
+			return false
+		}
+		if name == "omitempty" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyTags maps the validate tags of a field to the keywords of its schema. It returns true
+// if the field is required.
+func applyTags(s *Schema, t reflect.Type, tag string) bool {
+	if len(tag) == 0 || tag == "-" {
+		return false
+	}
+
+	required := false
+	tags := strings.Split(tag, ",")
+	for i := 0; i < len(tags); i++ {
+		name, param := tags[i], ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, param = name[:j], name[j+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			elem := indirectType(t)
+			if elem.Kind() != reflect.Slice && elem.Kind() != reflect.Array && elem.Kind() != reflect.Map {
+				return required
+			}
+			rest := tags[i+1:]
+			// the keys of the maps are not described
+			if len(rest) > 0 && rest[0] == "keys" {
+				for k, tag := range rest {
+					if tag == "endkeys" {
+						rest = rest[k+1:]
+
+						break
+					}
+				}
+			}
+			target := s.Items
+			if elem.Kind() == reflect.Map {
+				target = s.AdditionalProperties
+			}
+			if target != nil {
+				applyTags(target, elem.Elem(), strings.Join(rest, ","))
+			}
+
+			return required
+		default:
+			applyTag(s, t, name, param)
+		}
+	}
+
+	return required
+}
+
+// applyTag maps a single validate tag to schema keywords. The tags without an equivalent
+// keyword, and the alternatives separated by "|", are ignored.
+func applyTag(s *Schema, t reflect.Type, name, param string) {
+	if strings.Contains(name, "|") {
+		return
+	}
+	kind := indirectType(t).Kind()
+
+	switch name {
+	case "min", "gte":
+		setBound(s, kind, param, false, false)
+	case "max", "lte":
+		setBound(s, kind, param, true, false)
+	case "gt":
+		setBound(s, kind, param, false, true)
+	case "lt":
+		setBound(s, kind, param, true, true)
+	case "len":
+		setBound(s, kind, param, false, false)
+		setBound(s, kind, param, true, false)
+	case "eq", "oneof":
+		values := []string{param}
+		if name == "oneof" {
+			values = strings.Fields(param)
+		}
+		for _, v := range values {
+			s.Enum = append(s.Enum, enumValue(kind, v))
+		}
+	case "email", "ipv4", "ipv6", "hostname", "uuid":
+		s.Format = name
+	case "url", "uri":
+		s.Format = "uri"
+	case "uuid4", "uuid_rfc4122", "uuid4_rfc4122":
+		s.Format = "uuid"
+	case "hostname_rfc1123":
+		s.Format = "hostname"
+	case "alpha":
+		s.Pattern = "^[a-zA-Z]+$"
+	case "alphanum":
+		s.Pattern = "^[a-zA-Z0-9]+$"
+	case "numeric":
+		s.Pattern = `^[-+]?[0-9]+(?:\.[0-9]+)?$`
+	case "regexp":
+		s.Pattern = strings.NewReplacer("0x2C", ",", "0x7C", "|").Replace(param)
+	case "unique":
+		s.UniqueItems = true
+	case "dir", "file", "name", "description":
+		s.Format = name
+		if len(s.Description) == 0 {
+			s.Description = Formats[name]
+		}
+		if name == "description" {
+			s.MaxLength = intPtr(255)
+		}
+	}
+}
+
+// setBound sets the minimum or maximum of a value, of the length of a string, of the
+// number of items of an array or of the number of properties of an object.
+func setBound(s *Schema, kind reflect.Kind, param string, max, exclusive bool) {
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	//nolint: exhaustive
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n := int(f)
+		if exclusive && max {
+			n--
+		} else if exclusive {
+			n++
+		}
+		var target **int
+		switch {
+		case kind == reflect.String && max:
+			target = &s.MaxLength
+		case kind == reflect.String:
+			target = &s.MinLength
+		case kind == reflect.Map && max:
+			target = &s.MaxProperties
+		case kind == reflect.Map:
+			target = &s.MinProperties
+		case max:
+			target = &s.MaxItems
+		default:
+			target = &s.MinItems
+		}
+		*target = intPtr(n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		switch {
+		case max && exclusive:
+			s.ExclusiveMaximum = floatPtr(f)
+		case max:
+			s.Maximum = floatPtr(f)
+		case exclusive:
+			s.ExclusiveMinimum = floatPtr(f)
+		default:
+			s.Minimum = floatPtr(f)
+		}
+	}
+}
+
+// enumValue returns a value of a oneof or eq tag, typed after the field.
+func enumValue(kind reflect.Kind, v string) interface{} {
+	//nolint: exhaustive
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return strings.Trim(v, "'")
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,206 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+type testSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Username    string            `json:"username"              validate:"required,min=1,max=16"`
+	SecretID    string            `json:"secretID"              validate:"required,regexp=^[a-z0-9]{20x2C8}$"`
+	Email       string            `json:"email,omitempty"       validate:"omitempty,email"`
+	Algorithm   string            `json:"algorithm"             validate:"oneof=rsa ecdsa"`
+	Expires     int64             `json:"expires"               validate:"gte=0,lt=100"`
+	Tags        []string          `json:"tags,omitempty"        validate:"max=3,unique,dive,max=8"`
+	Path        string            `json:"path,omitempty"        validate:"omitempty,dir"`
+	Description string            `json:"description,omitempty" validate:"description"`
+	Labels      map[string]string `json:"labels,omitempty"      validate:"dive,keys,max=5,endkeys,alphanum"`
+	Parent      *testSecret       `json:"parent,omitempty"`
+	Data        []byte            `json:"data,omitempty"`
+	internal    string
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema(&testSecret{})
+	if s.Schema != Draft || s.Ref != "#/$defs/testSecret" {
+		t.Fatalf("unexpected root schema %#v", s)
+	}
+
+	secret := s.Defs["testSecret"]
+	assert.Equal(t, []string{"username", "secretID"}, secret.Required)
+	assert.Equal(t, &Schema{Ref: "#/$defs/ObjectMeta"}, secret.Properties["metadata"])
+	assert.Equal(t, &Schema{AnyOf: []*Schema{{Type: Types{TypeNull}}, {Ref: "#/$defs/testSecret"}}},
+		secret.Properties["parent"])
+	for _, name := range []string{"apiVersion", "kind"} {
+		if _, ok := secret.Properties[name]; !ok {
+			t.Errorf("expected the inline property %s", name)
+		}
+	}
+	if _, ok := secret.Properties["internal"]; ok {
+		t.Errorf("unexpected unexported property")
+	}
+
+	expected := map[string]string{
+		"username":    `{"type":"string","minLength":1,"maxLength":16}`,
+		"secretID":    `{"type":"string","pattern":"^[a-z0-9]{2,8}$"}`,
+		"email":       `{"anyOf":[{"const":""},{"type":"string","format":"email"}]}`,
+		"algorithm":   `{"type":"string","enum":["rsa","ecdsa"]}`,
+		"expires":     `{"type":"integer","minimum":0,"exclusiveMaximum":100}`,
+		"tags":        `{"type":["array","null"],"items":{"type":"string","maxLength":8},"maxItems":3,"uniqueItems":true}`,
+		"path":        `{"anyOf":[{"const":""},{"description":"Path of an existing directory.","type":"string","format":"dir"}]}`,
+		"labels":      `{"type":["object","null"],"additionalProperties":{"type":"string","pattern":"^[a-zA-Z0-9]+$"}}`,
+		"data":        `{"type":["string","null"],"contentEncoding":"base64"}`,
+		"description": `{"description":"Free text description of at most 255 characters.","type":"string","format":"description","maxLength":255}`,
+	}
+	for name, schema := range expected {
+		data, _ := json.Marshal(secret.Properties[name])
+		assert.JSONEq(t, schema, string(data), name)
+	}
+
+	meta := s.Defs["ObjectMeta"]
+	assert.Equal(t, &Schema{Type: Types{TypeString}, Format: "date-time"}, meta.Properties["createdAt"])
+	assert.Equal(t, Types{TypeInteger}, meta.Properties["id"].Type)
+	assert.Equal(t, "name", meta.Properties["name"].Format)
+
+	var decoded Schema
+	data, _ := json.Marshal(s)
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(&decoded, s) {
+		t.Errorf("the schema does not round trip: %v", err)
+	}
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	c := OpenAPIComponents(testSecret{})
+	if _, ok := c.Schemas["ObjectMeta"]; !ok {
+		t.Errorf("expected the ObjectMeta component")
+	}
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/ObjectMeta"}, c.Schemas["testSecret"].Properties["metadata"])
+
+	errs := c.Validate("testSecret", []byte(`{"username":"colin","secretID":"abc","algorithm":"rsa","expires":1,
+		"metadata":{"name":"secret","createdAt":"yesterday"}}`))
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("metadata", "createdAt"), "yesterday", "must be a valid date-time"),
+	}, errs)
+	if errs := c.Validate("unknown", []byte(`{}`)); len(errs) != 1 || errs[0].Type != field.ErrorTypeInternal {
+		t.Errorf("expected an internal error, got %v", errs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := JSONSchema(&testSecret{})
+
+	valid := `{"username":"colin","secretID":"abc123","email":"colin@example.com","algorithm":"rsa","expires":99,
+		"tags":["a","b"],"path":"/not/checked","labels":{"env":"prod"},"parent":{"username":"a","secretID":"xy"}}`
+	assert.Nil(t, s.Validate([]byte(valid)))
+
+	invalid := `{"username":"a-very-long-username","secretID":"ABC","email":"colin","algorithm":"dsa","expires":100,
+		"tags":["a","a","b","too-long-tag"],"labels":{"env":"prod!"},"parent":{"username":1},"extra":true}`
+	expected := field.ErrorList{
+		field.NotSupported(field.NewPath("algorithm"), "dsa", []string{"rsa", "ecdsa"}),
+		field.Invalid(field.NewPath("email"), "colin", "must be a valid email"),
+		field.Invalid(field.NewPath("expires"), json.Number("100"), "must be less than 100"),
+		field.Invalid(field.NewPath("labels").Key("env"), "prod!", `must match the regular expression "^[a-zA-Z0-9]+$"`),
+		field.Required(field.NewPath("parent", "secretID"), ""),
+		field.Invalid(field.NewPath("parent", "username"), json.Number("1"), "must be of type string"),
+		field.Invalid(field.NewPath("secretID"), "ABC", `must match the regular expression "^[a-z0-9]{2,8}$"`),
+		field.TooMany(field.NewPath("tags"), 4, 3),
+		field.Duplicate(field.NewPath("tags").Index(1), "a"),
+		field.TooLong(field.NewPath("tags").Index(3), "too-long-tag", 8),
+		field.TooLong(field.NewPath("username"), "a-very-long-username", 16),
+	}
+	assert.Equal(t, expected, s.Validate([]byte(invalid)))
+
+	if errs := s.Validate([]byte(`{`)); len(errs) != 1 {
+		t.Errorf("expected a syntax error, got %v", errs)
+	}
+}
+
+type testSpec struct {
+	Name     string   `json:"name"               validate:"omitempty,min=3"`
+	Owner    *string  `json:"owner,omitempty"    validate:"omitempty,min=3"`
+	Email    string   `json:"email"              validate:"omitempty,email"`
+	Replicas int      `json:"replicas"           validate:"omitempty,min=1"`
+	Tags     []string `json:"tags"               validate:"max=2"`
+	Region   *string  `json:"region,omitempty"   validate:"omitempty,oneof=sh bj"`
+	Secret   *string  `json:"secret,omitempty"   validate:"required"`
+}
+
+// TestValidatorAgreement checks that the schema accepts the values the validate tags accept.
+func TestValidatorAgreement(t *testing.T) {
+	s := JSONSchema(testSpec{})
+
+	tests := []struct {
+		data  string
+		valid bool
+	}{
+		{data: `{"name":"","owner":null,"email":"","secret":"x"}`, valid: true},
+		{data: `{"name":"colin","owner":"colin","email":"colin@example.com","replicas":3,"secret":"x"}`, valid: true},
+		{data: `{"replicas":0,"tags":null,"region":null,"secret":"x"}`, valid: true},
+		{data: `{"region":"sh","secret":"x"}`, valid: true},
+		{data: `{"name":"ab","secret":"x"}`, valid: false},
+		{data: `{"owner":"ab","secret":"x"}`, valid: false},
+		{data: `{"email":"colin","secret":"x"}`, valid: false},
+		{data: `{"replicas":-1,"secret":"x"}`, valid: false},
+		{data: `{"tags":["a","b","c"],"secret":"x"}`, valid: false},
+		{data: `{"region":"gz","secret":"x"}`, valid: false},
+		{data: `{"secret":null}`, valid: false},
+	}
+	for _, test := range tests {
+		var spec testSpec
+		if err := json.Unmarshal([]byte(test.data), &spec); err != nil {
+			t.Fatal(err)
+		}
+		goErrs := validation.NewValidator(&spec).Validate()
+		schemaErrs := s.Validate([]byte(test.data))
+		assert.Equal(t, test.valid, len(goErrs) == 0, "validator: %s: %v", test.data, goErrs)
+		assert.Equal(t, test.valid, len(schemaErrs) == 0, "schema: %s: %v", test.data, schemaErrs)
+	}
+}
+
+type testQuoted struct {
+	Ratio   float64       `json:"ratio,string"`
+	Count   *int          `json:"count,string,omitempty" validate:"omitempty,min=1"`
+	Enabled bool          `json:"enabled,string"`
+	Name    string        `json:"name,string"            validate:"required"`
+	Size    uint          `json:"size,string"`
+	Timeout time.Duration `json:"timeout,string"`
+}
+
+func TestQuotedFields(t *testing.T) {
+	s := JSONSchema(testQuoted{})
+
+	count := 2
+	data, _ := json.Marshal(testQuoted{Ratio: -1.5e-7, Count: &count, Enabled: true, Name: "a\nb", Timeout: time.Second})
+	assert.Nil(t, s.Validate(data), string(data))
+	assert.Len(t, s.Validate([]byte(`{"ratio":1.5,"enabled":"yes","name":"a","size":"-1","timeout":"1s"}`)), 5)
+}
+
+// TestMarshalledObjects checks that the schemas accept the JSON encoding of their types.
+func TestMarshalledObjects(t *testing.T) {
+	for _, obj := range []interface{}{
+		&testSecret{Username: "colin", SecretID: "abc123", Algorithm: "rsa"},
+		&testSpec{Secret: new(string)},
+		&testQuoted{Name: "colin"},
+	} {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, JSONSchema(obj).Validate(data), "%T: %s", obj, data)
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/marmotedu/component-base/pkg/json"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// Validate validates raw JSON against the schema. The references are resolved against the
+// $defs of s. The formats which cannot be checked, e.g. dir or file, are ignored.
+func (s *Schema) Validate(data []byte) field.ErrorList {
+	return validateData(s, s.Defs, data)
+}
+
+// Validate validates raw JSON against the component schema named name.
+func (c *Components) Validate(name string, data []byte) field.ErrorList {
+	s, ok := c.Schemas[name]
+	if !ok {
+		return field.ErrorList{field.InternalError(field.NewPath(""), fmt.Errorf("unknown schema %q", name))}
+	}
+
+	return validateData(s, c.Schemas, data)
+}
+
+func validateData(s *Schema, defs map[string]*Schema, data []byte) field.ErrorList {
+	var value interface{}
+	if err := json.UnmarshalWithOptions(data, &value, json.UseNumber()); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath(""), string(data), err.Error())}
+	}
+
+	v := &validator{defs: defs}
+	v.validate(s, value, nil)
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return v.errs
+}
+
+type validator struct {
+	defs map[string]*Schema
+	errs field.ErrorList
+}
+
+func (v *validator) validate(s *Schema, value interface{}, fldPath *field.Path) {
+	path := fldPath
+	if path == nil {
+		path = field.NewPath("")
+	}
+
+	if len(s.Ref) > 0 {
+		ref, ok := v.defs[s.Ref[strings.LastIndex(s.Ref, "/")+1:]]
+		if !ok {
+			v.errs = append(v.errs, field.InternalError(path, fmt.Errorf("unresolved reference %q", s.Ref)))
+
+			return
+		}
+		v.validate(ref, value, fldPath)
+	}
+
+	if len(s.AnyOf) > 0 && !v.validateAnyOf(s.AnyOf, value, fldPath) {
+		return
+	}
+	if s.Const != nil && !equal(s.Const, value) {
+		v.errs = append(v.errs, field.Invalid(path, value, fmt.Sprintf("must be %v", s.Const)))
+
+		return
+	}
+	if len(s.Type) > 0 && !s.Type.Has(typeOf(value)) && !(s.Type.Has(TypeNumber) && typeOf(value) == TypeInteger) {
+		v.errs = append(v.errs, field.Invalid(path, value, fmt.Sprintf("must be of type %s", strings.Join(s.Type, " or "))))
+
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		supported := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			supported = append(supported, fmt.Sprint(e))
+		}
+		v.errs = append(v.errs, field.NotSupported(path, value, supported))
+	}
+
+	switch x := value.(type) {
+	case string:
+		v.validateString(s, x, path)
+	case json.Number:
+		v.validateNumber(s, x, path)
+	case []interface{}:
+		v.validateArray(s, x, fldPath)
+	case map[string]interface{}:
+		v.validateObject(s, x, fldPath)
+	}
+}
+
+// validateAnyOf returns true if value is valid against one of the schemas. Otherwise, the
+// errors of the last schema are reported.
+func (v *validator) validateAnyOf(schemas []*Schema, value interface{}, fldPath *field.Path) bool {
+	var errs field.ErrorList
+	for _, s := range schemas {
+		sub := &validator{defs: v.defs}
+		sub.validate(s, value, fldPath)
+		if len(sub.errs) == 0 {
+			return true
+		}
+		errs = sub.errs
+	}
+	v.errs = append(v.errs, errs...)
+
+	return false
+}
+
+func (v *validator) validateString(s *Schema, value string, path *field.Path) {
+	length := utf8.RuneCountInString(value)
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.errs = append(v.errs, field.TooLong(path, value, *s.MaxLength))
+	}
+	if s.MinLength != nil && length < *s.MinLength {
+		v.errs = append(v.errs, field.Invalid(path, value, fmt.Sprintf("must have at least %d characters", *s.MinLength)))
+	}
+	if len(s.Pattern) > 0 {
+		re, err := compile(s.Pattern)
+		if err != nil {
+			v.errs = append(v.errs, field.InternalError(path, err))
+		} else if !re.MatchString(value) {
+			v.errs = append(v.errs, field.Invalid(path, value, fmt.Sprintf("must match the regular expression %q", s.Pattern)))
+		}
+	}
+	if check, ok := formatCheckers[s.Format]; ok && !check(value) {
+		v.errs = append(v.errs, field.Invalid(path, value, fmt.Sprintf("must be a valid %s", s.Format)))
+	}
+}
+
+func (v *validator) validateNumber(s *Schema, value json.Number, path *field.Path) {
+	f, err := value.Float64()
+	if err != nil {
+		v.errs = append(v.errs, field.Invalid(path, value, err.Error()))
+
+		return
+	}
+
+	for _, bound := range []struct {
+		limit  *float64
+		fails  bool
+		detail string
+	}{
+		{s.Minimum, s.Minimum != nil && f < *s.Minimum, "must be greater than or equal to %v"},
+		{s.Maximum, s.Maximum != nil && f > *s.Maximum, "must be less than or equal to %v"},
+		{s.ExclusiveMinimum, s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum, "must be greater than %v"},
+		{s.ExclusiveMaximum, s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum, "must be less than %v"},
+	} {
+		if bound.fails {
+			v.errs = append(v.errs, field.Invalid(path, value, fmt.Sprintf(bound.detail, *bound.limit)))
+		}
+	}
+}
+
+func (v *validator) validateArray(s *Schema, items []interface{}, fldPath *field.Path) {
+	path := fldPath
+	if path == nil {
+		path = field.NewPath("")
+	}
+
+	if s.MaxItems != nil && len(items) > *s.MaxItems {
+		v.errs = append(v.errs, field.TooMany(path, len(items), *s.MaxItems))
+	}
+	if s.MinItems != nil && len(items) < *s.MinItems {
+		v.errs = append(v.errs, field.Invalid(path, len(items), fmt.Sprintf("must have at least %d items", *s.MinItems)))
+	}
+	for i, item := range items {
+		if s.UniqueItems {
+			for j := 0; j < i; j++ {
+				if equal(items[j], item) {
+					v.errs = append(v.errs, field.Duplicate(path.Index(i), item))
+
+					break
+				}
+			}
+		}
+		if s.Items != nil {
+			v.validate(s.Items, item, path.Index(i))
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, object map[string]interface{}, fldPath *field.Path) {
+	path := fldPath
+	if path == nil {
+		path = field.NewPath("")
+	}
+	child := func(name string) *field.Path {
+		if fldPath == nil {
+			return field.NewPath(name)
+		}
+
+		return fldPath.Child(name)
+	}
+
+	if s.MaxProperties != nil && len(object) > *s.MaxProperties {
+		v.errs = append(v.errs, field.TooMany(path, len(object), *s.MaxProperties))
+	}
+	if s.MinProperties != nil && len(object) < *s.MinProperties {
+		v.errs = append(v.errs, field.Invalid(path, len(object),
+			fmt.Sprintf("must have at least %d properties", *s.MinProperties)))
+	}
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			v.errs = append(v.errs, field.Required(child(name), ""))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if property, ok := s.Properties[name]; ok {
+			v.validate(property, object[name], child(name))
+		} else if s.AdditionalProperties != nil {
+			v.validate(s.AdditionalProperties, object[name], path.Key(name))
+		}
+	}
+}
+
+// typeOf returns the JSON Schema type of a value decoded with UseNumber.
+func typeOf(value interface{}) string {
+	switch x := value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case json.Number:
+		if f, err := x.Float64(); err == nil && f == math.Trunc(f) {
+			return TypeInteger
+		}
+
+		return TypeNumber
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	default:
+		return TypeObject
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if equal(e, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares JSON values, the numbers by value.
+func equal(a, b interface{}) bool {
+	fa, aok := toFloat(a)
+	fb, bok := toFloat(b)
+	if aok || bok {
+		return aok && bok && fa == fb
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		f, err := x.Float64()
+
+		return f, err == nil
+	case int64:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case float64:
+		return x, true
+	default:
+		return 0, false
+	}
+}
+
+var patterns sync.Map
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+
+	return re, nil
+}
+
+var (
+	uuidRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*` +
+		`[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// formatCheckers check the standard formats. The other formats are annotations only.
+var formatCheckers = map[string]func(string) bool{
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+
+		return err == nil && addr.Address == s
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+
+		return err == nil && u.IsAbs()
+	},
+	"uuid": uuidRegexp.MatchString,
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, s)
+
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+
+		return err == nil
+	},
+	"ipv4": func(s string) bool {
+		ip := net.ParseIP(s)
+
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	},
+	"ipv6": func(s string) bool {
+		ip := net.ParseIP(s)
+
+		return ip != nil && strings.Contains(s, ":")
+	},
+	"hostname": func(s string) bool {
+		return len(s) <= 253 && hostnameRegexp.MatchString(s)
+	},
+}
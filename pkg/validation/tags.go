@@ -0,0 +1,274 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// Tag is a custom validation tag, used in the validate struct tags like the tags of
+// go-playground/validator.
+type Tag struct {
+	// Name is the name of the tag, e.g. "dir".
+	Name string
+
+	// Func returns false if the field is invalid.
+	Func validator.Func
+
+	// Type is the type of the errors reported for the tag. Defaults to
+	// field.ErrorTypeInvalid.
+	Type field.ErrorType
+
+	// Translation is the english message of the errors reported for the tag. {0} is
+	// replaced by the name of the field, {1} by its value and {2} by the tag parameter,
+	// e.g. "{0} must point to an existing directory, but found '{1}'".
+	Translation string
+
+	// CallEvenIfNull calls Func for nil pointers and other nil values too.
+	CallEvenIfNull bool
+}
+
+var (
+	tagsLock sync.RWMutex
+	tags     = map[string]Tag{}
+)
+
+// RegisterTag registers a custom validation tag, for the validators created afterwards.
+// It returns an error if a tag with the same name has already been registered.
+func RegisterTag(tag Tag) error {
+	if len(tag.Name) == 0 || tag.Func == nil {
+		return errors.New("a validation tag must have a name and a function")
+	}
+	if len(tag.Type) == 0 {
+		tag.Type = field.ErrorTypeInvalid
+	}
+
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+
+	if _, ok := tags[tag.Name]; ok {
+		return fmt.Errorf("validation tag %q is already registered", tag.Name)
+	}
+	tags[tag.Name] = tag
+
+	return nil
+}
+
+// MustRegisterTag is like RegisterTag but panics on error.
+func MustRegisterTag(tag Tag) {
+	if err := RegisterTag(tag); err != nil {
+		panic(err)
+	}
+}
+
+// registeredTags returns a copy of the registered tags.
+func registeredTags() []Tag {
+	tagsLock.RLock()
+	defer tagsLock.RUnlock()
+
+	result := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag)
+	}
+
+	return result
+}
+
+// tagErrorType returns the type of the errors reported for a tag.
+func tagErrorType(tag string) field.ErrorType {
+	tagsLock.RLock()
+	t, ok := tags[tag]
+	tagsLock.RUnlock()
+	if ok {
+		return t.Type
+	}
+
+	switch {
+	case strings.HasPrefix(tag, "required"):
+		return field.ErrorTypeRequired
+	case strings.HasPrefix(tag, "excluded"):
+		return field.ErrorTypeForbidden
+	case tag == "oneof":
+		return field.ErrorTypeNotSupported
+	case tag == "unique":
+		return field.ErrorTypeDuplicate
+	default:
+		return field.ErrorTypeInvalid
+	}
+}
+
+// toFieldError converts an error reported by go-playground/validator into the
+// *field.Error matching its tag, e.g. a "required" tag into a field.Required error.
+func toFieldError(fe validator.FieldError, path *field.Path, detail string) *field.Error {
+	value := fe.Value()
+
+	switch t := tagErrorType(fe.Tag()); t {
+	case field.ErrorTypeRequired:
+		return field.Required(path, detail)
+	case field.ErrorTypeForbidden:
+		return field.Forbidden(path, detail)
+	case field.ErrorTypeNotSupported:
+		if fe.Tag() == "oneof" {
+			return field.NotSupported(path, value, strings.Fields(fe.Param()))
+		}
+
+		return &field.Error{Type: t, Field: path.String(), BadValue: value, Detail: detail}
+	case field.ErrorTypeInternal:
+		return field.InternalError(path, errors.New(detail))
+	case field.ErrorTypeInvalid:
+		if fe.Tag() == "max" || fe.Tag() == "lte" {
+			if err := lengthError(fe, path); err != nil {
+				return err
+			}
+		}
+
+		return field.Invalid(path, value, detail)
+	default:
+		return &field.Error{Type: t, Field: path.String(), BadValue: value, Detail: detail}
+	}
+}
+
+// lengthError returns a field.TooLong error for the strings, and a field.TooMany error
+// for the slices and maps, which exceed a max or lte tag. It returns nil for the other
+// kinds.
+func lengthError(fe validator.FieldError, path *field.Path) *field.Error {
+	max, err := strconv.Atoi(fe.Param())
+	if err != nil {
+		return nil
+	}
+
+	//nolint: exhaustive
+	switch fe.Kind() {
+	case reflect.String:
+		return field.TooLong(path, fe.Value(), max)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return field.TooMany(path, reflect.ValueOf(fe.Value()).Len(), max)
+	default:
+		return nil
+	}
+}
+
+// jsonName returns the name of a struct field in JSON, and false if the field is
+// inlined in its parent.
+func jsonName(sf reflect.StructField) (string, bool) {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	switch {
+	case name == "-":
+		return sf.Name, true
+	case len(name) > 0:
+		return name, true
+	case sf.Anonymous:
+		return "", false
+	default:
+		return sf.Name, true
+	}
+}
+
+// jsonPath converts the Go namespace of a field reported by go-playground/validator,
+// e.g. "Secret.ObjectMeta.Labels[app]", into its path in JSON, e.g. "metadata.labels[app]",
+// by walking the fields of t. The root struct name is dropped.
+func jsonPath(t reflect.Type, namespace string) *field.Path {
+	var path *field.Path
+	child := func(name string) {
+		if path == nil {
+			path = field.NewPath(name)
+		} else {
+			path = path.Child(name)
+		}
+	}
+
+	segments := splitNamespace(namespace)
+	if len(segments) > 0 {
+		segments = segments[1:]
+	}
+	for _, segment := range segments {
+		name, subscripts := segment, []string(nil)
+		if i := strings.Index(segment, "["); i >= 0 {
+			name, subscripts = segment[:i], strings.Split(segment[i+1:len(segment)-1], "][")
+		}
+
+		t = indirect(t)
+		inline := false
+		if t != nil && t.Kind() == reflect.Struct {
+			if sf, ok := t.FieldByName(name); ok {
+				var jsonOK bool
+				if name, jsonOK = jsonName(sf); !jsonOK {
+					inline = true
+				}
+				t = sf.Type
+			} else {
+				t = nil
+			}
+		} else {
+			t = nil
+		}
+		if !inline {
+			child(name)
+		}
+
+		for _, s := range subscripts {
+			t = indirect(t)
+			if t != nil && t.Kind() == reflect.Map {
+				path = path.Key(s)
+			} else if i, err := strconv.Atoi(s); err == nil {
+				path = path.Index(i)
+			} else {
+				path = path.Key(s)
+			}
+			if t != nil && (t.Kind() == reflect.Map || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+		}
+	}
+
+	if path == nil {
+		return field.NewPath("")
+	}
+
+	return path
+}
+
+// splitNamespace splits a namespace on the dots which are not in a subscript.
+func splitNamespace(namespace string) []string {
+	var (
+		segments []string
+		depth    int
+		start    int
+	)
+	for i := 0; i < len(namespace); i++ {
+		switch namespace[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, namespace[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(segments, namespace[start:])
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
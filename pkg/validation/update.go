@@ -0,0 +1,176 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// ImmutableTag marks the fields which cannot be changed by an update, e.g.
+// `validate:"required,immutable"`. It is checked by ValidateUpdate only.
+const ImmutableTag = "immutable"
+
+// ValidateCreate validates an object being created, with its validate tags and its rules.
+func ValidateCreate(obj interface{}) field.ErrorList {
+	return NewValidator(obj).Validate()
+}
+
+// ValidateUpdate validates an object being updated from old to obj. The fields tagged
+// immutable must not have changed, and obj is validated like ValidateCreate does, except
+// that the errors on the fields which have not changed are dropped: the values which were
+// accepted before a rule was tightened can be kept.
+//
+// The errors are ratcheted on the field they are reported on. A rule reported on a field
+// but depending on other fields is ratcheted when that field is unchanged.
+func ValidateUpdate(old, obj interface{}) field.ErrorList {
+	oldValue, newValue := indirectValue(reflect.ValueOf(old)), indirectValue(reflect.ValueOf(obj))
+	if !oldValue.IsValid() || !newValue.IsValid() || oldValue.Type() != newValue.Type() {
+		return field.ErrorList{
+			field.InternalError(field.NewPath(""), fmt.Errorf("cannot validate the update of %T to %T", old, obj)),
+		}
+	}
+
+	allErrs := field.ErrorList{}
+	validateImmutable(oldValue, newValue, nil, &allErrs)
+	for _, err := range NewValidator(obj).Validate() {
+		if err.Type != field.ErrorTypeInternal && unchanged(oldValue, newValue, err.Field) {
+			continue
+		}
+		allErrs = append(allErrs, err)
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return allErrs
+}
+
+// validateImmutable reports the fields tagged immutable whose value differs between old
+// and new, which have the same type.
+func validateImmutable(old, new reflect.Value, fldPath *field.Path, allErrs *field.ErrorList) {
+	old, new = indirectValue(old), indirectValue(new)
+	if !old.IsValid() || !new.IsValid() {
+		return
+	}
+
+	//nolint: exhaustive
+	switch new.Kind() {
+	case reflect.Struct:
+		t := new.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			path := fldPath
+			if name, ok := jsonName(sf); ok {
+				path = childPath(fldPath, name)
+			}
+			if !isImmutable(sf) {
+				validateImmutable(old.Field(i), new.Field(i), path, allErrs)
+
+				continue
+			}
+			if !reflect.DeepEqual(old.Field(i).Interface(), new.Field(i).Interface()) {
+				*allErrs = append(*allErrs, field.Invalid(path, fieldInterface(new.Field(i)), "field is immutable").
+					WithOrigin(ImmutableTag))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < old.Len() && i < new.Len(); i++ {
+			validateImmutable(old.Index(i), new.Index(i), indexPath(fldPath, i), allErrs)
+		}
+	case reflect.Map:
+		iter := new.MapRange()
+		for iter.Next() {
+			if oldValue := old.MapIndex(iter.Key()); oldValue.IsValid() {
+				key := fmt.Sprint(iter.Key().Interface())
+				validateImmutable(oldValue, iter.Value(), keyPath(fldPath, key), allErrs)
+			}
+		}
+	}
+}
+
+// isImmutable returns true if a field has the immutable tag, before any dive.
+func isImmutable(sf reflect.StructField) bool {
+	_, ok := fieldTag(sf, ImmutableTag)
+
+	return ok
+}
+
+// fieldTag returns the parameter of the validate tag name of a field, e.g. "3" for min=3,
+// and true if the field has the tag before any dive.
+func fieldTag(sf reflect.StructField, name string) (string, bool) {
+	for _, tag := range strings.Split(sf.Tag.Get("validate"), ",") {
+		if tag == "dive" {
+			return "", false
+		}
+		parts := strings.SplitN(tag, "=", 2)
+		if parts[0] != name {
+			continue
+		}
+		if len(parts) == 2 {
+			return parts[1], true
+		}
+
+		return "", true
+	}
+
+	return "", false
+}
+
+// unchanged returns true if the field at path, as reported in a field.Error, has the same
+// value in old and new.
+func unchanged(old, new reflect.Value, path string) bool {
+	p, err := field.ParsePath(path)
+	if err != nil {
+		return false
+	}
+	oldField, err := field.Resolve(old.Interface(), p)
+	if err != nil {
+		return false
+	}
+	newField, err := field.Resolve(new.Interface(), p)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(oldField, newField)
+}
+
+// indirectValue dereferences the pointers and interfaces of v. It returns an invalid value
+// for nil.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// fieldInterface returns the value of a field, nil for the nil pointers.
+func fieldInterface(v reflect.Value) interface{} {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+// validateNothing is the function of the tags which are only markers for other validations,
+// e.g. the immutable tag.
+func validateNothing(validator.FieldLevel) bool {
+	return true
+}
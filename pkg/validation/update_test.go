@@ -0,0 +1,70 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+type testVolume struct {
+	Name string `json:"name" validate:"required,immutable"`
+	Size int    `json:"size" validate:"min=1,max=100"`
+}
+
+type testPolicy struct {
+	Name        string                 `json:"name"        validate:"required,immutable,max=10"`
+	Owner       *string                `json:"owner"       validate:"omitempty,immutable"`
+	Description string                 `json:"description" validate:"max=16"`
+	Volumes     []testVolume           `json:"volumes"     validate:"dive"`
+	Extra       map[string]*testVolume `json:"extra"`
+}
+
+func TestValidateCreateAndUpdate(t *testing.T) {
+	owner, otherOwner := "colin", "lingfei"
+	old := &testPolicy{
+		Name:        "read-only",
+		Owner:       &owner,
+		Description: "a description too long for the tightened rule",
+		Volumes:     []testVolume{{Name: "data", Size: 200}, {Name: "logs", Size: 1}},
+		Extra:       map[string]*testVolume{"cache": {Name: "cache", Size: 1}},
+	}
+
+	// the old object is invalid against the tightened rules
+	assert.Equal(t, field.ErrorList{
+		field.TooLong(field.NewPath("description"), old.Description, 16).WithOrigin("max"),
+		field.Invalid(field.NewPath("volumes").Index(0).Child("size"), 200, "size must be 100 or less").WithOrigin("max"),
+	}, ValidateCreate(old))
+
+	// the unchanged invalid values are ratcheted
+	updated := *old
+	updated.Volumes = []testVolume{{Name: "data", Size: 200}, {Name: "logs", Size: 0}}
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("volumes").Index(1).Child("size"), 0, "size must be 1 or greater").WithOrigin("min"),
+	}, ValidateUpdate(old, &updated))
+
+	// the changed values are validated, and the immutable fields must not change
+	updated = *old
+	updated.Name = "read-write"
+	updated.Owner = &otherOwner
+	updated.Description = "another description too long"
+	updated.Volumes = []testVolume{{Name: "data", Size: 200}, {Name: "log", Size: 1}}
+	updated.Extra = map[string]*testVolume{"cache": {Name: "tmp", Size: 1}, "new": {Name: "new", Size: 1}}
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("name"), "read-write", "field is immutable").WithOrigin(ImmutableTag),
+		field.Invalid(field.NewPath("owner"), "lingfei", "field is immutable").WithOrigin(ImmutableTag),
+		field.Invalid(field.NewPath("volumes").Index(1).Child("name"), "log", "field is immutable").WithOrigin(ImmutableTag),
+		field.Invalid(field.NewPath("extra").Key("cache").Child("name"), "tmp", "field is immutable").WithOrigin(ImmutableTag),
+		field.TooLong(field.NewPath("description"), updated.Description, 16).WithOrigin("max"),
+	}, ValidateUpdate(old, &updated))
+
+	assert.Nil(t, ValidateUpdate(old, old))
+	if errs := ValidateUpdate(old, &testVolume{}); len(errs) != 1 || errs[0].Type != field.ErrorTypeInternal {
+		t.Errorf("expected an internal error, got %v", errs)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 
 	english "github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -28,15 +30,157 @@ type Validator struct {
 	trans ut.Translator
 }
 
-// NewValidator creates a new Validator.
+func init() {
+	MustRegisterTag(Tag{
+		Name:        "dir",
+		Func:        validateDir,
+		Translation: "{0} must point to an existing directory, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "file",
+		Func:        validateFile,
+		Translation: "{0} must point to an existing file, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "description",
+		Func:        validateDescription,
+		Type:        field.ErrorTypeTooLong,
+		Translation: fmt.Sprintf("must have at most %d bytes", maxDescriptionLength),
+	})
+	MustRegisterTag(Tag{
+		Name:        "name",
+		Func:        validateName,
+		Translation: "is not a valid name",
+	})
+	MustRegisterTag(Tag{
+		Name:        "regexp",
+		Func:        validateRegexp,
+		Translation: "{0} is '{1}', but must match the regular expression '{2}'",
+	})
+	MustRegisterTag(Tag{
+		Name: ImmutableTag,
+		Func: validateNothing,
+	})
+	MustRegisterTag(Tag{
+		Name: DeprecatedTag,
+		Func: validateNothing,
+	})
+
+	// files and directories, the paths are checked by the dir and file tags. max_mode,
+	// secret_file and owner do not check the permissions and the owner on windows.
+	MustRegisterTag(Tag{
+		Name:        "readable",
+		Func:        accessTagFunc(accessRead),
+		Translation: "{0} must point to a readable path, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "writable",
+		Func:        accessTagFunc(accessWrite),
+		Translation: "{0} must point to a writable path, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "executable",
+		Func:        accessTagFunc(accessExecute),
+		Translation: "{0} must point to an executable path, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "max_mode",
+		Func:        validateMaxMode,
+		Translation: "{0} is '{1}', but must have at most the permissions {2}",
+	})
+	MustRegisterTag(Tag{
+		Name:        "secret_file",
+		Func:        validateSecretFile,
+		Translation: "{0} must point to a file which is not accessible by the group or the others, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "owner",
+		Func:        validateOwner,
+		Translation: "{0} is '{1}', but must be owned by {2}",
+	})
+	MustRegisterTag(Tag{
+		Name:        "max_size",
+		Func:        validateMaxSize,
+		Translation: "{0} is '{1}', but must be a file of at most {2} bytes",
+	})
+	MustRegisterTag(Tag{
+		Name:        "filetype",
+		Func:        validateFileType,
+		Translation: "{0} is '{1}', but must be a file of type {2}",
+	})
+	MustRegisterTag(Tag{
+		Name:        "tls_cert",
+		Func:        validateTLSCert,
+		Translation: "{0} must point to a valid PEM certificate, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "tls_keypair",
+		Func:        validateTLSKeyPair,
+		Translation: "{0} is '{1}', but must be a PEM certificate matching the private key of {2}",
+	})
+
+	// network and identity formats, the tags of validator, e.g. email, e164, uuid or
+	// cidrv4, are kept
+	MustRegisterTag(Tag{
+		Name:        "cidr_prefix",
+		Func:        validateCIDRPrefix,
+		Translation: "{0} is '{1}', but must be a CIDR value with a prefix length of {2}",
+	})
+	MustRegisterTag(Tag{
+		Name:        "port_range",
+		Func:        stringTagFunc(IsValidPortRange),
+		Translation: "{0} must be a port number or a range of port numbers, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "host_port",
+		Func:        stringTagFunc(IsValidHostPort),
+		Translation: "{0} must be a host and a port, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "url_scheme",
+		Func:        validateURLScheme,
+		Translation: "{0} is '{1}', but must be an absolute URL with one of the schemes '{2}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "semver",
+		Func:        stringTagFunc(IsValidSemver),
+		Translation: "{0} must be a semantic version, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "cron",
+		Func:        stringTagFunc(IsValidCron),
+		Translation: "{0} must be a cron schedule, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "duration",
+		Func:        stringTagFunc(IsValidDuration),
+		Translation: "{0} must be a duration, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "ulid",
+		Func:        stringTagFunc(IsValidULID),
+		Translation: "{0} must be a ULID, but found '{1}'",
+	})
+	MustRegisterTag(Tag{
+		Name:        "instanceid",
+		Func:        validateInstanceID,
+		Translation: "{0} must be an instance ID, but found '{1}'",
+	})
+}
+
+// NewValidator creates a new Validator, with the validation tags of go-playground/validator
+// and the tags registered with RegisterTag.
 func NewValidator(data interface{}) *Validator {
 	result := validator.New()
 
-	// independent validators
-	result.RegisterValidation("dir", validateDir)                 // nolint: errcheck // no need
-	result.RegisterValidation("file", validateFile)               // nolint: errcheck // no need
-	result.RegisterValidation("description", validateDescription) // nolint: errcheck // no need
-	result.RegisterValidation("name", validateName)               // nolint: errcheck // no need
+	// report the fields with their json names
+	result.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		if name, ok := jsonName(sf); ok && name != sf.Name {
+			return name
+		}
+
+		return ""
+	})
 
 	// default translations
 	eng := english.New()
@@ -47,30 +191,15 @@ func NewValidator(data interface{}) *Validator {
 		panic(err)
 	}
 
-	// additional translations
-	translations := []struct {
-		tag         string
-		translation string
-	}{
-		{
-			tag:         "dir",
-			translation: "{0} must point to an existing directory, but found '{1}'",
-		},
-		{
-			tag:         "file",
-			translation: "{0} must point to an existing file, but found '{1}'",
-		},
-		{
-			tag:         "description",
-			translation: fmt.Sprintf("must be less than %d", maxDescriptionLength),
-		},
-		{
-			tag:         "name",
-			translation: "is not a invalid name",
-		},
-	}
-	for _, t := range translations {
-		err = result.RegisterTranslation(t.tag, trans, registrationFunc(t.tag, t.translation), translateFunc)
+	// registered tags and their translations
+	for _, t := range registeredTags() {
+		if err := result.RegisterValidation(t.Name, t.Func, t.CallEvenIfNull); err != nil {
+			panic(err)
+		}
+		if len(t.Translation) == 0 {
+			continue
+		}
+		err = result.RegisterTranslation(t.Name, trans, registrationFunc(t.Name, t.Translation), translateFunc)
 		if err != nil {
 			panic(err)
 		}
@@ -94,7 +223,7 @@ func registrationFunc(tag string, translation string) validator.RegisterTranslat
 }
 
 func translateFunc(ut ut.Translator, fe validator.FieldError) string {
-	t, err := ut.T(fe.Tag(), fe.Field(), reflect.ValueOf(fe.Value()).String())
+	t, err := ut.T(fe.Tag(), fe.Field(), fmt.Sprint(fe.Value()), fe.Param())
 	if err != nil {
 		return fe.(error).Error()
 	}
@@ -102,32 +231,49 @@ func translateFunc(ut ut.Translator, fe validator.FieldError) string {
 	return t
 }
 
-// Validate validates config for errors and returns an error (it can be casted to
-// ValidationErrors, containing a list of errors inside). When error is printed as string, it will
-// automatically contains the full list of validation errors.
+// Validate validates config for errors and returns the list of the field errors. The
+// errors are typed after the failed tags, e.g. field.ErrorTypeRequired for the required
+// tags or field.ErrorTypeNotSupported for the oneof tags, their paths are made of the json
+// names of the fields, and their origins are the tags. The rules declared with RuleProvider
+// or RegisterRules are validated too.
 func (v *Validator) Validate() field.ErrorList {
-	// validate policy
-	err := v.val.Struct(v.data)
-	if err == nil {
+	result := v.ValidateWithWarnings()
+	if result.Valid() {
 		return nil
 	}
 
-	// this check is only needed when your code could produce
-	// an invalid value for validation such as interface with nil
-	// value most including myself do not usually have code like this.
-	if _, ok := err.(*validator.InvalidValidationError); ok {
-		return field.ErrorList{field.Invalid(field.NewPath(""), err.Error(), "")}
-	}
+	return result.Errors
+}
 
-	allErrs := field.ErrorList{}
+// ValidateWithWarnings validates config like Validate, and reports the warnings too: the
+// fields tagged deprecated which are set, and the failures of the rules declared as
+// warnings.
+func (v *Validator) ValidateWithWarnings() *Result {
+	result := &Result{Errors: field.ErrorList{}}
 
-	// collect human-readable errors
-	vErrors, _ := err.(validator.ValidationErrors)
-	for _, vErr := range vErrors {
-		allErrs = append(allErrs, field.Invalid(field.NewPath(vErr.Namespace()), vErr.Translate(v.trans), ""))
+	// validate policy
+	if err := v.val.Struct(v.data); err != nil {
+		// this check is only needed when your code could produce
+		// an invalid value for validation such as interface with nil
+		// value most including myself do not usually have code like this.
+		if _, ok := err.(*validator.InvalidValidationError); ok {
+			result.Errors = append(result.Errors, field.Invalid(field.NewPath(""), err.Error(), ""))
+
+			return result
+		}
+
+		// collect typed errors
+		t := reflect.TypeOf(v.data)
+		vErrors, _ := err.(validator.ValidationErrors)
+		for _, vErr := range vErrors {
+			path := jsonPath(t, vErr.StructNamespace())
+			result.Errors = append(result.Errors, toFieldError(vErr, path, vErr.Translate(v.trans)).WithOrigin(vErr.Tag()))
+		}
 	}
 
-	return allErrs
+	validateRules(reflect.ValueOf(v.data), nil, result)
+
+	return result
 }
 
 // validateDir checks if a given string is an existing directory.
@@ -166,3 +312,64 @@ func validateName(fl validator.FieldLevel) bool {
 
 	return true
 }
+
+// validateRegexp checks if a given string matches the regular expression of the tag
+// parameter, e.g. `validate:"regexp=^[a-z]+$"`. Commas and pipes must be written 0x2C
+// and 0x7C in the parameter.
+func validateRegexp(fl validator.FieldLevel) bool {
+	re, err := cachedRegexp(fl.Param())
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(fl.Field().String())
+}
+
+// stringTagFunc wraps a validation helper of a string into the function of a tag.
+func stringTagFunc(fn func(string) []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return len(fn(fl.Field().String())) == 0
+	}
+}
+
+// validateCIDRPrefix checks if a given string is a CIDR value whose prefix length is the
+// tag parameter, or in the inclusive range of the parameter, e.g.
+// `validate:"cidr_prefix=16-24"`.
+func validateCIDRPrefix(fl validator.FieldLevel) bool {
+	min, max, err := parseRange(fl.Param())
+	if err != nil {
+		return false
+	}
+
+	return len(IsValidCIDRPrefix(nil, fl.Field().String(), min, max)) == 0
+}
+
+// validateURLScheme checks if a given string is an absolute URL whose scheme is one of
+// the space separated schemes of the tag parameter, e.g. `validate:"url_scheme=http https"`.
+func validateURLScheme(fl validator.FieldLevel) bool {
+	return len(IsValidURL(fl.Field().String(), strings.Fields(fl.Param())...)) == 0
+}
+
+// validateInstanceID checks if a given string is an instance ID with the prefix of the tag
+// parameter, e.g. `validate:"instanceid=secret-"`, or with any prefix.
+func validateInstanceID(fl validator.FieldLevel) bool {
+	return len(IsValidInstanceID(fl.Field().String(), fl.Param())) == 0
+}
+
+// parseRange parses an inclusive range of integers, e.g. 16-24, or a single integer.
+func parseRange(s string) (int, int, error) {
+	lo, hi := s, s
+	if i := strings.Index(s, "-"); i > 0 {
+		lo, hi = s[:i], s[i+1:]
+	}
+	min, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := strconv.Atoi(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return min, max, nil
+}
@@ -9,7 +9,10 @@ import (
 	"os"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
 )
 
 // Base is the interface for all configs used in Aptomi (e.g. client config, server config).
@@ -160,3 +163,134 @@ func TestConfigValidation(t *testing.T) {
 		}
 	}
 }
+
+type testMeta struct {
+	Name   string            `json:"name"             validate:"required"`
+	Labels map[string]string `json:"labels,omitempty" validate:"dive,max=5"`
+}
+
+type testContainer struct {
+	Image string   `json:"image"          validate:"required,max=8"`
+	Ports []int    `json:"ports"          validate:"unique,dive,min=1"`
+	Pull  string   `json:"pullPolicy"     validate:"oneof=Always Never"`
+	Args  []string `json:"args,omitempty" validate:"max=2,even"`
+}
+
+type testSpec struct {
+	testMeta   `json:",inline"`
+	Containers []*testContainer `json:"containers" validate:"dive"`
+	Internal   string           `json:"-"          validate:"omitempty,ip"`
+}
+
+func TestValidatorFieldErrors(t *testing.T) {
+	MustRegisterTag(Tag{
+		Name: "even",
+		Func: func(fl validator.FieldLevel) bool {
+			return fl.Field().Len()%2 == 0
+		},
+		Type:        field.ErrorTypeForbidden,
+		Translation: "{0} must have an even number of items",
+	})
+	if err := RegisterTag(Tag{Name: "even", Func: func(validator.FieldLevel) bool { return true }}); err == nil {
+		t.Errorf("expected an error registering a tag twice")
+	}
+
+	spec := &testSpec{
+		testMeta: testMeta{Labels: map[string]string{"app": "component-base"}},
+		Containers: []*testContainer{
+			{Image: "nginx", Ports: []int{80, 0}, Pull: "Always", Args: []string{"-a", "-b", "-c"}},
+			{Image: "marmotedu/iam", Ports: []int{80, 80}, Pull: "IfNotPresent", Args: []string{"-v"}},
+		},
+		Internal: "not-an-ip",
+	}
+
+	containers := field.NewPath("containers")
+	expected := field.ErrorList{
+		field.Required(field.NewPath("name"), "name is a required field").WithOrigin("required"),
+		field.TooLong(field.NewPath("labels").Key("app"), "component-base", 5).WithOrigin("max"),
+		field.Invalid(containers.Index(0).Child("ports").Index(1), 0, "ports[1] must be 1 or greater").WithOrigin("min"),
+		field.TooMany(containers.Index(0).Child("args"), 3, 2).WithOrigin("max"),
+		field.TooLong(containers.Index(1).Child("image"), "marmotedu/iam", 8).WithOrigin("max"),
+		{
+			Type:     field.ErrorTypeDuplicate,
+			Field:    "containers[1].ports",
+			BadValue: []int{80, 80},
+			Detail:   "ports must contain unique values",
+			Origin:   "unique",
+		},
+		field.NotSupported(containers.Index(1).Child("pullPolicy"), "IfNotPresent", []string{"Always", "Never"}).
+			WithOrigin("oneof"),
+		field.Forbidden(containers.Index(1).Child("args"), "args must have an even number of items").WithOrigin("even"),
+		field.Invalid(field.NewPath("Internal"), "not-an-ip", "Internal must be a valid IP address").WithOrigin("ip"),
+	}
+
+	assert.Equal(t, expected, NewValidator(spec).Validate())
+}
+
+func TestRegexpTag(t *testing.T) {
+	type spec struct {
+		Code string `json:"code" validate:"regexp=^[a-z]{3}$"`
+	}
+
+	assert.Empty(t, NewValidator(&spec{Code: "abc"}).Validate())
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("code"), "ABCD", "code is 'ABCD', but must match the regular expression '^[a-z]{3}$'").
+			WithOrigin("regexp"),
+	}, NewValidator(&spec{Code: "ABCD"}).Validate())
+}
+
+func TestNetworkAndIdentityTags(t *testing.T) {
+	type endpoint struct {
+		Subnet   string `json:"subnet" validate:"cidr,cidr_prefix=16-24"`
+		Ports    string `json:"ports" validate:"port_range"`
+		Address  string `json:"address" validate:"host_port"`
+		Callback string `json:"callback" validate:"url_scheme=http https"`
+		Version  string `json:"version" validate:"semver"`
+		Schedule string `json:"schedule" validate:"cron"`
+		Timeout  string `json:"timeout" validate:"duration"`
+		TraceID  string `json:"traceID" validate:"ulid"`
+		SecretID string `json:"secretID" validate:"instanceid=secret-"`
+	}
+
+	valid := &endpoint{
+		Subnet:   "10.0.0.0/16",
+		Ports:    "8000-9000",
+		Address:  "example.com:443",
+		Callback: "https://example.com/callback",
+		Version:  "v1.2.3",
+		Schedule: "*/10 * * * *",
+		Timeout:  "30s",
+		TraceID:  "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		SecretID: "secret-2v69o5",
+	}
+	assert.Empty(t, NewValidator(valid).Validate())
+
+	invalid := &endpoint{
+		Subnet:   "10.0.0.0/8",
+		Ports:    "9000-8000",
+		Address:  "example.com",
+		Callback: "ftp://example.com/callback",
+		Version:  "1.2",
+		Schedule: "* * *",
+		Timeout:  "soon",
+		TraceID:  "01ARZ3NDEKTSV4RRFFQ69G5FAU",
+		SecretID: "policy-2v69o5",
+	}
+	expected := field.ErrorList{
+		field.Invalid(field.NewPath("subnet"), "10.0.0.0/8",
+			"subnet is '10.0.0.0/8', but must be a CIDR value with a prefix length of 16-24").WithOrigin("cidr_prefix"),
+		field.Invalid(field.NewPath("ports"), "9000-8000",
+			"ports must be a port number or a range of port numbers, but found '9000-8000'").WithOrigin("port_range"),
+		field.Invalid(field.NewPath("address"), "example.com", "address must be a host and a port, but found 'example.com'").WithOrigin("host_port"),
+		field.Invalid(field.NewPath("callback"), "ftp://example.com/callback",
+			"callback is 'ftp://example.com/callback', but must be an absolute URL with one of the schemes 'http https'").WithOrigin("url_scheme"),
+		field.Invalid(field.NewPath("version"), "1.2", "version must be a semantic version, but found '1.2'").WithOrigin("semver"),
+		field.Invalid(field.NewPath("schedule"), "* * *", "schedule must be a cron schedule, but found '* * *'").WithOrigin("cron"),
+		field.Invalid(field.NewPath("timeout"), "soon", "timeout must be a duration, but found 'soon'").WithOrigin("duration"),
+		field.Invalid(field.NewPath("traceID"), "01ARZ3NDEKTSV4RRFFQ69G5FAU",
+			"traceID must be a ULID, but found '01ARZ3NDEKTSV4RRFFQ69G5FAU'").WithOrigin("ulid"),
+		field.Invalid(field.NewPath("secretID"), "policy-2v69o5",
+			"secretID must be an instance ID, but found 'policy-2v69o5'").WithOrigin("instanceid"),
+	}
+	assert.Equal(t, expected, NewValidator(invalid).Validate())
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"reflect"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+// DeprecatedTag marks the fields which are deprecated, e.g.
+// `validate:"deprecated=use replicas instead"`. Setting them is reported as a warning by
+// ValidateWithWarnings.
+const DeprecatedTag = "deprecated"
+
+// Warning is reported by the checks which do not fail the validation, e.g. the deprecated
+// fields, or the rules which will be enforced later.
+type Warning struct {
+	// Field is the path of the field the warning is about, e.g. "spec.replicas".
+	Field string
+
+	// Message is the warning itself.
+	Message string
+
+	// Origin identifies the check which reported the warning, like field.Error.Origin.
+	Origin string
+}
+
+// String returns the warning prefixed by its field, e.g. "spec.replicas: deprecated".
+func (w Warning) String() string {
+	if len(w.Field) == 0 {
+		return w.Message
+	}
+
+	return w.Field + ": " + w.Message
+}
+
+// Warnings is a list of warnings.
+type Warnings []Warning
+
+// Strings returns the strings of the warnings, without the duplicates.
+func (w Warnings) Strings() []string {
+	seen := make(map[string]bool, len(w))
+	result := make([]string, 0, len(w))
+	for _, warning := range w {
+		s := warning.String()
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+
+	return result
+}
+
+// Result is the result of a validation: the errors which fail it, and the warnings which
+// do not.
+type Result struct {
+	Errors   field.ErrorList
+	Warnings Warnings
+}
+
+// Valid returns true if the validation reported no error, whatever the warnings.
+func (r *Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Warn adds a warning about a field.
+func (r *Result) Warn(fldPath *field.Path, message string) {
+	r.Warnings = append(r.Warnings, Warning{Field: fldPath.String(), Message: message})
+}
+
+// Merge adds the errors and the warnings of another result.
+func (r *Result) Merge(other *Result) {
+	if other == nil {
+		return
+	}
+	r.Errors = append(r.Errors, other.Errors...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+}
+
+// warnFailure adds the error reported by a check as a warning, with its detail as message.
+func (r *Result) warnFailure(err *field.Error) {
+	message := err.Detail
+	if len(message) == 0 {
+		message = err.ErrorBody()
+	}
+	r.Warnings = append(r.Warnings, Warning{Field: err.Field, Message: message, Origin: err.Origin})
+}
+
+// warnField adds the warnings about a field of a struct.
+func (r *Result) warnField(sf reflect.StructField, v reflect.Value, fldPath *field.Path) {
+	r.warnDeprecated(sf, v, fldPath)
+	r.warnCertificateExpiry(sf, v, fldPath)
+}
+
+// warnDeprecated adds a warning if a field tagged deprecated is set.
+func (r *Result) warnDeprecated(sf reflect.StructField, v reflect.Value, fldPath *field.Path) {
+	message, ok := fieldTag(sf, DeprecatedTag)
+	if !ok || v.IsZero() {
+		return
+	}
+
+	if len(message) == 0 {
+		message = "deprecated"
+	} else {
+		message = "deprecated: " + message
+	}
+	r.Warnings = append(r.Warnings, Warning{Field: fldPath.String(), Message: message, Origin: DeprecatedTag})
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marmotedu/component-base/pkg/validation/field"
+)
+
+type testDeployment struct {
+	Name     string              `json:"name"     validate:"required"`
+	Replicas int                 `json:"replicas" validate:"min=0"`
+	Scale    int                 `json:"scale"    validate:"omitempty,deprecated=use replicas instead"`
+	Legacy   *bool               `json:"legacy"   validate:"deprecated"`
+	Volumes  []testDeploymentVol `json:"volumes"`
+}
+
+type testDeploymentVol struct {
+	Size int `json:"size"`
+}
+
+func (testDeploymentVol) ValidationRules() []Rule {
+	return []Rule{
+		{Rule: "size <= 10", Message: "will be limited to 10", Field: "size", ID: "max-size", Warning: true},
+		{Rule: "size >= 0", Message: "must not be negative", Field: "size"},
+	}
+}
+
+func TestValidateWithWarnings(t *testing.T) {
+	legacy := false
+	d := &testDeployment{
+		Name:    "nginx",
+		Scale:   3,
+		Legacy:  &legacy,
+		Volumes: []testDeploymentVol{{Size: 20}, {Size: -1}},
+	}
+
+	result := NewValidator(d).ValidateWithWarnings()
+	assert.False(t, result.Valid())
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("volumes").Index(1).Child("size"), -1, "must not be negative").
+			WithOrigin("size >= 0"),
+	}, result.Errors)
+	assert.Equal(t, Warnings{
+		{Field: "scale", Message: "deprecated: use replicas instead", Origin: DeprecatedTag},
+		{Field: "legacy", Message: "deprecated", Origin: DeprecatedTag},
+		{Field: "volumes[0].size", Message: "will be limited to 10", Origin: "max-size"},
+	}, result.Warnings)
+	assert.Equal(t, []string{
+		"scale: deprecated: use replicas instead",
+		"legacy: deprecated",
+		"volumes[0].size: will be limited to 10",
+	}, result.Warnings.Strings())
+
+	// the warnings do not fail the validation
+	d.Volumes = []testDeploymentVol{{Size: 20}}
+	result = NewValidator(d).ValidateWithWarnings()
+	assert.True(t, result.Valid())
+	assert.Len(t, result.Warnings, 3)
+	assert.Nil(t, NewValidator(d).Validate())
+	assert.Empty(t, ValidateRules(d, nil))
+
+	// the unset deprecated fields are not reported
+	d = &testDeployment{Name: "nginx"}
+	assert.Empty(t, NewValidator(d).ValidateWithWarnings().Warnings)
+}
+
+func TestResult(t *testing.T) {
+	result := &Result{}
+	result.Warn(field.NewPath("spec", "replicas"), "should be at most 10")
+	result.Warn(nil, "the object is deprecated")
+	result.Merge(&Result{
+		Errors:   field.ErrorList{field.Required(field.NewPath("name"), "")},
+		Warnings: Warnings{{Message: "the object is deprecated"}},
+	})
+	result.Merge(nil)
+
+	assert.False(t, result.Valid())
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, []string{"spec.replicas: should be at most 10", "the object is deprecated"},
+		result.Warnings.Strings())
+}
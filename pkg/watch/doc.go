@@ -0,0 +1,7 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package watch contains a generic watchable interface, an in-memory broadcaster
+// used to fan out change notifications, and helpers to serve them over http.
+package watch // import "github.com/marmotedu/component-base/pkg/watch"
@@ -0,0 +1,204 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/labels"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// AttrFunc returns the labels and fields of an object which are used
+// to match it against label and field selectors.
+type AttrFunc func(obj interface{}) (labels.Set, fields.Set, error)
+
+// labelsAccessor is implemented by objects which expose their labels.
+type labelsAccessor interface {
+	GetLabels() map[string]string
+}
+
+// DefaultAttrFunc exposes the labels of objects implementing GetLabels() and
+// the "metadata.name" field of objects implementing metav1.Object.
+func DefaultAttrFunc(obj interface{}) (labels.Set, fields.Set, error) {
+	var ls labels.Set
+	if accessor, ok := obj.(labelsAccessor); ok {
+		ls = labels.Set(accessor.GetLabels())
+	}
+
+	fs := fields.Set{}
+	if accessor, ok := obj.(metav1.ObjectMetaAccessor); ok {
+		fs["metadata.name"] = accessor.GetObjectMeta().GetName()
+	} else if accessor, ok := obj.(metav1.Object); ok {
+		fs["metadata.name"] = accessor.GetName()
+	}
+
+	return ls, fs, nil
+}
+
+// SelectionPredicate is used to represent the way to select objects from a watch.
+type SelectionPredicate struct {
+	Label    labels.Selector
+	Field    fields.Selector
+	GetAttrs AttrFunc
+}
+
+// NewSelectionPredicate builds a SelectionPredicate from the label and field selectors
+// of the list options. DefaultAttrFunc is used when attrs is nil.
+func NewSelectionPredicate(opts metav1.ListOptions, attrs AttrFunc) (SelectionPredicate, error) {
+	if attrs == nil {
+		attrs = DefaultAttrFunc
+	}
+
+	return newSelectionPredicate(opts, fields.ParseSelector, attrs)
+}
+
+// NewAdapterSelectionPredicate builds a SelectionPredicate from the label and field
+// selectors of the list options, whose fields are extracted by adapter. The field selector
+// must only select the fields of the adapter.
+func NewAdapterSelectionPredicate(opts metav1.ListOptions, adapter *fields.Adapter) (SelectionPredicate, error) {
+	return newSelectionPredicate(opts, adapter.ParseSelector, AdapterAttrFunc(adapter))
+}
+
+// AdapterAttrFunc returns an AttrFunc exposing the labels of objects implementing
+// GetLabels() and the fields extracted by adapter.
+func AdapterAttrFunc(adapter *fields.Adapter) AttrFunc {
+	return func(obj interface{}) (labels.Set, fields.Set, error) {
+		var ls labels.Set
+		if accessor, ok := obj.(labelsAccessor); ok {
+			ls = labels.Set(accessor.GetLabels())
+		}
+		fs, err := adapter.Fields(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return ls, fs, nil
+	}
+}
+
+func newSelectionPredicate(opts metav1.ListOptions, parseFields func(string) (fields.Selector, error),
+	attrs AttrFunc) (SelectionPredicate, error) {
+	label := labels.Everything()
+	if opts.LabelSelector != "" {
+		selector, err := labels.Parse(opts.LabelSelector)
+		if err != nil {
+			return SelectionPredicate{}, fmt.Errorf("invalid label selector: %w", err)
+		}
+		label = selector
+	}
+
+	field := fields.Everything()
+	if opts.FieldSelector != "" {
+		selector, err := parseFields(opts.FieldSelector)
+		if err != nil {
+			return SelectionPredicate{}, fmt.Errorf("invalid field selector: %w", err)
+		}
+		field = selector
+	}
+
+	return SelectionPredicate{Label: label, Field: field, GetAttrs: attrs}, nil
+}
+
+// Empty returns true if the predicate performs no filtering.
+func (s SelectionPredicate) Empty() bool {
+	return (s.Label == nil || s.Label.Empty()) && (s.Field == nil || s.Field.Empty())
+}
+
+// Matches returns true if the given object's labels and fields (as
+// returned by s.GetAttrs) match s.Label and s.Field. An error is
+// returned if s.GetAttrs fails.
+func (s SelectionPredicate) Matches(obj interface{}) (bool, error) {
+	if s.Empty() {
+		return true, nil
+	}
+
+	getAttrs := s.GetAttrs
+	if getAttrs == nil {
+		getAttrs = DefaultAttrFunc
+	}
+	ls, fs, err := getAttrs(obj)
+	if err != nil {
+		return false, err
+	}
+
+	matched := true
+	if s.Label != nil {
+		matched = s.Label.Matches(ls)
+	}
+	if matched && s.Field != nil {
+		matched = s.Field.Matches(fs)
+	}
+
+	return matched, nil
+}
+
+// FilterFunc should take an event, possibly modify it in some way, and return
+// the modified event. If the event should be ignored, then return keep=false.
+type FilterFunc func(in Event) (out Event, keep bool)
+
+// Filter passes all events through f before allowing them to pass on.
+// Putting a filter on a watch, as an unavoidable side-effect due to the way
+// go channels work, effectively causes the watch's event channel to have its
+// queue length increased by one.
+func Filter(w Interface, f FilterFunc) Interface {
+	fw := &filteredWatch{
+		incoming: w,
+		result:   make(chan Event),
+		f:        f,
+	}
+	go fw.loop()
+	return fw
+}
+
+// FilterPredicate filters the events of w with the selection predicate. Bookmark and
+// error events are always passed on.
+func FilterPredicate(w Interface, p SelectionPredicate) Interface {
+	return Filter(w, p.filterFunc())
+}
+
+func (s SelectionPredicate) filterFunc() FilterFunc {
+	return func(in Event) (Event, bool) {
+		if in.Type == Bookmark || in.Type == Error {
+			return in, true
+		}
+		matched, err := s.Matches(in.Object)
+		if err != nil {
+			return Event{Type: Error, Object: err}, true
+		}
+
+		return in, matched
+	}
+}
+
+type filteredWatch struct {
+	incoming Interface
+	result   chan Event
+	f        FilterFunc
+	stopOnce sync.Once
+}
+
+// ResultChan returns a channel which will receive filtered events.
+func (fw *filteredWatch) ResultChan() <-chan Event {
+	return fw.result
+}
+
+// Stop stops the upstream watch, which will eventually stop this watch.
+func (fw *filteredWatch) Stop() {
+	fw.stopOnce.Do(fw.incoming.Stop)
+}
+
+// loop waits for new values, filters them, and resends them.
+func (fw *filteredWatch) loop() {
+	defer close(fw.result)
+	for event := range fw.incoming.ResultChan() {
+		filtered, keep := fw.f(event)
+		if keep {
+			fw.result <- filtered
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+func TestNewAdapterSelectionPredicate(t *testing.T) {
+	adapter := fields.MustNewAdapter("MyType", &myType{}, "metadata.name", "value")
+
+	p, err := NewAdapterSelectionPredicate(metav1.ListOptions{
+		LabelSelector: "app=nginx",
+		FieldSelector: "metadata.name!=skipped",
+	}, adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		obj  *myType
+		want bool
+	}{
+		{obj: newMyType("kept", map[string]string{"app": "nginx"}), want: true},
+		{obj: newMyType("skipped", map[string]string{"app": "nginx"}), want: false},
+		{obj: newMyType("kept", map[string]string{"app": "redis"}), want: false},
+	}
+	for _, test := range tests {
+		matched, err := p.Matches(test.obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != test.want {
+			t.Errorf("Matches(%s, %v) = %v, want %v", test.obj.Name, test.obj.Labels, matched, test.want)
+		}
+	}
+
+	if _, err := p.Matches("not a myType"); err == nil {
+		t.Errorf("expected an error for an object of another type")
+	}
+
+	_, err = NewAdapterSelectionPredicate(metav1.ListOptions{FieldSelector: "metadata.id=1"}, adapter)
+	if err == nil || !strings.Contains(err.Error(), `field label not supported for MyType: "metadata.id"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
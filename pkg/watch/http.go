@@ -0,0 +1,262 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/json"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+const (
+	// ContentTypeWatchStream is the content type of a chunked watch stream, in which
+	// every line is a JSON encoded metav1.WatchEvent.
+	ContentTypeWatchStream = "application/json;stream=watch"
+	// ContentTypeEventStream is the content type of a server-sent events stream.
+	ContentTypeEventStream = "text/event-stream"
+)
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+// EncodeEvent converts an Event into its serialized form.
+func EncodeEvent(event Event) (metav1.WatchEvent, error) {
+	obj := event.Object
+	if err, ok := obj.(error); ok && event.Type == Error {
+		obj = errorMessage{Message: err.Error()}
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return metav1.WatchEvent{}, err
+	}
+
+	return metav1.WatchEvent{Type: string(event.Type), Object: data}, nil
+}
+
+// DecodeEvent converts a serialized event back into an Event. newObj returns an
+// empty object of the watched kind, in which the event object is decoded.
+func DecodeEvent(in metav1.WatchEvent, newObj func() interface{}) (Event, error) {
+	switch EventType(in.Type) {
+	case Added, Modified, Deleted, Bookmark:
+		obj := newObj()
+		if err := json.Unmarshal(in.Object, obj); err != nil {
+			return Event{}, err
+		}
+
+		return Event{Type: EventType(in.Type), Object: obj}, nil
+	case Error:
+		var msg errorMessage
+		if err := json.Unmarshal(in.Object, &msg); err != nil {
+			return Event{}, err
+		}
+
+		return Event{Type: Error, Object: fmt.Errorf("%s", msg.Message)}, nil
+	default:
+		return Event{}, fmt.Errorf("got invalid watch event type: %v", in.Type)
+	}
+}
+
+// Handler returns a gin handler which serves a watch on the broadcaster. The watch
+// is configured from the metav1.ListOptions in the query string, and is resumed from
+// the Last-Event-ID header sent by reconnecting server-sent events clients.
+func Handler(m *Broadcaster, attrs AttrFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var opts metav1.ListOptions
+		if err := c.ShouldBindQuery(&opts); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, core.ErrResponse{Code: http.StatusBadRequest, Message: err.Error()})
+
+			return
+		}
+
+		predicate, err := NewSelectionPredicate(opts, attrs)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, core.ErrResponse{Code: http.StatusBadRequest, Message: err.Error()})
+
+			return
+		}
+
+		resourceVersion := opts.ResourceVersion
+		if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+			resourceVersion = lastEventID
+		}
+
+		w, err := m.WatchWithOptions(Options{
+			ResourceVersion: resourceVersion,
+			Predicate:       predicate,
+			AllowBookmarks:  opts.AllowWatchBookmarks,
+		})
+		if err != nil {
+			status := http.StatusBadRequest
+			if err == ErrResourceVersionTooOld {
+				status = http.StatusGone
+			}
+			c.AbortWithStatusJSON(status, core.ErrResponse{Code: status, Message: err.Error()})
+
+			return
+		}
+
+		Serve(c, w)
+	}
+}
+
+// Serve streams the events of w to the client until the client goes away or the
+// result channel of w is closed. The watch is always stopped on return.
+//
+// Clients accepting "text/event-stream" get server-sent events, whose id is the
+// resource version of the object. Other clients get a chunked stream of newline
+// delimited metav1.WatchEvent.
+func Serve(c *gin.Context, w Interface) {
+	defer w.Stop()
+
+	sse := strings.Contains(c.GetHeader("Accept"), ContentTypeEventStream)
+	if sse {
+		c.Header("Content-Type", ContentTypeEventStream)
+		c.Header("Cache-Control", "no-cache")
+	} else {
+		c.Header("Content-Type", ContentTypeWatchStream)
+	}
+	// send the headers right away, so that clients know the watch has been established
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	done := c.Request.Context().Done()
+	c.Stream(func(out io.Writer) bool {
+		select {
+		case <-done:
+			return false
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false
+			}
+
+			we, err := EncodeEvent(event)
+			if err != nil {
+				we, _ = EncodeEvent(Event{Type: Error, Object: err})
+			}
+			if sse {
+				err = writeServerSentEvent(out, event, we)
+			} else {
+				err = json.NewEncoder(out).Encode(&we)
+			}
+
+			return err == nil
+		}
+	})
+}
+
+func writeServerSentEvent(out io.Writer, event Event, we metav1.WatchEvent) error {
+	if versioner, ok := event.Object.(ResourceVersioner); ok && event.Type != Error {
+		if _, err := fmt.Fprintf(out, "id: %s\n", versioner.GetResourceVersion()); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(out, "event: %s\ndata: %s\n\n", we.Type, we.Object)
+
+	return err
+}
+
+// maxEventSize is the maximum size of an event read by a StreamWatcher.
+var maxEventSize = 16 * 1024 * 1024
+
+// StreamWatcher turns a chunked watch stream, as written by Serve, into a watch.Interface.
+type StreamWatcher struct {
+	sync.Mutex
+	source  io.ReadCloser
+	newObj  func() interface{}
+	result  chan Event
+	done    chan struct{}
+	stopped bool
+}
+
+// NewStreamWatcher creates a StreamWatcher from the given response body. newObj
+// returns an empty object of the watched kind.
+func NewStreamWatcher(source io.ReadCloser, newObj func() interface{}) *StreamWatcher {
+	sw := &StreamWatcher{
+		source: source,
+		newObj: newObj,
+		// It's easy for a consumer to add buffering via an extra
+		// goroutine/channel, but impossible for them to remove it,
+		// so nonbuffered is better.
+		result: make(chan Event),
+		done:   make(chan struct{}),
+	}
+	go sw.receive()
+
+	return sw
+}
+
+// ResultChan implements Interface.
+func (sw *StreamWatcher) ResultChan() <-chan Event {
+	return sw.result
+}
+
+// Stop implements Interface.
+func (sw *StreamWatcher) Stop() {
+	sw.Lock()
+	defer sw.Unlock()
+	if !sw.stopped {
+		sw.stopped = true
+		close(sw.done)
+		sw.source.Close()
+	}
+}
+
+// receive reads result from the decoder in a loop and sends down the result channel.
+func (sw *StreamWatcher) receive() {
+	defer close(sw.result)
+	defer sw.Stop()
+
+	scanner := bufio.NewScanner(sw.source)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var we metav1.WatchEvent
+		event, err := Event{}, json.Unmarshal(line, &we)
+		if err == nil {
+			event, err = DecodeEvent(we, sw.newObj)
+		}
+		if err != nil {
+			event = Event{Type: Error, Object: err}
+		}
+
+		select {
+		case <-sw.done:
+			return
+		case sw.result <- event:
+		}
+	}
+
+	// the read errors, and the events exceeding maxEventSize, are reported unless the
+	// watcher has been stopped
+	if err := scanner.Err(); err != nil && err != io.EOF && !sw.isStopped() {
+		select {
+		case <-sw.done:
+		case sw.result <- Event{Type: Error, Object: err}:
+		}
+	}
+}
+
+func (sw *StreamWatcher) isStopped() bool {
+	sw.Lock()
+	defer sw.Unlock()
+
+	return sw.stopped
+}
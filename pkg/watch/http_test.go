@@ -0,0 +1,143 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestServer(m *Broadcaster) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/watch", Handler(m, nil))
+
+	return httptest.NewServer(router)
+}
+
+func TestHandlerChunked(t *testing.T) {
+	m := NewBroadcasterWithHistory(10, 10, WaitIfChannelFull)
+	server := newTestServer(m)
+	defer server.Close()
+
+	m.Action(Added, newMyType("foo", map[string]string{"app": "iam"}))
+	m.Action(Added, newMyType("bar", map[string]string{"app": "other"}))
+	m.Action(Deleted, newMyType("baz", map[string]string{"app": "iam"}))
+
+	resp, err := http.Get(server.URL + "/watch?watch=true&resourceVersion=0&labelSelector=app%3Diam")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != ContentTypeWatchStream {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	w := NewStreamWatcher(resp.Body, func() interface{} { return &myType{} })
+	defer w.Stop()
+	m.Action(Modified, newMyType("foo", map[string]string{"app": "iam"}))
+	m.Action(Modified, newMyType("bar", map[string]string{"app": "other"}))
+	m.Shutdown()
+
+	event := receive(t, w)
+	if obj := event.Object.(*myType); event.Type != Modified || obj.Name != "foo" || obj.ResourceVersion != "4" {
+		t.Errorf("unexpected event %#v", event)
+	}
+	if _, open := <-w.ResultChan(); open {
+		t.Errorf("expected stream to be closed after broadcaster shutdown")
+	}
+}
+
+func TestHandlerServerSentEvents(t *testing.T) {
+	m := NewBroadcasterWithHistory(10, 10, WaitIfChannelFull)
+	server := newTestServer(m)
+	defer server.Close()
+
+	m.Action(Added, newMyType("foo", nil))
+	m.Action(Added, newMyType("bar", nil))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/watch", nil)
+	req.Header.Set("Accept", ContentTypeEventStream)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	m.Shutdown()
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 4 || lines[0] != "id: 2" || lines[1] != "event: ADDED" ||
+		!strings.HasPrefix(lines[2], `data: {"metadata":{"name":"bar",`) || lines[3] != "" {
+		t.Errorf("unexpected event stream:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestHandlerResourceVersionTooOld(t *testing.T) {
+	m := NewBroadcasterWithHistory(10, 1, WaitIfChannelFull)
+	server := newTestServer(m)
+	defer server.Close()
+	defer m.Shutdown()
+
+	m.Action(Added, newMyType("foo", nil))
+	m.Action(Added, newMyType("bar", nil))
+	m.Action(Added, newMyType("baz", nil))
+	// make sure the events have been distributed
+	w := m.Watch()
+	m.Action(Added, newMyType("qux", nil))
+	receive(t, w)
+	w.Stop()
+
+	resp, err := http.Get(server.URL + "/watch?resourceVersion=1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGone {
+		t.Errorf("expected status %d, got %d", http.StatusGone, resp.StatusCode)
+	}
+}
+
+func TestStreamWatcherErrors(t *testing.T) {
+	defer func(size int) { maxEventSize = size }(maxEventSize)
+	maxEventSize = 128 * 1024
+
+	stream := `{"type":"ADDED","object":{"metadata":{"name":"foo"}}}` + "\n" +
+		`{"type":"ADDED","object":{"metadata":{"name":"` + strings.Repeat("x", 256*1024) + `"}}}` + "\n"
+	w := NewStreamWatcher(ioutil.NopCloser(strings.NewReader(stream)), func() interface{} { return &myType{} })
+	defer w.Stop()
+
+	if event := receive(t, w); event.Type != Added || event.Object.(*myType).Name != "foo" {
+		t.Errorf("unexpected event %#v", event)
+	}
+	if event := receive(t, w); event.Type != Error || event.Object != bufio.ErrTooLong {
+		t.Errorf("expected the event exceeding the buffer to be reported, got %#v", event)
+	}
+	if _, open := <-w.ResultChan(); open {
+		t.Errorf("expected stream to be closed after the error")
+	}
+
+	// the errors caused by Stop are not reported
+	r, pw := io.Pipe()
+	defer pw.Close()
+	w = NewStreamWatcher(r, func() interface{} { return &myType{} })
+	w.Stop()
+	if event, open := <-w.ResultChan(); open {
+		t.Errorf("unexpected event after stop %#v", event)
+	}
+}
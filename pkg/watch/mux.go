@@ -0,0 +1,390 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// FullChannelBehavior controls how the Broadcaster reacts if a watcher's watch
+// channel is full.
+type FullChannelBehavior int
+
+const (
+	// WaitIfChannelFull blocks the broadcaster until the watcher consumes an event.
+	WaitIfChannelFull FullChannelBehavior = iota
+	// DropIfChannelFull drops the incoming event for the watcher whose channel is full.
+	DropIfChannelFull
+	// DropOldestIfChannelFull discards the oldest buffered event of the watcher to make
+	// room for the incoming one.
+	DropOldestIfChannelFull
+)
+
+// Buffer the incoming queue a little bit even though it should rarely ever accumulate
+// anything, just in case a few events are received in such a short window that
+// Broadcaster can't move them onto the watchers' queues fast enough.
+const incomingQueueLength = 25
+
+var (
+	// ErrResourceVersionTooOld is returned when a watch is resumed from a resource
+	// version which is no longer kept in the broadcaster history.
+	ErrResourceVersionTooOld = errors.New("too old resource version")
+
+	// ErrBroadcasterStopped is returned when watching a broadcaster which has been shut down.
+	ErrBroadcasterStopped = errors.New("broadcaster already stopped")
+)
+
+// Options configures a single watcher of a Broadcaster.
+type Options struct {
+	// ResourceVersion resumes the watch right after the given version. Empty or "0"
+	// starts from the current state.
+	ResourceVersion string
+
+	// Predicate filters the events sent to the watcher.
+	Predicate SelectionPredicate
+
+	// AllowBookmarks makes the watcher receive Bookmark events.
+	AllowBookmarks bool
+}
+
+type historyEntry struct {
+	version uint64
+	event   Event
+}
+
+// Broadcaster distributes event notifications among any number of watchers. Every event
+// is delivered to every watcher whose predicate matches it.
+//
+// Each event gets a monotonically increasing resource version, which is stamped
+// on objects implementing ResourceVersioner. The most recent events are kept in
+// a bounded history so that watchers can resume from a known resource version.
+type Broadcaster struct {
+	lock sync.Mutex
+
+	watchers    map[int64]*broadcasterWatcher
+	nextWatcher int64
+	history     []historyEntry
+	historySize int
+	// lastVersion is the version of the last event which has been distributed.
+	lastVersion uint64
+
+	// actionLock serializes the version assignment and queueing of incoming events.
+	actionLock sync.Mutex
+	version    uint64
+
+	incoming chan historyEntry
+	stopped  chan struct{}
+	shutdown int32
+
+	// How large to make watcher's channel.
+	watchQueueLength int
+	// If one of the watch channels is full, don't wait for it to become empty.
+	// Instead just deliver it to the watchers that do have space in their
+	// channels and move on to the next event.
+	// It's more fair to do this on a per-watcher basis than to do it on the
+	// "incoming" channel, which would allow one slow watcher to prevent all
+	// other watchers from getting new events.
+	fullChannelBehavior FullChannelBehavior
+}
+
+// NewBroadcaster creates a new Broadcaster. queueLength is the maximum number of events to queue
+// per watcher. It is guaranteed that events will be distributed in the order in which they occur,
+// but the order in which a single event is distributed among all of the watchers is unspecified.
+func NewBroadcaster(queueLength int, fullChannelBehavior FullChannelBehavior) *Broadcaster {
+	return NewBroadcasterWithHistory(queueLength, 0, fullChannelBehavior)
+}
+
+// NewBroadcasterWithHistory creates a new Broadcaster which keeps the last historySize events,
+// so that watchers are able to resume from a resource version.
+func NewBroadcasterWithHistory(queueLength, historySize int, fullChannelBehavior FullChannelBehavior) *Broadcaster {
+	m := &Broadcaster{
+		watchers:            map[int64]*broadcasterWatcher{},
+		historySize:         historySize,
+		incoming:            make(chan historyEntry, incomingQueueLength),
+		stopped:             make(chan struct{}),
+		watchQueueLength:    queueLength,
+		fullChannelBehavior: fullChannelBehavior,
+	}
+	go m.loop()
+
+	return m
+}
+
+// Watch adds a new watcher to the list and returns an Interface for it.
+// Note: new watchers will only receive new events. They won't get an entire history
+// of previous events.
+func (m *Broadcaster) Watch() Interface {
+	w, err := m.WatchWithOptions(Options{})
+	if err != nil {
+		return NewEmptyWatch()
+	}
+
+	return w
+}
+
+// WatchWithOptions adds a new watcher configured with opts. Events kept in the history
+// which are newer than opts.ResourceVersion are replayed before any new event, and are
+// filtered like the new events.
+func (m *Broadcaster) WatchWithOptions(opts Options) (Interface, error) {
+	since, err := parseResourceVersion(opts.ResourceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if atomic.LoadInt32(&m.shutdown) == 1 {
+		return nil, ErrBroadcasterStopped
+	}
+
+	id := m.nextWatcher
+	m.nextWatcher++
+	w := &broadcasterWatcher{
+		stopped:   make(chan struct{}),
+		id:        id,
+		m:         m,
+		predicate: opts.Predicate,
+		bookmarks: opts.AllowBookmarks,
+		resumed:   m.lastVersion,
+	}
+
+	// the history is only changed by distribute, under the same lock, so the replayed
+	// events are exactly the ones the watcher would have received
+	var replay []Event
+	if since > 0 && since < m.lastVersion {
+		if len(m.history) == 0 || m.history[0].version > since+1 {
+			return nil, ErrResourceVersionTooOld
+		}
+		for _, entry := range m.history {
+			if entry.version <= since {
+				continue
+			}
+			if event, ok := w.filter(entry.event); ok {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	queueLength := m.watchQueueLength
+	if len(replay) > queueLength {
+		queueLength = len(replay)
+	}
+	w.result = make(chan Event, queueLength)
+	for _, event := range replay {
+		w.result <- event
+	}
+	m.watchers[id] = w
+
+	return w, nil
+}
+
+// Action distributes the given event among all watchers and returns the resource version
+// assigned to it.
+func (m *Broadcaster) Action(action EventType, obj interface{}) string {
+	m.actionLock.Lock()
+	defer m.actionLock.Unlock()
+
+	m.version++
+	version := strconv.FormatUint(m.version, 10)
+	if action != Error {
+		if versioner, ok := obj.(ResourceVersioner); ok {
+			versioner.SetResourceVersion(version)
+		}
+	}
+	m.incoming <- historyEntry{version: m.version, event: Event{action, obj}}
+
+	return version
+}
+
+// Bookmark sends a Bookmark event carrying the current resource version to the
+// watchers which allow bookmarks. newObj should return an empty object of the
+// watched kind implementing ResourceVersioner.
+func (m *Broadcaster) Bookmark(newObj func() interface{}) {
+	m.actionLock.Lock()
+	defer m.actionLock.Unlock()
+
+	obj := newObj()
+	if versioner, ok := obj.(ResourceVersioner); ok {
+		versioner.SetResourceVersion(strconv.FormatUint(m.version, 10))
+	}
+	// bookmarks do not bump the resource version, nor are they kept in history
+	m.incoming <- historyEntry{event: Event{Bookmark, obj}}
+}
+
+// Shutdown disconnects all watchers (but any queued events will still be distributed).
+// You must not call Action or Watch* after calling Shutdown. This call blocks
+// until all events have been distributed through the outbound channels.
+func (m *Broadcaster) Shutdown() {
+	if !atomic.CompareAndSwapInt32(&m.shutdown, 0, 1) {
+		return
+	}
+	close(m.incoming)
+	<-m.stopped
+}
+
+// stopWatching stops the given watcher and removes it from the list.
+func (m *Broadcaster) stopWatching(id int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	w, ok := m.watchers[id]
+	if !ok {
+		// No need to do anything, it's already been removed from the list.
+		return
+	}
+	delete(m.watchers, id)
+	close(w.result)
+}
+
+// closeAll disconnects all watchers (presumably in response to a Shutdown call).
+func (m *Broadcaster) closeAll() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, w := range m.watchers {
+		close(w.result)
+	}
+	// Delete everything from the map, since presence/absence in the map is used
+	// by stopWatching to avoid double-closing the channel.
+	m.watchers = map[int64]*broadcasterWatcher{}
+}
+
+// loop receives from m.incoming and distributes to all watchers.
+func (m *Broadcaster) loop() {
+	// Deliberately not catching crashes here. Yes, bring down the process if there's a
+	// bug in watch.Broadcaster.
+	for entry := range m.incoming {
+		m.distribute(entry)
+	}
+	m.closeAll()
+	close(m.stopped)
+}
+
+// distribute sends event to all watchers. Blocking.
+func (m *Broadcaster) distribute(entry historyEntry) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if entry.version > 0 {
+		m.lastVersion = entry.version
+		if m.historySize > 0 {
+			if len(m.history) >= m.historySize {
+				m.history = append(m.history[:0], m.history[1:]...)
+			}
+			m.history = append(m.history, entry)
+		}
+	}
+
+	for _, w := range m.watchers {
+		if entry.version > 0 && entry.version <= w.resumed {
+			// already replayed
+			continue
+		}
+		w.send(entry.event, m.fullChannelBehavior)
+	}
+}
+
+// broadcasterWatcher handles a single watcher of a broadcaster.
+type broadcasterWatcher struct {
+	result    chan Event
+	stopped   chan struct{}
+	stop      sync.Once
+	id        int64
+	m         *Broadcaster
+	predicate SelectionPredicate
+	bookmarks bool
+	// resumed is the version of the last event distributed before the watcher was added.
+	// The events up to it have been replayed, or are older than the resumed version.
+	resumed uint64
+}
+
+// filter returns the event to send to the watcher, and false if the event is filtered
+// out. The Error events are always sent, and the errors of the predicate are sent as
+// Error events.
+func (mw *broadcasterWatcher) filter(event Event) (Event, bool) {
+	switch event.Type {
+	case Bookmark:
+		return event, mw.bookmarks
+	case Error:
+		return event, true
+	default:
+		matched, err := mw.predicate.Matches(event.Object)
+		if err != nil {
+			return Event{Type: Error, Object: err}, true
+		}
+
+		return event, matched
+	}
+}
+
+// send delivers event to the watcher according to the given behavior.
+func (mw *broadcasterWatcher) send(event Event, behavior FullChannelBehavior) {
+	event, ok := mw.filter(event)
+	if !ok {
+		return
+	}
+
+	if behavior == DropOldestIfChannelFull && cap(mw.result) == 0 {
+		// there is no buffered event to discard on an unbuffered channel
+		behavior = DropIfChannelFull
+	}
+
+	switch behavior {
+	case DropIfChannelFull:
+		select {
+		case mw.result <- event:
+		case <-mw.stopped:
+		default: // Don't block if the event can't be queued.
+		}
+	case DropOldestIfChannelFull:
+		for {
+			select {
+			case mw.result <- event:
+				return
+			case <-mw.stopped:
+				return
+			default:
+			}
+			// make room by discarding the oldest queued event
+			select {
+			case <-mw.result:
+			default:
+			}
+		}
+	default:
+		select {
+		case mw.result <- event:
+		case <-mw.stopped:
+		}
+	}
+}
+
+// ResultChan returns a channel to use for waiting on events.
+func (mw *broadcasterWatcher) ResultChan() <-chan Event {
+	return mw.result
+}
+
+// Stop stops watching and removes mw from its list.
+// It will block until the watcher stop request is actually executed.
+func (mw *broadcasterWatcher) Stop() {
+	mw.stop.Do(func() {
+		close(mw.stopped)
+		mw.m.stopWatching(mw.id)
+	})
+}
+
+func parseResourceVersion(version string) (uint64, error) {
+	if version == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid resource version: " + version)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,268 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	"github.com/marmotedu/component-base/pkg/labels"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/wait"
+)
+
+type myType struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  string            `json:"value"`
+}
+
+func (obj *myType) GetLabels() map[string]string { return obj.Labels }
+
+func newMyType(name string, ls map[string]string) *myType {
+	return &myType{ObjectMeta: metav1.ObjectMeta{Name: name}, Labels: ls, Value: name}
+}
+
+func receive(t *testing.T, w Interface) Event {
+	t.Helper()
+	select {
+	case event, ok := <-w.ResultChan():
+		if !ok {
+			t.Fatalf("result channel closed unexpectedly")
+		}
+
+		return event
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("timed out waiting for an event")
+	}
+
+	return Event{}
+}
+
+func TestBroadcaster(t *testing.T) {
+	table := []Event{
+		{Added, newMyType("foo", nil)},
+		{Modified, newMyType("bar", nil)},
+		{Deleted, newMyType("baz", nil)},
+	}
+
+	m := NewBroadcaster(0, WaitIfChannelFull)
+	w1, w2 := m.Watch(), m.Watch()
+	done := make(chan []Event, 2)
+	for _, w := range []Interface{w1, w2} {
+		go func(w Interface) {
+			var got []Event
+			for event := range w.ResultChan() {
+				got = append(got, event)
+			}
+			done <- got
+		}(w)
+	}
+
+	for i, item := range table {
+		if version := m.Action(item.Type, item.Object); version != strconv.Itoa(i+1) {
+			t.Errorf("expected resource version %d, got %s", i+1, version)
+		}
+	}
+	m.Shutdown()
+
+	for i := 0; i < 2; i++ {
+		if got := <-done; !reflect.DeepEqual(got, table) {
+			t.Errorf("watcher got %#v, expected %#v", got, table)
+		}
+	}
+	if rv := table[2].Object.(*myType).ResourceVersion; rv != "3" {
+		t.Errorf("expected object to be stamped with resource version 3, got %q", rv)
+	}
+}
+
+func TestBroadcasterWatcherStop(t *testing.T) {
+	m := NewBroadcaster(0, WaitIfChannelFull)
+	w := m.Watch()
+	w2 := m.Watch()
+	w.Stop()
+	m.Action(Added, newMyType("foo", nil))
+	if event := receive(t, w2); event.Type != Added {
+		t.Errorf("unexpected event %#v", event)
+	}
+	if _, open := <-w.ResultChan(); open {
+		t.Errorf("expected result channel of stopped watcher to be closed")
+	}
+	m.Shutdown()
+}
+
+func TestBroadcasterDropIfChannelFull(t *testing.T) {
+	m := NewBroadcaster(1, DropIfChannelFull)
+	w := m.Watch()
+	m.Action(Added, newMyType("foo", nil))
+	m.Action(Added, newMyType("bar", nil))
+	m.Shutdown()
+
+	var names []string
+	for event := range w.ResultChan() {
+		names = append(names, event.Object.(*myType).Name)
+	}
+	if !reflect.DeepEqual(names, []string{"foo"}) {
+		t.Errorf("expected only the first event to be delivered, got %v", names)
+	}
+}
+
+func TestBroadcasterDropOldestIfChannelFull(t *testing.T) {
+	m := NewBroadcaster(1, DropOldestIfChannelFull)
+	w := m.Watch()
+	m.Action(Added, newMyType("foo", nil))
+	m.Action(Added, newMyType("bar", nil))
+	m.Shutdown()
+
+	var names []string
+	for event := range w.ResultChan() {
+		names = append(names, event.Object.(*myType).Name)
+	}
+	if !reflect.DeepEqual(names, []string{"bar"}) {
+		t.Errorf("expected only the last event to be delivered, got %v", names)
+	}
+}
+
+func TestBroadcasterPredicate(t *testing.T) {
+	m := NewBroadcaster(10, WaitIfChannelFull)
+	w, err := m.WatchWithOptions(Options{
+		Predicate: SelectionPredicate{
+			Label: labels.SelectorFromSet(labels.Set{"app": "iam"}),
+			Field: fields.OneTermNotEqualSelector("metadata.name", "skipped"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.Action(Added, newMyType("other", map[string]string{"app": "other"}))
+	m.Action(Added, newMyType("skipped", map[string]string{"app": "iam"}))
+	m.Action(Added, newMyType("kept", map[string]string{"app": "iam"}))
+	m.Shutdown()
+
+	var names []string
+	for event := range w.ResultChan() {
+		names = append(names, event.Object.(*myType).Name)
+	}
+	if !reflect.DeepEqual(names, []string{"kept"}) {
+		t.Errorf("expected only matching objects, got %v", names)
+	}
+}
+
+func TestBroadcasterResume(t *testing.T) {
+	m := NewBroadcasterWithHistory(10, 2, WaitIfChannelFull)
+	defer m.Shutdown()
+
+	for _, name := range []string{"a", "b", "c"} {
+		m.Action(Added, newMyType(name, nil))
+	}
+	// wait for the events to be distributed
+	synced := m.Watch()
+	m.Action(Added, newMyType("d", nil))
+	receive(t, synced)
+	synced.Stop()
+
+	if _, err := m.WatchWithOptions(Options{ResourceVersion: "1"}); err != ErrResourceVersionTooOld {
+		t.Errorf("expected ErrResourceVersionTooOld, got %v", err)
+	}
+	if _, err := m.WatchWithOptions(Options{ResourceVersion: "abc"}); err == nil {
+		t.Errorf("expected an error for an invalid resource version")
+	}
+
+	w, err := m.WatchWithOptions(Options{ResourceVersion: "2", AllowBookmarks: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Stop()
+	for _, expected := range []string{"c", "d"} {
+		if name := receive(t, w).Object.(*myType).Name; name != expected {
+			t.Errorf("expected replayed object %q, got %q", expected, name)
+		}
+	}
+
+	m.Bookmark(func() interface{} { return &myType{} })
+	event := receive(t, w)
+	if event.Type != Bookmark || event.Object.(*myType).ResourceVersion != "4" {
+		t.Errorf("expected bookmark at resource version 4, got %#v", event)
+	}
+}
+
+func TestBroadcasterResumeConcurrently(t *testing.T) {
+	const count = 200
+	m := NewBroadcasterWithHistory(count, count, WaitIfChannelFull)
+	defer m.Shutdown()
+
+	m.Action(Added, newMyType("first", nil))
+	// wait for the first event to be distributed
+	synced := m.Watch()
+	go func() {
+		for i := 0; i < count-1; i++ {
+			m.Action(Modified, newMyType("next", nil))
+		}
+	}()
+	receive(t, synced)
+	synced.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, err := m.WatchWithOptions(Options{ResourceVersion: "1"})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+
+				return
+			}
+			defer w.Stop()
+
+			last := uint64(1)
+			for last < count {
+				event := receive(t, w)
+				version, _ := strconv.ParseUint(event.Object.(*myType).ResourceVersion, 10, 64)
+				if version != last+1 {
+					t.Errorf("expected resource version %d, got %d", last+1, version)
+
+					return
+				}
+				last = version
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBroadcasterResumeErrors(t *testing.T) {
+	m := NewBroadcasterWithHistory(10, 10, WaitIfChannelFull)
+	defer m.Shutdown()
+
+	m.Action(Added, newMyType("a", nil))
+	m.Action(Error, errors.New("failed"))
+	m.Action(Added, newMyType("b", map[string]string{"app": "iam"}))
+	synced := m.Watch()
+	m.Action(Added, newMyType("c", nil))
+	receive(t, synced)
+	synced.Stop()
+
+	w, err := m.WatchWithOptions(Options{
+		ResourceVersion: "1",
+		Predicate:       SelectionPredicate{Label: labels.SelectorFromSet(labels.Set{"app": "iam"})},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Stop()
+	if event := receive(t, w); event.Type != Error {
+		t.Errorf("expected the error to be replayed like it is distributed, got %#v", event)
+	}
+	if event := receive(t, w); event.Type != Added || event.Object.(*myType).Name != "b" {
+		t.Errorf("expected the matching object to be replayed, got %#v", event)
+	}
+}